@@ -310,6 +310,48 @@ type ZuulExecutorSpec struct {
 	// +kubebuilder:default:=7200
 	// +kubebuilder:validation:Minimum:=1
 	TerminationGracePeriodSeconds int64 `json:"TerminationGracePeriodSeconds,omitempty"`
+	// How many zuul-executor replicas to run. When unset, the replica count of the statefulset is left untouched
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Remote executor pools, deployed with the control plane by the `deploy` command
+	// +optional
+	Pools []ZuulExecutorPoolSpec `json:"pools,omitempty"`
+	// Maintenance of the git repositories cached by the zuul-executor
+	// +optional
+	GitCache *GitCacheSpec `json:"gitCache,omitempty"`
+}
+
+// GitCacheSpec defines the maintenance of the git repositories cached on the zuul-merger or zuul-executor volumes
+type GitCacheSpec struct {
+	// Clone the projects of the tenants when a new volume is provisioned, so that the first builds do not have to
+	// +optional
+	WarmUp bool `json:"warmUp,omitempty"`
+	// The schedule, in Cron format, of the `git gc` and prune maintenance of the cached repositories. Disabled when empty
+	// +optional
+	GCSchedule string `json:"gcSchedule,omitempty"`
+}
+
+// ZuulExecutorPoolSpec defines a group of standalone executors running on a remote cluster or namespace
+type ZuulExecutorPoolSpec struct {
+	// The name of the pool, used to report its status
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9-]+$`
+	Name string `json:"name"`
+	// The context, from the control plane kubeconfig, of the cluster where the pool is deployed
+	KubeContext string `json:"kubeContext"`
+	// The namespace where the pool is deployed. Defaults to the namespace of the context
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// How many zuul-executor replicas to run in the pool
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Memory/CPU Limit. Defaults to the control plane executor limits
+	// +optional
+	Limits *LimitsSpec `json:"limits,omitempty"`
+	// The settings to connect to the control plane, and the executor zone of the pool
+	StandaloneZuulExecutorSpec `json:",inline"`
 }
 
 type ZuulWebSpec struct {
@@ -372,6 +414,9 @@ type ZuulMergerSpec struct {
 	// the [git_timeout](https://zuul-ci.org/docs/zuul/latest/configuration.html#attr-merger.git_timeout) parameter
 	// +kubebuilder:validation:Minimum:=1
 	GitTimeout int32 `json:"gitTimeout,omitempty"`
+	// Maintenance of the git repositories cached by the zuul-merger
+	// +optional
+	GitCache *GitCacheSpec `json:"gitCache,omitempty"`
 	// Storage-related settings
 	Storage StorageSpec `json:"storage,omitempty"`
 	// Specify the Log Level of the nodepool launcher service.
@@ -418,6 +463,38 @@ type ZuulSpec struct {
 	Web ZuulWebSpec `json:"web,omitempty"`
 	// Configuration of the merger microservice
 	Merger ZuulMergerSpec `json:"merger,omitempty"`
+	// The retention policy of the builds and buildsets stored in the database
+	// +optional
+	BuildRetention *BuildRetentionSpec `json:"buildRetention,omitempty"`
+}
+
+// BuildRetentionPipelineSpec overrides the build retention of a pipeline
+type BuildRetentionPipelineSpec struct {
+	// The name of the pipeline, in any tenant
+	Name string `json:"name"`
+	// Buildsets of this pipeline older than this setting in days are pruned
+	// +kubebuilder:validation:Minimum:=1
+	Days int `json:"days"`
+}
+
+// BuildRetentionSpec defines the pruning of the Zuul database
+type BuildRetentionSpec struct {
+	// Buildsets older than this setting in days are pruned from the database
+	// +kubebuilder:validation:Minimum:=1
+	Days int `json:"days"`
+	// Retention overrides for specific pipelines
+	// +optional
+	Pipelines []BuildRetentionPipelineSpec `json:"pipelines,omitempty"`
+	// The schedule of the pruning cronjob. Defaults to every day at 2:00
+	// +kubebuilder:default:="0 2 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// Export the pruned buildsets and builds to a compressed archive before pruning them
+	// +optional
+	Archive bool `json:"archive,omitempty"`
+	// Storage-related settings of the archive volume
+	// +optional
+	ArchiveStorage StorageSpec `json:"archiveStorage,omitempty"`
 }
 
 func GetGitHubConnectionsSecretName(spec *ZuulSpec) []string {
@@ -472,6 +549,24 @@ type NodepoolLauncherSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default={"memory": "2Gi", "cpu": "500m"}
 	Limits *LimitsSpec `json:"limits"`
+	// Launcher groups, each deployed as a nodepool-launcher-<name> deployment running its own providers only.
+	// The nodepool-launcher deployment runs the providers that are not part of a group.
+	// +optional
+	Groups []NodepoolLauncherGroupSpec `json:"groups,omitempty"`
+}
+
+type NodepoolLauncherGroupSpec struct {
+	// The name of the group, used as suffix of the deployment name
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength:=40
+	Name string `json:"name"`
+	// The names of the nodepool providers run by the group. A provider can only be part of one group.
+	// The openstacksdk metrics of the clouds named after these providers are mapped by the group statsd exporter.
+	// +kubebuilder:validation:MinItems:=1
+	Providers []string `json:"providers"`
+	// Memory/CPU Limit, the launcher limits are used when unset
+	// +optional
+	Limits *LimitsSpec `json:"limits,omitempty"`
 }
 
 type NodepoolBuilderSpec struct {
@@ -487,6 +582,11 @@ type NodepoolBuilderSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default={"memory": "2Gi", "cpu": "500m"}
 	Limits *LimitsSpec `json:"limits"`
+	// +kubebuilder:default:=true
+	// +optional
+	// If set to false, the nodepool-builder won't be deployed. Use this setting when
+	// the providers only rely on container or cloud images that are not built by nodepool.
+	Enabled *bool `json:"enabled,omitempty"`
 }
 
 type NodepoolSpec struct {
@@ -506,6 +606,31 @@ type ZookeeperSpec struct {
 	Limits *LimitsSpec `json:"limits"`
 }
 
+// CodesearchRepositoriesSpec selects the projects of the Zuul tenants to index
+type CodesearchRepositoriesSpec struct {
+	// Restrict the selection to the projects of this tenant. All the tenants when empty
+	// +optional
+	Tenant string `json:"tenant,omitempty"`
+	// Restrict the selection to the projects of this Zuul connection. All the connections when empty
+	// +optional
+	Connection string `json:"connection,omitempty"`
+	// Regular expressions matching the project names to index. When set, the other projects of the tenant or connection are not indexed
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Regular expressions matching the project names to not index
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// CodesearchBranchesSpec defines the branches to index for a set of projects
+type CodesearchBranchesSpec struct {
+	// A regular expression matching the project names
+	Project string `json:"project"`
+	// The branches to index instead of the default branch
+	// +kubebuilder:validation:MinItems:=1
+	Branches []string `json:"branches"`
+}
+
 type CodesearchSpec struct {
 	Storage StorageSpec `json:"storage,omitempty"`
 	// Memory/CPU Limit
@@ -516,6 +641,63 @@ type CodesearchSpec struct {
 	// +optional
 	// If set to false, the service won't be deployed
 	Enabled *bool `json:"enabled,omitempty"`
+	// The project selections. Every project of the Zuul tenants is indexed when empty
+	// +optional
+	Repositories []CodesearchRepositoriesSpec `json:"repositories,omitempty"`
+	// The branches to index per project. The default branch is indexed for the other projects
+	// +optional
+	Branches []CodesearchBranchesSpec `json:"branches,omitempty"`
+	// The interval, in minutes, between two updates of a repository index
+	// +kubebuilder:default:=720
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	ReindexInterval int32 `json:"reindexInterval,omitempty"`
+	// Clone the repositories with the credentials of their Zuul connection: the GitHub app or api token,
+	// the GitLab api token, or the Gerrit HTTP password. This enables the indexing of private repositories
+	// +optional
+	ConnectionCredentials bool `json:"connectionCredentials,omitempty"`
+}
+
+// LogjuicerSpec defines the LogJuicer service
+type LogjuicerSpec struct {
+	// Storage space to allocate to the LogJuicer data, expressed as a [Quantity](https://kubernetes.io/docs/reference/kubernetes-api/common-definitions/quantity/)
+	// +optional
+	Size resource.Quantity `json:"size,omitempty"`
+	// Default storage class to use with the Persistent Volume Claim of the LogJuicer data
+	// +optional
+	ClassName string `json:"className,omitempty"`
+	// Memory/CPU Limit
+	// +optional
+	Limits *LimitsSpec `json:"limits,omitempty"`
+	// +kubebuilder:default:=true
+	// +optional
+	// If set to false, the service won't be deployed
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// WeederSpec defines the zuul-weeder service
+type WeederSpec struct {
+	// The volume of the weeder cache. An ephemeral volume is used when unset
+	// +optional
+	Storage *StorageSpec `json:"storage,omitempty"`
+	// Memory/CPU Limit
+	// +optional
+	Limits *LimitsSpec `json:"limits,omitempty"`
+	// +kubebuilder:default:=true
+	// +optional
+	// If set to false, the service won't be deployed
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// ZuulCapacitySpec defines the zuul-capacity service, which runs next to the nodepool-launcher when the providers secret exists
+type ZuulCapacitySpec struct {
+	// Memory/CPU Limit
+	// +optional
+	Limits *LimitsSpec `json:"limits,omitempty"`
+	// +kubebuilder:default:=true
+	// +optional
+	// If set to false, the service won't be deployed
+	Enabled *bool `json:"enabled,omitempty"`
 }
 
 type LimitsSpec struct {
@@ -525,6 +707,23 @@ type LimitsSpec struct {
 	CPU resource.Quantity `json:"cpu"`
 }
 
+// MariaDBBinlogSpec defines the retention of the MariaDB binary logs
+type MariaDBBinlogSpec struct {
+	// How long the binary logs are kept on the log storage, in days. The binary logs must be archived
+	// by a backup before they expire.
+	// +kubebuilder:default:=7
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	RetentionDays int32 `json:"retentionDays,omitempty"`
+	// The schedule of the cronjob archiving the binary logs on the `mariadb-binlog-archive` volume, for instance
+	// "*/15 * * * *". When empty, the binary logs are only archived by the backups.
+	// +optional
+	ArchiveSchedule string `json:"archiveSchedule,omitempty"`
+	// Storage-related settings of the archive volume
+	// +optional
+	ArchiveStorage StorageSpec `json:"archiveStorage,omitempty"`
+}
+
 type MariaDBSpec struct {
 	// Storage parameters related to mariaDB's data
 	DBStorage StorageSpec `json:"dbStorage,omitempty"`
@@ -534,6 +733,29 @@ type MariaDBSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default={"memory": "2Gi", "cpu": "500m"}
 	Limits *LimitsSpec `json:"limits"`
+	// Enable the binary logs on the log storage, to allow a point-in-time recovery
+	// +optional
+	Binlog *MariaDBBinlogSpec `json:"binlog,omitempty"`
+	// The number of asynchronous read replicas deployed in addition to the primary. The replicas use GTID replication,
+	// and zuul-web reads from them through the `mariadb-ro` service.
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// ExternalDatabaseSpec defines a database managed outside of the deployment, used by Zuul instead of the MariaDB service
+type ExternalDatabaseSpec struct {
+	// The database driver
+	// +kubebuilder:validation:Enum=mysql;postgresql
+	// +kubebuilder:default:=mysql
+	// +optional
+	Driver string `json:"driver,omitempty"`
+	// The name of the Secret holding the connection URL in its `dsn` key, such as `user:password@db.example.com:3306/zuul`
+	SecretName string `json:"secretName"`
+	// The Secret key of the CA certificate used to verify the database server certificate.
+	// When set, the connection is encrypted. The default key is `ca.crt`.
+	// +optional
+	TLSCA *Secret `json:"tlsCA,omitempty"`
 }
 
 type GitServerSpec struct {
@@ -568,15 +790,116 @@ type LogServerSpec struct {
 	// Optional annotations to add to the logserver pod template (e.g. io.kubernetes.cri-o.TrySkipVolumeSELinuxLabel for CRI-O)
 	// +optional
 	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+	// Retention overrides for some paths of the logs. The first matching path applies
+	// +optional
+	PathRetentions []LogServerPathRetentionSpec `json:"pathRetentions,omitempty"`
+	// A volume usage quota. When set, the oldest logs are deleted when the volume usage exceeds the high watermark
+	// +optional
+	Quota *LogServerQuotaSpec `json:"quota,omitempty"`
+	// The logs storage backend: `pvc` stores the logs on the logserver volume, `s3` in an S3-compatible bucket
+	// +kubebuilder:validation:Enum=pvc;s3
+	// +kubebuilder:default:=pvc
+	// +optional
+	Backend string `json:"backend,omitempty"`
+	// The bucket settings of the s3 backend
+	// +optional
+	S3 *LogServerS3Spec `json:"s3,omitempty"`
+}
+
+// LogServerS3Spec defines the S3-compatible bucket storing the logs
+type LogServerS3Spec struct {
+	// The URL of the S3 API, for instance `https://s3.us-east-1.amazonaws.com` or `http://minio:9000`. The bucket is addressed with the path style
+	Endpoint string `json:"endpoint"`
+	// The bucket name. The bucket is created when missing
+	Bucket string `json:"bucket"`
+	// The bucket region
+	// +optional
+	Region string `json:"region,omitempty"`
+	// The name of the Secret holding the `access_key` and `secret_key` keys of the bucket credentials
+	SecretName string `json:"secretName"`
+}
+
+// LogServerPathRetentionSpec defines the retention of the logs stored under a path
+type LogServerPathRetentionSpec struct {
+	// A glob pattern matching the path of the log files, relative to the logs root directory, for instance `periodic/*`
+	Path string `json:"path"`
+	// Logs retention time in days for this path
+	// +kubebuilder:validation:Minimum:=1
+	RetentionDays int `json:"retentionDays"`
+}
+
+// LogServerQuotaSpec defines the watermarks of the logserver volume usage
+type LogServerQuotaSpec struct {
+	// The volume usage, in percent, above which the oldest logs are deleted
+	// +kubebuilder:default:=90
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	// +optional
+	HighWatermark int `json:"highWatermark,omitempty"`
+	// The volume usage, in percent, to reach when the oldest logs are deleted. Must be lower than highWatermark
+	// +kubebuilder:default:=80
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Maximum:=99
+	// +optional
+	LowWatermark int `json:"lowWatermark,omitempty"`
+	// The delay, in seconds, between two volume usage checks
+	// +kubebuilder:default:=300
+	// +kubebuilder:validation:Minimum:=10
+	// +optional
+	CheckInterval int `json:"checkInterval,omitempty"`
 }
 
 // GatewaySpec defines extra gateway config if needed
 type GatewaySpec struct {
 	// Name of a configmap containing extra httpd configuration file(s). the default gateway config is prefixed by "99-", meaning it is possible to control whether the extra config files are loaded first or not.
-	ExtraConfigurationConfigMap string `json:"extraConfigurationConfigMap"`
+	// +kubebuilder:validation:Optional
+	ExtraConfigurationConfigMap string `json:"extraConfigurationConfigMap,omitempty"`
 	// Optional configmap containing file(s) to mount in /var/www/html/ - it is implied these static files are to be used with any extra config added above.
 	// +kubebuilder:validation:Optional
 	ExtraStaticFilesConfigMap *string `json:"extraStaticFilesConfigMap,omitempty"`
+	// The access rules restricting the routes served by the gateway
+	// +optional
+	AccessRules []GatewayAccessRuleSpec `json:"accessRules,omitempty"`
+	// The IP addresses or CIDR ranges of the proxies in front of the gateway, like the ingress controller or the router.
+	// The client IP address checked by the access rules is read from their `X-Forwarded-For` header
+	// +optional
+	TrustedProxies []string `json:"trustedProxies,omitempty"`
+	// The landing page listing the enabled services. When set, it is served at the root path instead of Zuul
+	// +optional
+	LandingPage *GatewayLandingPageSpec `json:"landingPage,omitempty"`
+	// The maintenance mode. When set, the gateway serves a maintenance page, except for the Zuul API, while a core service is not ready or during an upgrade
+	// +optional
+	Maintenance *GatewayMaintenanceSpec `json:"maintenance,omitempty"`
+}
+
+// GatewayLandingPageSpec defines the landing page rendered by the operator
+type GatewayLandingPageSpec struct {
+	// The title of the landing page. Defaults to the FQDN
+	// +optional
+	Title string `json:"title,omitempty"`
+	// A message displayed at the top of the landing page
+	// +optional
+	Banner string `json:"banner,omitempty"`
+}
+
+// GatewayMaintenanceSpec defines the maintenance page of the gateway
+type GatewayMaintenanceSpec struct {
+	// The message of the maintenance page
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Serve the maintenance page until this setting is removed, for instance during a manual intervention
+	// +optional
+	Always bool `json:"always,omitempty"`
+}
+
+// GatewayAccessRuleSpec restricts the access to the routes under a path prefix to an IP allowlist
+type GatewayAccessRuleSpec struct {
+	// The path prefix, for instance `/weeder`
+	// +kubebuilder:validation:Pattern:=`^/`
+	Path string `json:"path"`
+	// The IP addresses or CIDR ranges allowed to access the path
+	// +kubebuilder:validation:MinItems:=1
+	AllowedIPs []string `json:"allowedIPs"`
 }
 
 type HostAlias struct {
@@ -620,11 +943,22 @@ type SoftwareFactorySpec struct {
 
 	// Logjuicer service spec
 	// +optional
-	Logjuicer StorageSpec `json:"logjuicer,omitempty"`
+	Logjuicer LogjuicerSpec `json:"logjuicer,omitempty"`
+
+	// Weeder service spec
+	// +optional
+	Weeder WeederSpec `json:"weeder,omitempty"`
+
+	// Zuul-capacity service spec
+	// +optional
+	ZuulCapacity ZuulCapacitySpec `json:"zuulCapacity,omitempty"`
 
 	// MariaDB service spec
 	MariaDB MariaDBSpec `json:"mariadb,omitempty"`
 
+	// Use an external database instead of deploying the MariaDB service
+	ExternalDatabase *ExternalDatabaseSpec `json:"externalDatabase,omitempty"`
+
 	// Git server spec
 	GitServer GitServerSpec `json:"gitserver,omitempty"`
 
@@ -649,6 +983,24 @@ type BaseStatus struct {
 	ReconciledBy string `json:"reconciledBy,omitempty"`
 	// Information about ongoing or completed reconciliation processes between the Log server spec and the observed state of the cluster
 	Conditions []metav1.Condition `json:"conditions,omitempty" optional:"true"`
+	// The status of the remote executor pools
+	ExecutorPools []ExecutorPoolStatus `json:"executorPools,omitempty" optional:"true"`
+}
+
+// ExecutorPoolStatus defines the observed state of a remote executor pool
+type ExecutorPoolStatus struct {
+	// The pool name
+	Name string `json:"name"`
+	// The executor zone of the pool
+	Zone string `json:"zone,omitempty"`
+	// Whether the pool executors are ready
+	Ready bool `json:"ready"`
+	// The number of ready zuul-executor replicas
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// The number of desired zuul-executor replicas
+	Replicas int32 `json:"replicas"`
+	// A human readable message, set when the pool is not ready
+	Message string `json:"message,omitempty"`
 }
 
 // SoftwareFactoryStatus defines the observed state of SoftwareFactory
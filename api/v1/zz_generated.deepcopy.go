@@ -37,6 +37,11 @@ func (in *BaseStatus) DeepCopyInto(out *BaseStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExecutorPools != nil {
+		in, out := &in.ExecutorPools, &out.ExecutorPools
+		*out = make([]ExecutorPoolStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BaseStatus.
@@ -49,6 +54,87 @@ func (in *BaseStatus) DeepCopy() *BaseStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRetentionPipelineSpec) DeepCopyInto(out *BuildRetentionPipelineSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRetentionPipelineSpec.
+func (in *BuildRetentionPipelineSpec) DeepCopy() *BuildRetentionPipelineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRetentionPipelineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRetentionSpec) DeepCopyInto(out *BuildRetentionSpec) {
+	*out = *in
+	if in.Pipelines != nil {
+		in, out := &in.Pipelines, &out.Pipelines
+		*out = make([]BuildRetentionPipelineSpec, len(*in))
+		copy(*out, *in)
+	}
+	in.ArchiveStorage.DeepCopyInto(&out.ArchiveStorage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRetentionSpec.
+func (in *BuildRetentionSpec) DeepCopy() *BuildRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CodesearchBranchesSpec) DeepCopyInto(out *CodesearchBranchesSpec) {
+	*out = *in
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodesearchBranchesSpec.
+func (in *CodesearchBranchesSpec) DeepCopy() *CodesearchBranchesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CodesearchBranchesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CodesearchRepositoriesSpec) DeepCopyInto(out *CodesearchRepositoriesSpec) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodesearchRepositoriesSpec.
+func (in *CodesearchRepositoriesSpec) DeepCopy() *CodesearchRepositoriesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CodesearchRepositoriesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CodesearchSpec) DeepCopyInto(out *CodesearchSpec) {
 	*out = *in
@@ -63,6 +149,20 @@ func (in *CodesearchSpec) DeepCopyInto(out *CodesearchSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]CodesearchRepositoriesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]CodesearchBranchesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodesearchSpec.
@@ -120,6 +220,41 @@ func (in *ElasticSearchConnection) DeepCopy() *ElasticSearchConnection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorPoolStatus) DeepCopyInto(out *ExecutorPoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorPoolStatus.
+func (in *ExecutorPoolStatus) DeepCopy() *ExecutorPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDatabaseSpec) DeepCopyInto(out *ExternalDatabaseSpec) {
+	*out = *in
+	if in.TLSCA != nil {
+		in, out := &in.TLSCA, &out.TLSCA
+		*out = new(Secret)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDatabaseSpec.
+func (in *ExternalDatabaseSpec) DeepCopy() *ExternalDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FluentBitForwarderSpec) DeepCopyInto(out *FluentBitForwarderSpec) {
 	*out = *in
@@ -140,6 +275,56 @@ func (in *FluentBitForwarderSpec) DeepCopy() *FluentBitForwarderSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAccessRuleSpec) DeepCopyInto(out *GatewayAccessRuleSpec) {
+	*out = *in
+	if in.AllowedIPs != nil {
+		in, out := &in.AllowedIPs, &out.AllowedIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAccessRuleSpec.
+func (in *GatewayAccessRuleSpec) DeepCopy() *GatewayAccessRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAccessRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayLandingPageSpec) DeepCopyInto(out *GatewayLandingPageSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayLandingPageSpec.
+func (in *GatewayLandingPageSpec) DeepCopy() *GatewayLandingPageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayLandingPageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayMaintenanceSpec) DeepCopyInto(out *GatewayMaintenanceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayMaintenanceSpec.
+func (in *GatewayMaintenanceSpec) DeepCopy() *GatewayMaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayMaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
 	*out = *in
@@ -148,6 +333,28 @@ func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AccessRules != nil {
+		in, out := &in.AccessRules, &out.AccessRules
+		*out = make([]GatewayAccessRuleSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TrustedProxies != nil {
+		in, out := &in.TrustedProxies, &out.TrustedProxies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LandingPage != nil {
+		in, out := &in.LandingPage, &out.LandingPage
+		*out = new(GatewayLandingPageSpec)
+		**out = **in
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(GatewayMaintenanceSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySpec.
@@ -185,6 +392,21 @@ func (in *GerritConnection) DeepCopy() *GerritConnection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitCacheSpec) DeepCopyInto(out *GitCacheSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitCacheSpec.
+func (in *GitCacheSpec) DeepCopy() *GitCacheSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitCacheSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitConnection) DeepCopyInto(out *GitConnection) {
 	*out = *in
@@ -298,6 +520,51 @@ func (in *LimitsSpec) DeepCopy() *LimitsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogServerPathRetentionSpec) DeepCopyInto(out *LogServerPathRetentionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogServerPathRetentionSpec.
+func (in *LogServerPathRetentionSpec) DeepCopy() *LogServerPathRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogServerPathRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogServerQuotaSpec) DeepCopyInto(out *LogServerQuotaSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogServerQuotaSpec.
+func (in *LogServerQuotaSpec) DeepCopy() *LogServerQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogServerQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogServerS3Spec) DeepCopyInto(out *LogServerS3Spec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogServerS3Spec.
+func (in *LogServerS3Spec) DeepCopy() *LogServerS3Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogServerS3Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogServerSpec) DeepCopyInto(out *LogServerSpec) {
 	*out = *in
@@ -309,6 +576,21 @@ func (in *LogServerSpec) DeepCopyInto(out *LogServerSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.PathRetentions != nil {
+		in, out := &in.PathRetentions, &out.PathRetentions
+		*out = make([]LogServerPathRetentionSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(LogServerQuotaSpec)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(LogServerS3Spec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogServerSpec.
@@ -321,6 +603,48 @@ func (in *LogServerSpec) DeepCopy() *LogServerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogjuicerSpec) DeepCopyInto(out *LogjuicerSpec) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(LimitsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogjuicerSpec.
+func (in *LogjuicerSpec) DeepCopy() *LogjuicerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogjuicerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MariaDBBinlogSpec) DeepCopyInto(out *MariaDBBinlogSpec) {
+	*out = *in
+	in.ArchiveStorage.DeepCopyInto(&out.ArchiveStorage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MariaDBBinlogSpec.
+func (in *MariaDBBinlogSpec) DeepCopy() *MariaDBBinlogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MariaDBBinlogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MariaDBSpec) DeepCopyInto(out *MariaDBSpec) {
 	*out = *in
@@ -331,6 +655,11 @@ func (in *MariaDBSpec) DeepCopyInto(out *MariaDBSpec) {
 		*out = new(LimitsSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Binlog != nil {
+		in, out := &in.Binlog, &out.Binlog
+		*out = new(MariaDBBinlogSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MariaDBSpec.
@@ -352,6 +681,11 @@ func (in *NodepoolBuilderSpec) DeepCopyInto(out *NodepoolBuilderSpec) {
 		*out = new(LimitsSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodepoolBuilderSpec.
@@ -364,6 +698,31 @@ func (in *NodepoolBuilderSpec) DeepCopy() *NodepoolBuilderSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodepoolLauncherGroupSpec) DeepCopyInto(out *NodepoolLauncherGroupSpec) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(LimitsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodepoolLauncherGroupSpec.
+func (in *NodepoolLauncherGroupSpec) DeepCopy() *NodepoolLauncherGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodepoolLauncherGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodepoolLauncherSpec) DeepCopyInto(out *NodepoolLauncherSpec) {
 	*out = *in
@@ -372,6 +731,13 @@ func (in *NodepoolLauncherSpec) DeepCopyInto(out *NodepoolLauncherSpec) {
 		*out = new(LimitsSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]NodepoolLauncherGroupSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodepoolLauncherSpec.
@@ -557,7 +923,14 @@ func (in *SoftwareFactorySpec) DeepCopyInto(out *SoftwareFactorySpec) {
 	in.Zookeeper.DeepCopyInto(&out.Zookeeper)
 	in.Logserver.DeepCopyInto(&out.Logserver)
 	in.Logjuicer.DeepCopyInto(&out.Logjuicer)
+	in.Weeder.DeepCopyInto(&out.Weeder)
+	in.ZuulCapacity.DeepCopyInto(&out.ZuulCapacity)
 	in.MariaDB.DeepCopyInto(&out.MariaDB)
+	if in.ExternalDatabase != nil {
+		in, out := &in.ExternalDatabase, &out.ExternalDatabase
+		*out = new(ExternalDatabaseSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.GitServer.DeepCopyInto(&out.GitServer)
 	in.Codesearch.DeepCopyInto(&out.Codesearch)
 	if in.HostAliases != nil {
@@ -594,6 +967,11 @@ func (in *SoftwareFactoryStatus) DeepCopyInto(out *SoftwareFactoryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExecutorPools != nil {
+		in, out := &in.ExecutorPools, &out.ExecutorPools
+		*out = make([]ExecutorPoolStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoftwareFactoryStatus.
@@ -668,6 +1046,36 @@ func (in *StorageSpec) DeepCopy() *StorageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeederSpec) DeepCopyInto(out *WeederSpec) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(StorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(LimitsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeederSpec.
+func (in *WeederSpec) DeepCopy() *WeederSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WeederSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZookeeperSpec) DeepCopyInto(out *ZookeeperSpec) {
 	*out = *in
@@ -689,6 +1097,57 @@ func (in *ZookeeperSpec) DeepCopy() *ZookeeperSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZuulCapacitySpec) DeepCopyInto(out *ZuulCapacitySpec) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(LimitsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZuulCapacitySpec.
+func (in *ZuulCapacitySpec) DeepCopy() *ZuulCapacitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ZuulCapacitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZuulExecutorPoolSpec) DeepCopyInto(out *ZuulExecutorPoolSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(LimitsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.StandaloneZuulExecutorSpec.DeepCopyInto(&out.StandaloneZuulExecutorSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZuulExecutorPoolSpec.
+func (in *ZuulExecutorPoolSpec) DeepCopy() *ZuulExecutorPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ZuulExecutorPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZuulExecutorSpec) DeepCopyInto(out *ZuulExecutorSpec) {
 	*out = *in
@@ -708,6 +1167,23 @@ func (in *ZuulExecutorSpec) DeepCopyInto(out *ZuulExecutorSpec) {
 		*out = new(LimitsSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]ZuulExecutorPoolSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GitCache != nil {
+		in, out := &in.GitCache, &out.GitCache
+		*out = new(GitCacheSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZuulExecutorSpec.
@@ -723,6 +1199,11 @@ func (in *ZuulExecutorSpec) DeepCopy() *ZuulExecutorSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZuulMergerSpec) DeepCopyInto(out *ZuulMergerSpec) {
 	*out = *in
+	if in.GitCache != nil {
+		in, out := &in.GitCache, &out.GitCache
+		*out = new(GitCacheSpec)
+		**out = **in
+	}
 	in.Storage.DeepCopyInto(&out.Storage)
 	if in.Limits != nil {
 		in, out := &in.Limits, &out.Limits
@@ -840,6 +1321,11 @@ func (in *ZuulSpec) DeepCopyInto(out *ZuulSpec) {
 	in.Scheduler.DeepCopyInto(&out.Scheduler)
 	in.Web.DeepCopyInto(&out.Web)
 	in.Merger.DeepCopyInto(&out.Merger)
+	if in.BuildRetention != nil {
+		in, out := &in.BuildRetention, &out.BuildRetention
+		*out = new(BuildRetentionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZuulSpec.
@@ -49,6 +49,14 @@ func backupCmd(kmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if binlogsOnly, _ := kmd.Flags().GetBool("binlogs-only"); binlogsOnly {
+		if err := env.DoBinlogArchive(backupDir, cr); err != nil {
+			ctrl.Log.Error(err, "Couldn't archive the binary logs")
+			os.Exit(1)
+		}
+		return
+	}
+
 	// TODO: check that the CR name and the FQDN match the cr being backuped
 	if err := env.DoBackup(backupDir, cr); err != nil {
 		os.Exit(1)
@@ -58,8 +66,9 @@ func backupCmd(kmd *cobra.Command, args []string) {
 func MkBackupCmd() *cobra.Command {
 
 	var (
-		backupDir string
-		backupCmd = &cobra.Command{
+		backupDir   string
+		binlogsOnly bool
+		backupCmd   = &cobra.Command{
 			Use:   "backup",
 			Short: "Create a backup of a deployment",
 			Long:  `This command will do a backup of important resources`,
@@ -68,5 +77,6 @@ func MkBackupCmd() *cobra.Command {
 	)
 
 	backupCmd.Flags().StringVar(&backupDir, "backup_dir", "", "The path to the backup directory")
+	backupCmd.Flags().BoolVar(&binlogsOnly, "binlogs-only", false, "Only archive the MariaDB binary logs in the backup directory")
 	return backupCmd
 }
@@ -52,6 +52,8 @@ with boilerplate code that define standard pipelines:
 * "gate" for approved commits gating
 * "post for post-commit actions
 
+The "git" driver only gets the "post" pipeline, since it has no change to review.
+
 it also includes a boilerplate job and pre-run playbook.
 
 This will generate the following files:
@@ -83,38 +85,6 @@ func InitConfigRepo(driver string, connection string, zuulrootdir string) {
 
 	createDirectoryStructureOrDie(zuulrootdir)
 
-	// Check Pipeline
-	requireCheck, err := utils.GetRequireCheckByDriver(driver, connection)
-	if err != nil {
-		ctrl.Log.Error(err, "Could not define check pipeline require config for "+driver)
-		os.Exit(1)
-	}
-	triggerCheck, err := utils.GetTriggerCheckByDriver(driver, connection)
-	if err != nil {
-		ctrl.Log.Error(err, "Could not define check pipeline trigger config for "+driver)
-		os.Exit(1)
-	}
-	reportersCheck, err := utils.GetReportersCheckByDriver(driver, connection)
-	if err != nil {
-		ctrl.Log.Error(err, "Could not define check pipeline reporters config for "+driver)
-		os.Exit(1)
-	}
-	// Gate Pipeline
-	requireGate, err := utils.GetRequireGateByDriver(driver, connection)
-	if err != nil {
-		ctrl.Log.Error(err, "Could not define gate pipeline require config for "+driver)
-		os.Exit(1)
-	}
-	triggerGate, err := utils.GetTriggerGateByDriver(driver, connection)
-	if err != nil {
-		ctrl.Log.Error(err, "Could not define gate pipeline trigger config for "+driver)
-		os.Exit(1)
-	}
-	reportersGate, err := utils.GetReportersGateByDriver(driver, connection)
-	if err != nil {
-		ctrl.Log.Error(err, "Could not define gate pipeline trigger config for "+driver)
-		os.Exit(1)
-	}
 	// Post Pipeline
 	triggerPost, err := utils.GetTriggerPostByDriver(driver, connection)
 	if err != nil {
@@ -122,49 +92,87 @@ func InitConfigRepo(driver string, connection string, zuulrootdir string) {
 		os.Exit(1)
 	}
 
-	zuulpipelinefilepath := filepath.Join(zuulrootdir, zuuldropindir, connection+"-pipeline.yaml")
-	writeFileOrDie(utils.PipelineConfig{
-		{
-			Pipeline: utils.PipelineBody{
-				Name: "check",
-				Description: `Newly uploaded patchsets enter this
+	pipelines := utils.PipelineConfig{}
+	// The post only drivers have no change to review, thus no check and gate pipelines
+	if !utils.IsPostOnlyDriver(driver) {
+		// Check Pipeline
+		requireCheck, err := utils.GetRequireCheckByDriver(driver, connection)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not define check pipeline require config for "+driver)
+			os.Exit(1)
+		}
+		triggerCheck, err := utils.GetTriggerCheckByDriver(driver, connection)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not define check pipeline trigger config for "+driver)
+			os.Exit(1)
+		}
+		reportersCheck, err := utils.GetReportersCheckByDriver(driver, connection)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not define check pipeline reporters config for "+driver)
+			os.Exit(1)
+		}
+		// Gate Pipeline
+		requireGate, err := utils.GetRequireGateByDriver(driver, connection)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not define gate pipeline require config for "+driver)
+			os.Exit(1)
+		}
+		triggerGate, err := utils.GetTriggerGateByDriver(driver, connection)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not define gate pipeline trigger config for "+driver)
+			os.Exit(1)
+		}
+		reportersGate, err := utils.GetReportersGateByDriver(driver, connection)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not define gate pipeline trigger config for "+driver)
+			os.Exit(1)
+		}
+		pipelines = utils.PipelineConfig{
+			{
+				Pipeline: utils.PipelineBody{
+					Name: "check",
+					Description: `Newly uploaded patchsets enter this
 pipeline to receive an initial +/-1 Verified vote.`,
-				Manager: utils.Independent,
-				Require: requireCheck,
-				Trigger: triggerCheck,
-				Start:   reportersCheck[0],
-				Success: reportersCheck[1],
-				Failure: reportersCheck[2],
-			},
-		},
-		{
-			Pipeline: utils.PipelineBody{
-				Name:           "gate",
-				Description:    `Changes that have been approved by core developers are enqueued in order in this pipeline, and if they pass tests, will be merged.`,
-				SuccessMessage: "Build succeeded (gate pipeline).",
-				FailureMessage: "Build failed (gate pipeline).",
-				Precedence:     utils.GetZuulPipelinePrecedence("high"),
-				Supercedes:     []string{"check"},
-				PostReview:     true,
-				Manager:        utils.Dependent,
-				Require:        requireGate,
-				Trigger:        triggerGate,
-				Start:          reportersGate[0],
-				Success:        reportersGate[1],
-				Failure:        reportersGate[2],
+					Manager: utils.Independent,
+					Require: requireCheck,
+					Trigger: triggerCheck,
+					Start:   reportersCheck[0],
+					Success: reportersCheck[1],
+					Failure: reportersCheck[2],
+				},
 			},
-		},
-		{
-			Pipeline: utils.PipelineBody{
-				Name:        "post",
-				PostReview:  true,
-				Description: `This pipeline runs jobs that operate after each change is merged.`,
-				Manager:     utils.Supercedent,
-				Precedence:  utils.GetZuulPipelinePrecedence("low"),
-				Trigger:     triggerPost,
+			{
+				Pipeline: utils.PipelineBody{
+					Name:           "gate",
+					Description:    `Changes that have been approved by core developers are enqueued in order in this pipeline, and if they pass tests, will be merged.`,
+					SuccessMessage: "Build succeeded (gate pipeline).",
+					FailureMessage: "Build failed (gate pipeline).",
+					Precedence:     utils.GetZuulPipelinePrecedence("high"),
+					Supercedes:     []string{"check"},
+					PostReview:     true,
+					Manager:        utils.Dependent,
+					Require:        requireGate,
+					Trigger:        triggerGate,
+					Start:          reportersGate[0],
+					Success:        reportersGate[1],
+					Failure:        reportersGate[2],
+				},
 			},
+		}
+	}
+	pipelines = append(pipelines, utils.Pipeline{
+		Pipeline: utils.PipelineBody{
+			Name:        "post",
+			PostReview:  true,
+			Description: `This pipeline runs jobs that operate after each change is merged.`,
+			Manager:     utils.Supercedent,
+			Precedence:  utils.GetZuulPipelinePrecedence("low"),
+			Trigger:     triggerPost,
 		},
-	}, zuulpipelinefilepath)
+	})
+
+	zuulpipelinefilepath := filepath.Join(zuulrootdir, zuuldropindir, connection+"-pipeline.yaml")
+	writeFileOrDie(pipelines, zuulpipelinefilepath)
 
 	zuuljobfilepath := filepath.Join(zuulrootdir, zuuldropindir, connection+"-base-jobs.yaml")
 	writeFileOrDie(utils.JobConfig{
@@ -173,7 +181,7 @@ pipeline to receive an initial +/-1 Verified vote.`,
 				Name:        "base",
 				Description: "The base job.",
 				Parent:      nil,
-				PreRun: []string{
+				PreRun: []interface{}{
 					"playbooks/" + connection + "-pre.yaml",
 				},
 				Roles: []utils.JobRoles{
@@ -230,18 +238,21 @@ pipeline to receive an initial +/-1 Verified vote.`,
 		},
 	}, zuuljobplaybookfilepath)
 
+	if utils.IsPostOnlyDriver(driver) {
+		return
+	}
 	zuulppfilepath := filepath.Join(zuulrootdir, zuuldropindir, connection+"-project-pipeline.yaml")
 	writeFileOrDie(utils.ProjectConfig{{
 		Project: utils.ZuulProjectBody{
 			Pipeline: utils.ZuulProjectPipelineMap{
 				"check": utils.ZuulProjectPipeline{
-					Jobs: []string{
-						"noop",
+					Jobs: []utils.ProjectPipelineJob{
+						{Name: "noop"},
 					},
 				},
 				"gate": utils.ZuulProjectPipeline{
-					Jobs: []string{
-						"noop",
+					Jobs: []utils.ProjectPipelineJob{
+						{Name: "noop"},
 					},
 				},
 			},
@@ -254,6 +265,6 @@ func MkBootstrapCmd() *cobra.Command {
 		driver     string
 	)
 	BootstrapTenantConfigRepoCmd.Flags().StringVar(&connection, "connection", "", "Name of the connection or a source")
-	BootstrapTenantConfigRepoCmd.Flags().StringVar(&driver, "driver", "", "Driver type of the connection. Supported drivers: gitlab, gerrit")
+	BootstrapTenantConfigRepoCmd.Flags().StringVar(&driver, "driver", "", "Driver type of the connection. Supported drivers: gerrit, gitlab, github, pagure, git (post pipeline only)")
 	return BootstrapTenantConfigRepoCmd
 }
@@ -28,6 +28,7 @@ import (
 
 	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
 	"github.com/softwarefactory-project/sf-operator/cli/cmd/dev/gerrit"
+	"github.com/softwarefactory-project/sf-operator/cli/cmd/dev/minio"
 	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
 	"github.com/softwarefactory-project/sf-operator/controllers"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
@@ -38,8 +39,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-var devCreateAllowedArgs = []string{"gerrit", "demo-env"}
-var devWipeAllowedArgs = []string{"gerrit", "sf"}
+var devCreateAllowedArgs = []string{"gerrit", "demo-env", "minio"}
+var devWipeAllowedArgs = []string{"gerrit", "sf", "minio"}
 
 func ensureGatewayRoute(env *controllers.SFKubeContext, fqdn string) {
 	route := cliutils.MkHTTPSRoute("sf-gateway", env.Ns, fqdn, "gateway", "/", 8080, map[string]string{})
@@ -109,6 +110,8 @@ func devCreate(kmd *cobra.Command, args []string) {
 		sfOperatorRepoPath, _ := kmd.Flags().GetString("sf-operator-repository-path")
 		createDemoEnv(env, fqdn, reposPath, sfOperatorRepoPath, keepDemoTenantDefinition, hostAliasesSlice)
 
+	} else if target == "minio" {
+		minio.EnsureMinio(env)
 	} else {
 		ctrl.Log.Error(errors.New("unsupported target"), "Invalid argument '"+target+"'")
 	}
@@ -120,6 +123,8 @@ func devWipe(kmd *cobra.Command, args []string) {
 	rmData, _ := kmd.Flags().GetBool("rm-data")
 	if target == "gerrit" {
 		gerrit.WipeGerrit(env, rmData)
+	} else if target == "minio" {
+		minio.WipeMinio(env, rmData)
 	} else if target == "sf" {
 		env.CleanSFInstance()
 		if rmData {
@@ -217,6 +222,9 @@ func getImagesSecurityIssues(kmd *cobra.Command, args []string) {
 	}
 
 	getImageDigest := func(image base.Image) string {
+		if image.IsDigest() {
+			return image.Version
+		}
 		container := getContainerPath(image)
 		url := quayBaseURL + container
 		resp, _ := http.Get(url)
@@ -347,13 +355,13 @@ func MkDevCmd() *cobra.Command {
 		}
 		createCmd = &cobra.Command{
 			Use:       "create {" + strings.Join(devCreateAllowedArgs, ", ") + "}",
-			Long:      "Create a development resource. The resource can be a MicroShift cluster, a demo environment or a gerrit instance",
+			Long:      "Create a development resource. The resource can be a MicroShift cluster, a demo environment, a gerrit instance or a MinIO instance for the logserver s3 backend",
 			ValidArgs: devCreateAllowedArgs,
 			Run:       devCreate,
 		}
 		wipeCmd = &cobra.Command{
 			Use:       "wipe {" + strings.Join(devWipeAllowedArgs, ", ") + "}",
-			Long:      "Wipe a development resource. The resource can be a gerrit instance.",
+			Long:      "Wipe a development resource. The resource can be a gerrit or a MinIO instance.",
 			ValidArgs: devWipeAllowedArgs,
 			Run:       devWipe,
 		}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package minio provides a MinIO instance to test the S3 backend of the logserver
+package minio
+
+import (
+	"time"
+
+	v1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	cutils "github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
+)
+
+const minioIdent = "minio"
+const minioPort = 9000
+const minioPortName = "minio-s3"
+const minioDataPath = "/data"
+const minioImage = "quay.io/minio/minio:RELEASE.2024-11-07T00-52-20Z"
+
+// CredentialsSecret is the secret holding the MinIO credentials, in the format expected by the logserver s3 backend
+const CredentialsSecret = "logserver-s3"
+
+// Endpoint is the in-cluster URL of the MinIO S3 API
+const Endpoint = "http://minio:9000"
+
+func ensureCredentialsOrDie(env *controllers.SFKubeContext) {
+	var secret apiv1.Secret
+	if !env.GetOrDie(CredentialsSecret, &secret) {
+		secret = apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: CredentialsSecret, Namespace: env.Ns},
+			Data: map[string][]byte{
+				"access_key": []byte("sf-logs"),
+				"secret_key": []byte(cutils.NewUUIDString()),
+			},
+		}
+		env.CreateROrDie(&secret)
+	}
+}
+
+func mkStatefulSet(env *controllers.SFKubeContext) appsv1.StatefulSet {
+	container := base.MkContainer(minioIdent, minioImage, env.IsOpenShift)
+	container.Command = []string{"minio", "server", minioDataPath, "--address", ":9000"}
+	container.Env = []apiv1.EnvVar{
+		base.MkEnvVar("HOME", "/tmp"),
+		base.MkSecretEnvVar("MINIO_ROOT_USER", CredentialsSecret, "access_key"),
+		base.MkSecretEnvVar("MINIO_ROOT_PASSWORD", CredentialsSecret, "secret_key"),
+	}
+	container.Ports = []apiv1.ContainerPort{
+		base.MkContainerPort(minioPort, minioPortName),
+	}
+	container.VolumeMounts = []apiv1.VolumeMount{
+		{
+			Name:      minioIdent,
+			MountPath: minioDataPath,
+		},
+	}
+	container.ReadinessProbe = base.MkReadinessHTTPProbe("/minio/health/ready", minioPort)
+	base.SetContainerLimits(
+		&container,
+		resource.MustParse("256Mi"),
+		resource.MustParse("512Mi"),
+		resource.MustParse("100m"),
+		resource.MustParse("500m"))
+
+	storageConfig := controllers.BaseGetStorageConfOrDefault(v1.StorageSpec{}, v1.StorageDefaultSpec{})
+	pvc := base.MkPVC(minioIdent, env.Ns, storageConfig, apiv1.ReadWriteOnce)
+	return base.MkStatefulset(minioIdent, env.Ns, 1, minioIdent, container, pvc, map[string]string{})
+}
+
+// EnsureMinio deploys a MinIO instance and the logserver-s3 secret
+func EnsureMinio(env *controllers.SFKubeContext) {
+	ensureCredentialsOrDie(env)
+
+	var svc apiv1.Service
+	if !env.GetOrDie(minioIdent, &svc) {
+		svc = base.MkService(minioIdent, env.Ns, minioIdent, []int32{minioPort}, minioPortName, map[string]string{})
+		env.CreateROrDie(&svc)
+	}
+
+	var sts appsv1.StatefulSet
+	if !env.GetOrDie(minioIdent, &sts) {
+		sts = mkStatefulSet(env)
+		env.CreateROrDie(&sts)
+	}
+
+	for !base.IsStatefulSetRolloutDone(&sts) {
+		ctrl.Log.Info("Waiting 5s for the minio statefulset to be ready...")
+		time.Sleep(5 * time.Second)
+		env.GetOrDie(minioIdent, &sts)
+	}
+	ctrl.Log.Info("MinIO is ready, set the logserver backend to s3 with the endpoint " + Endpoint +
+		" and the secretName " + CredentialsSecret)
+}
+
+// WipeMinio removes the MinIO instance
+func WipeMinio(env *controllers.SFKubeContext, rmData bool) {
+	ns := env.Ns
+	env.DeleteOrDie(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: minioIdent, Namespace: ns}})
+	env.DeleteOrDie(&apiv1.Service{ObjectMeta: metav1.ObjectMeta{Name: minioIdent, Namespace: ns}})
+	env.DeleteOrDie(&apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: CredentialsSecret, Namespace: ns}})
+	if rmData {
+		env.DeleteOrDie(&apiv1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: minioIdent + "-" + minioIdent + "-0", Namespace: ns}})
+	}
+}
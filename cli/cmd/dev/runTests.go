@@ -73,11 +73,11 @@ func SetupDemoConfigRepo(reposPath, zuulDriver, zuulConnection string, updateDem
 					Name: "demo-tenant",
 					Source: zuulcf.TenantConnectionSource{
 						"opendev.org": {
-							UntrustedProjects: []string{"zuul/zuul-jobs"},
+							UntrustedProjects: []any{"zuul/zuul-jobs"},
 						},
 						zuulConnection: {
-							ConfigProjects:    []string{"demo-tenant-config"},
-							UntrustedProjects: []string{"demo-project"},
+							ConfigProjects:    []any{"demo-tenant-config"},
+							UntrustedProjects: []any{"demo-project"},
 						},
 					},
 				},
@@ -139,18 +139,18 @@ func SetupDemoProjectRepo(reposPath string, fqdn string) {
 			Project: zuulcf.ZuulProjectBody{
 				Pipeline: zuulcf.ZuulProjectPipelineMap{
 					"check": zuulcf.ZuulProjectPipeline{
-						Jobs: []string{
-							"demo-job",
+						Jobs: []zuulcf.ProjectPipelineJob{
+							{Name: "demo-job"},
 						},
 					},
 					"gate": zuulcf.ZuulProjectPipeline{
-						Jobs: []string{
-							"demo-job",
+						Jobs: []zuulcf.ProjectPipelineJob{
+							{Name: "demo-job"},
 						},
 					},
 					"post": zuulcf.ZuulProjectPipeline{
-						Jobs: []string{
-							"publish-job",
+						Jobs: []zuulcf.ProjectPipelineJob{
+							{Name: "publish-job"},
 						},
 					},
 				},
@@ -0,0 +1,131 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+/*
+"export" and "diff" subcommands read the SoftwareFactory resource last reconciled in standalone mode.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	"github.com/softwarefactory-project/sf-operator/controllers"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// readDeployedSF returns the resource stored in the standalone owner ConfigMap of the namespace
+func readDeployedSF(kmd *cobra.Command) sfv1.SoftwareFactory {
+	env := cliutils.GetCLIContext(kmd)
+	if !env.GetStandaloneOwner() {
+		ctrl.Log.Error(errors.New("no owner found"), "Software Factory doesn't seem to be deployed in the namespace "+env.Ns)
+		os.Exit(1)
+	}
+	cm := env.Owner.(*corev1.ConfigMap)
+	sf, warnings, err := controllers.ReadStandaloneOwnerSF(*cm)
+	if err != nil {
+		ctrl.Log.Error(err, "Could not reconstruct the SoftwareFactory resource")
+		os.Exit(1)
+	}
+	for _, warning := range warnings {
+		ctrl.Log.Info(warning)
+	}
+	return sf
+}
+
+func exportCmd(kmd *cobra.Command, args []string) {
+	sf := readDeployedSF(kmd)
+
+	var manifest SFManifest
+	manifest.APIVersion = sf.APIVersion
+	manifest.Kind = sf.Kind
+	manifest.Metadata.Name = sf.Name
+	manifest.Metadata.Annotations = sf.Annotations
+	manifest.Spec = sf.Spec
+
+	yamlData, err := yaml.Marshal(manifest)
+	if err != nil {
+		ctrl.Log.Error(err, "Could not serialize the manifest")
+		os.Exit(1)
+	}
+	if outputPath, _ := kmd.Flags().GetString("output"); outputPath != "" {
+		cliutils.WriteContentToFile(outputPath, yamlData, 0644)
+		ctrl.Log.Info("The SoftwareFactory resource " + sf.Name + " is written to " + outputPath)
+		return
+	}
+	fmt.Print(string(yamlData))
+}
+
+func diffCmd(kmd *cobra.Command, args []string) {
+	cliutils.SetLogger(kmd)
+	local, err := controllers.ReadSFYAML(args[0])
+	if err != nil {
+		os.Exit(1)
+	}
+	deployed := readDeployedSF(kmd)
+
+	diff, err := controllers.DiffSpec(deployed.Spec, local.Spec)
+	if err != nil {
+		ctrl.Log.Error(err, "Could not compare the SoftwareFactory resources")
+		os.Exit(1)
+	}
+	if deployed.Name != local.Name {
+		diff = append([]string{"~ metadata.name: " + deployed.Name + " -> " + local.Name}, diff...)
+	}
+	if len(diff) == 0 {
+		fmt.Println("The SoftwareFactory resource " + args[0] + " is the one deployed")
+		return
+	}
+	fmt.Printf("--- deployed (sf-operator %s)\n+++ %s\n", deployed.Annotations["sf-operator-version"], args[0])
+	fmt.Println(strings.Join(diff, "\n"))
+	if exitCode, _ := kmd.Flags().GetBool("exit-code"); exitCode {
+		os.Exit(1)
+	}
+}
+
+func MkExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the SoftwareFactory resource of a deployment",
+		Long: "Reconstruct the SoftwareFactory resource last reconciled in standalone mode, from the sf-standalone-owner ConfigMap. " +
+			"The resource is annotated with the FQDN, the version of the operator and the time of the last reconcile.",
+		Args: cobra.NoArgs,
+		Run:  exportCmd,
+	}
+	exportCmd.Flags().String("output", "", "the file where the resource is written, instead of the standard output")
+	return exportCmd
+}
+
+func MkDiffCmd() *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff CR",
+		Short: "Compare a SoftwareFactory resource with the one deployed",
+		Long: "Report the attributes that differ between a local SoftwareFactory resource and the resource last reconciled " +
+			"in standalone mode. The specs are compared after their decoding, the formatting and the order of the keys do not matter.",
+		Args: cobra.ExactArgs(1),
+		Run:  diffCmd,
+	}
+	diffCmd.Flags().Bool("exit-code", false, "exit with 1 when the resources differ")
+	return diffCmd
+}
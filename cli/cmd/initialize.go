@@ -17,16 +17,27 @@ limitations under the License.
 package cmd
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 
 	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	"github.com/softwarefactory-project/sf-operator/controllers"
 	controllerutils "github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
 
@@ -36,12 +47,44 @@ type SFManifest struct {
 	APIVersion string `json:"apiVersion"`
 	Kind       string `json:"kind"`
 	Metadata   struct {
-		Name string `json:"name"`
+		Name        string            `json:"name"`
+		Annotations map[string]string `json:"annotations,omitempty"`
 	} `json:"metadata"`
 	Spec sfv1.SoftwareFactorySpec `json:"spec"`
 }
 
-func initializeSFManifest(withAuth bool, withBuilder bool, full bool, connections []string) {
+// connectionSecretKeys are the keys of the Secret expected by the connections that need one
+var connectionSecretKeys = map[string][]string{
+	"github": {"api_token", "webhook_token"},
+	"gitlab": {"api_token", "webhook_token"},
+	"pagure": {"api_token"},
+}
+
+// manifestOptions are the settings of the generated manifest, from the flags, the prompts and the cluster
+type manifestOptions struct {
+	withAuth     bool
+	withBuilder  bool
+	full         bool
+	connections  []string
+	fqdn         string
+	oidc         sfv1.ZuulOIDCAuthenticatorSpec
+	storageClass string
+	// secrets maps a connection to the name of its Secret
+	secrets map[string]string
+}
+
+// SecretSkeleton is a Secret to fill with the credentials of a connection, before applying it
+type SecretSkeleton struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Type       string            `json:"type"`
+	StringData map[string]string `json:"stringData"`
+}
+
+func initializeSFManifest(opts manifestOptions) SFManifest {
 	var manifest SFManifest
 	oneGi := controllerutils.Qty1Gi()
 
@@ -50,7 +93,9 @@ func initializeSFManifest(withAuth bool, withBuilder bool, full bool, connection
 	manifest.Metadata.Name = "my-sf"
 
 	manifest.Spec.ConfigRepositoryLocation.Name = "config"
-	manifest.Spec.ConfigRepositoryLocation.ZuulConnectionName = connections[0]
+	manifest.Spec.ConfigRepositoryLocation.ZuulConnectionName = opts.connections[0]
+
+	manifest.Spec.StorageDefault.ClassName = opts.storageClass
 
 	manifest.Spec.Logserver.LoopDelay = 3600
 	manifest.Spec.Logserver.RetentionDays = 15
@@ -68,27 +113,26 @@ func initializeSFManifest(withAuth bool, withBuilder bool, full bool, connection
 		PollDelay: 300,
 	}
 	gitlabConnection := sfv1.GitLabConnection{
-		Name: "gitlab",
+		Name:    "gitlab",
+		Secrets: opts.secrets["gitlab"],
 	}
 	githubConnection := sfv1.GitHubConnection{
-		Name: "github",
+		Name:    "github",
+		Secrets: opts.secrets["github"],
 	}
 	pagureConnection := sfv1.PagureConnection{
-		Name: "pagure",
+		Name:    "pagure",
+		Secrets: opts.secrets["pagure"],
 	}
-	if withAuth {
-		oidcAuth := sfv1.ZuulOIDCAuthenticatorSpec{
-			Name:     "zuulAuth",
-			Realm:    "zuul",
-			ClientID: "zuul-clientid",
-			IssuerID: "iss",
-		}
+	if opts.withAuth {
+		oidcAuth := opts.oidc
+		oidcAuth.Name = "zuulAuth"
 		manifest.Spec.Zuul.OIDCAuthenticators = []sfv1.ZuulOIDCAuthenticatorSpec{oidcAuth}
 		manifest.Spec.Zuul.DefaultAuthenticator = "zuulAuth"
 	}
 	manifest.Spec.Zuul.Executor.LogLevel = "INFO"
 	manifest.Spec.Zuul.Executor.Storage.Size = oneGi
-	for _, co := range connections {
+	for _, co := range opts.connections {
 		if co == "gerrit" {
 			manifest.Spec.Zuul.GerritConns = []sfv1.GerritConnection{gerritConnection}
 		} else if co == "git" {
@@ -104,9 +148,9 @@ func initializeSFManifest(withAuth bool, withBuilder bool, full bool, connection
 		}
 	}
 
-	manifest.Spec.FQDN = "sfop.me"
+	manifest.Spec.FQDN = opts.fqdn
 
-	if full {
+	if opts.full {
 		fbSpec := sfv1.FluentBitForwarderSpec{
 			ForwardInputHost: "fluentbit",
 			ForwardInputPort: 24224,
@@ -124,7 +168,7 @@ func initializeSFManifest(withAuth bool, withBuilder bool, full bool, connection
 		}
 		manifest.Spec.Zuul.ElasticSearchConns = []sfv1.ElasticSearchConnection{esConnection}
 
-		if withBuilder {
+		if opts.withBuilder {
 			manifest.Spec.Nodepool.Builder.LogLevel = "INFO"
 			manifest.Spec.Nodepool.Builder.Storage.Size = oneGi
 		}
@@ -138,21 +182,260 @@ func initializeSFManifest(withAuth bool, withBuilder bool, full bool, connection
 		manifest.Spec.Zuul.Web.LogLevel = "INFO"
 	}
 
-	yamlData, err := yaml.Marshal(manifest)
+	return manifest
+}
+
+// mkSecretSkeletons returns the Secrets of the connections that do not exist yet
+func mkSecretSkeletons(opts manifestOptions, existingSecrets []corev1.Secret) []SecretSkeleton {
+	skeletons := []SecretSkeleton{}
+	for _, co := range opts.connections {
+		name, ok := opts.secrets[co]
+		if !ok || slices.ContainsFunc(existingSecrets, func(s corev1.Secret) bool { return s.Name == name }) {
+			continue
+		}
+		skeleton := SecretSkeleton{APIVersion: "v1", Kind: "Secret", Type: "Opaque", StringData: map[string]string{}}
+		skeleton.Metadata.Name = name
+		for _, key := range connectionSecretKeys[co] {
+			skeleton.StringData[key] = "<" + key + ">"
+		}
+		skeletons = append(skeletons, skeleton)
+	}
+	return skeletons
+}
+
+// prompt asks a question on stderr, since the manifest is written to stdout, and returns the answer or the default value
+func prompt(reader *bufio.Reader, question string, defaultValue string) string {
+	if defaultValue != "" {
+		question += " [" + defaultValue + "]"
+	}
+	fmt.Fprint(os.Stderr, question+": ")
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		if errors.Is(err, io.EOF) {
+			return defaultValue
+		}
+		ctrl.Log.Error(err, "Could not read the answer")
+		os.Exit(1)
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultValue
+	}
+	return answer
+}
+
+func promptBool(reader *bufio.Reader, question string, defaultValue bool) bool {
+	defaultAnswer := "n"
+	if defaultValue {
+		defaultAnswer = "y"
+	}
+	return strings.HasPrefix(strings.ToLower(prompt(reader, question+" (y/n)", defaultAnswer)), "y")
+}
+
+// clusterInfo is what the cluster tells about the deployment
+type clusterInfo struct {
+	isOpenShift    bool
+	appsDomain     string
+	storageClasses []storagev1.StorageClass
+	defaultClass   string
+	secrets        []corev1.Secret
+}
+
+func discoverCluster(kmd *cobra.Command) clusterInfo {
+	env := cliutils.GetCLIContext(kmd)
+	info := clusterInfo{isOpenShift: env.IsOpenShift}
+
+	if info.isOpenShift {
+		// The default FQDN is taken from the domain of the OpenShift routes
+		var ingress unstructured.Unstructured
+		ingress.SetGroupVersionKind(schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Ingress"})
+		if err := env.Client.Get(env.Ctx, client.ObjectKey{Name: "cluster"}, &ingress); err == nil {
+			info.appsDomain, _, _ = unstructured.NestedString(ingress.Object, "spec", "domain")
+		} else {
+			ctrl.Log.Info("Could not read the OpenShift ingress domain: " + err.Error())
+		}
+	} else {
+		ctrl.Log.Info("Kubernetes cluster detected, an Ingress controller is needed to expose the gateway")
+	}
+
+	storageClasses, err := env.ClientSet.StorageV1().StorageClasses().List(env.Ctx, metav1.ListOptions{})
+	if err != nil {
+		ctrl.Log.Error(err, "Could not list the StorageClasses")
+		os.Exit(1)
+	}
+	info.storageClasses = storageClasses.Items
+	for _, sc := range info.storageClasses {
+		if sc.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			info.defaultClass = sc.Name
+		}
+	}
+	if len(info.storageClasses) == 0 {
+		ctrl.Log.Info("No StorageClass found, the PersistentVolumeClaims will not be bound")
+	}
+
+	secrets, err := env.ClientSet.CoreV1().Secrets(env.Ns).List(env.Ctx, metav1.ListOptions{})
 	if err != nil {
-		ctrl.Log.Error(err, "Could not serialize sample manifest")
+		ctrl.Log.Error(err, "Could not list the Secrets of the namespace "+env.Ns)
 		os.Exit(1)
 	}
-	fmt.Println(string(yamlData))
+	info.secrets = secrets.Items
+	return info
+}
+
+// findConnectionSecret returns the Secret to use for a connection: the Secret named after the connection. Another
+// existing Secret with the keys of the connection is only used when confirm accepts it, confirm being nil unless
+// the settings are prompted.
+func findConnectionSecret(co string, secrets []corev1.Secret, confirm func(string) bool) string {
+	name := co + "-conn-secret"
+	candidates := []string{}
+	for _, secret := range secrets {
+		if secret.Name == name {
+			return name
+		}
+		hasKeys := true
+		for _, key := range connectionSecretKeys[co] {
+			if _, ok := secret.Data[key]; !ok {
+				hasKeys = false
+			}
+		}
+		if hasKeys {
+			candidates = append(candidates, secret.Name)
+		}
+	}
+	if confirm != nil {
+		for _, candidate := range candidates {
+			if confirm(candidate) {
+				return candidate
+			}
+		}
+	}
+	return name
+}
+
+func initializeSFManifestOptions(kmd *cobra.Command) (manifestOptions, []corev1.Secret) {
+	var opts manifestOptions
+	opts.withAuth, _ = kmd.Flags().GetBool("with-auth")
+	opts.withBuilder, _ = kmd.Flags().GetBool("with-builder")
+	opts.full, _ = kmd.Flags().GetBool("full")
+	opts.connections, _ = kmd.Flags().GetStringSlice("connection")
+	opts.fqdn, _ = kmd.Flags().GetString("fqdn")
+	if opts.fqdn == "" {
+		opts.fqdn = "sfop.me"
+	}
+	opts.oidc.IssuerID, _ = kmd.Flags().GetString("oidc-issuer")
+	opts.oidc.ClientID, _ = kmd.Flags().GetString("oidc-client-id")
+	opts.oidc.Realm, _ = kmd.Flags().GetString("oidc-realm")
+	opts.storageClass, _ = kmd.Flags().GetString("storage-class")
+	interactive, _ := kmd.Flags().GetBool("interactive")
+	fromCluster, _ := kmd.Flags().GetBool("from-cluster")
+	if kmd.Flags().Changed("oidc-issuer") {
+		opts.withAuth = true
+	}
+
+	var cluster clusterInfo
+	if fromCluster {
+		cluster = discoverCluster(kmd)
+		if !kmd.Flags().Changed("fqdn") && cluster.appsDomain != "" {
+			opts.fqdn = "sf." + cluster.appsDomain
+		}
+		if !kmd.Flags().Changed("storage-class") {
+			opts.storageClass = cluster.defaultClass
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if interactive {
+		if !kmd.Flags().Changed("fqdn") {
+			opts.fqdn = prompt(reader, "FQDN of the deployment", opts.fqdn)
+		}
+		if !kmd.Flags().Changed("connection") {
+			answer := prompt(reader, "Connections, among gerrit, github, git, gitlab, pagure. The first one hosts the config repository",
+				strings.Join(opts.connections, ","))
+			opts.connections = strings.Split(strings.ReplaceAll(answer, " ", ""), ",")
+		}
+		if fromCluster && !kmd.Flags().Changed("storage-class") && len(cluster.storageClasses) > 0 {
+			names := []string{}
+			for _, sc := range cluster.storageClasses {
+				names = append(names, sc.Name)
+			}
+			opts.storageClass = prompt(reader, "StorageClass, among "+strings.Join(names, ", "), opts.storageClass)
+		}
+		if !kmd.Flags().Changed("with-auth") && !kmd.Flags().Changed("oidc-issuer") {
+			opts.withAuth = promptBool(reader, "Enable the OIDC authentication of the Zuul web UI", opts.withAuth)
+		}
+		if opts.withAuth {
+			if !kmd.Flags().Changed("oidc-issuer") {
+				opts.oidc.IssuerID = prompt(reader, "OIDC issuer ID", opts.oidc.IssuerID)
+			}
+			if !kmd.Flags().Changed("oidc-client-id") {
+				opts.oidc.ClientID = prompt(reader, "OIDC client ID", opts.oidc.ClientID)
+			}
+			if !kmd.Flags().Changed("oidc-realm") {
+				opts.oidc.Realm = prompt(reader, "OIDC realm", opts.oidc.Realm)
+			}
+		}
+	}
+
+	if opts.storageClass != "" && fromCluster &&
+		!slices.ContainsFunc(cluster.storageClasses, func(sc storagev1.StorageClass) bool { return sc.Name == opts.storageClass }) {
+		ctrl.Log.Error(errors.New("unknown StorageClass"), "The StorageClass "+opts.storageClass+" does not exist")
+		os.Exit(1)
+	}
+
+	opts.secrets = map[string]string{}
+	for _, co := range opts.connections {
+		if _, ok := connectionSecretKeys[co]; ok {
+			var confirm func(string) bool
+			if interactive {
+				confirm = func(secret string) bool {
+					return promptBool(reader, "Use the existing Secret "+secret+" for the "+co+" connection", false)
+				}
+			}
+			opts.secrets[co] = findConnectionSecret(co, cluster.secrets, confirm)
+		}
+	}
+	return opts, cluster.secrets
+}
+
+func writeSecretSkeletons(skeletons []SecretSkeleton, path string) {
+	docs := []string{}
+	for _, skeleton := range skeletons {
+		yamlData, err := yaml.Marshal(skeleton)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not serialize the Secret "+skeleton.Metadata.Name)
+			os.Exit(1)
+		}
+		docs = append(docs, string(yamlData))
+	}
+	cliutils.WriteContentToFile(path, []byte(strings.Join(docs, "---\n")), 0600)
+	ctrl.Log.Info("The Secrets of the connections are written to " + path + ", fill in the credentials and apply them before the manifest")
 }
 
 func initialize(kmd *cobra.Command, args []string) {
 	if args[0] == "manifest" {
-		withAuth, _ := kmd.Flags().GetBool("with-auth")
-		withBuilder, _ := kmd.Flags().GetBool("with-builder")
-		minimal, _ := kmd.Flags().GetBool("minimal")
-		connections, _ := kmd.Flags().GetStringSlice("connection")
-		initializeSFManifest(withAuth, withBuilder, minimal, connections)
+		cliutils.SetLogger(kmd)
+		opts, existingSecrets := initializeSFManifestOptions(kmd)
+		manifest := initializeSFManifest(opts)
+
+		warnings, err := controllers.ValidateSpec(sfv1.SoftwareFactory{Spec: manifest.Spec})
+		if err != nil {
+			ctrl.Log.Error(err, "The generated manifest is not valid")
+			os.Exit(1)
+		}
+		for _, warning := range warnings {
+			ctrl.Log.Info(warning)
+		}
+
+		if skeletons := mkSecretSkeletons(opts, existingSecrets); len(skeletons) > 0 {
+			secretsFile, _ := kmd.Flags().GetString("secrets-file")
+			writeSecretSkeletons(skeletons, secretsFile)
+		}
+
+		yamlData, err := yaml.Marshal(manifest)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not serialize sample manifest")
+			os.Exit(1)
+		}
+		fmt.Println(string(yamlData))
 	} else {
 		ctrl.Log.Error(errors.New("argument must be in: "+strings.Join(initAllowedArgs, ", ")), "Incorrect target "+args[0])
 		os.Exit(1)
@@ -180,5 +463,12 @@ func MkInitCmd() *cobra.Command {
 	initCmd.Flags().BoolVar(&withBuilder, "with-builder", false, "(manifest) include nodepool builder section")
 	initCmd.Flags().BoolVar(&full, "full", false, "(manifest) return a manifest with optional parameters and entries")
 	initCmd.Flags().StringSliceVar(&connections, "connection", []string{"gerrit"}, "(manifest) include connection (valid connections are gerrit, github, git, gitlab, pagure). The first connection will be assumed to host the config repo.")
+	initCmd.Flags().String("oidc-issuer", "iss", "(manifest) the OIDC issuer ID, implies --with-auth")
+	initCmd.Flags().String("oidc-client-id", "zuul-clientid", "(manifest) the OIDC client ID")
+	initCmd.Flags().String("oidc-realm", "zuul", "(manifest) the OIDC realm")
+	initCmd.Flags().String("storage-class", "", "(manifest) the default StorageClass of the PersistentVolumeClaims")
+	initCmd.Flags().String("secrets-file", "sf-secrets.yaml", "(manifest) the file where the Secrets of the connections to fill in are written")
+	initCmd.Flags().Bool("interactive", false, "(manifest) prompt for the settings that are not given by flags")
+	initCmd.Flags().Bool("from-cluster", false, "(manifest) detect the cluster flavor, the StorageClasses and the existing connection Secrets of the namespace")
 	return initCmd
 }
@@ -0,0 +1,33 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindConnectionSecret(t *testing.T) {
+	other := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-token"},
+		Data:       map[string][]byte{"api_token": []byte("token")},
+	}
+	if name := findConnectionSecret("pagure", []corev1.Secret{other}, nil); name != "pagure-conn-secret" {
+		t.Errorf("An unrelated Secret is reused without confirmation: %s", name)
+	}
+	reject := func(string) bool { return false }
+	if name := findConnectionSecret("pagure", []corev1.Secret{other}, reject); name != "pagure-conn-secret" {
+		t.Errorf("A rejected Secret is reused: %s", name)
+	}
+	accept := func(string) bool { return true }
+	if name := findConnectionSecret("pagure", []corev1.Secret{other}, accept); name != "deploy-token" {
+		t.Errorf("The confirmed Secret is not reused: %s", name)
+	}
+	named := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pagure-conn-secret"}}
+	if name := findConnectionSecret("pagure", []corev1.Secret{other, named}, accept); name != "pagure-conn-secret" {
+		t.Errorf("The Secret named after the connection is not preferred: %s", name)
+	}
+}
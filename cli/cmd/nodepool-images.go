@@ -0,0 +1,150 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+/*
+"nodepool images" subcommands report the state of the diskimage builds, show their logs and trigger new builds.
+*/
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	"github.com/softwarefactory-project/sf-operator/controllers"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// formatImageAge returns the time elapsed since a nodepool state change timestamp
+func formatImageAge(timestamp float64) string {
+	if timestamp <= 0 {
+		return "-"
+	}
+	return time.Since(time.Unix(int64(timestamp), 0)).Truncate(time.Second).String()
+}
+
+func npImagesList(kmd *cobra.Command, args []string) {
+	cliCtx := cliutils.GetCLIContext(kmd)
+	failed, _ := kmd.Flags().GetBool("failed")
+	builds, err := cliCtx.GetNodepoolDibImages()
+	if err != nil {
+		ctrl.Log.Error(err, "Unable to get the diskimage builds from the nodepool-launcher")
+		os.Exit(1)
+	}
+	uploads, err := cliCtx.GetNodepoolImageUploads()
+	if err != nil {
+		ctrl.Log.Error(err, "Unable to get the image uploads from the nodepool-launcher")
+		os.Exit(1)
+	}
+	buildUploads := map[string][]string{}
+	for _, upload := range uploads {
+		buildUploads[upload.BuildName()] = append(buildUploads[upload.BuildName()], upload.Provider+":"+upload.State)
+	}
+	slices.SortFunc(builds, func(a, b controllers.NodepoolDibImage) int {
+		if c := strings.Compare(a.Image, b.Image); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Age, a.Age)
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tIMAGE\tSTATE\tAGE\tFORMATS\tUPLOADS")
+	for _, build := range builds {
+		if failed && build.State != "failed" {
+			continue
+		}
+		fmt.Fprintln(w, strings.Join([]string{
+			build.ID, build.Image, build.State, formatImageAge(build.Age),
+			strings.Join(build.Formats, ","), strings.Join(buildUploads[build.ID], ","),
+		}, "\t"))
+	}
+	w.Flush()
+}
+
+func npImagesLogs(kmd *cobra.Command, args []string) {
+	cliCtx := cliutils.GetCLIContext(kmd)
+	image := args[0]
+	buildID, _ := kmd.Flags().GetString("build")
+	follow, _ := kmd.Flags().GetBool("follow")
+	build := image + "-" + buildID
+	if buildID == "" {
+		builds, err := cliCtx.GetNodepoolBuildLogs(image)
+		if err != nil {
+			ctrl.Log.Error(err, "Unable to list the build logs of "+image)
+			os.Exit(1)
+		}
+		if len(builds) == 0 {
+			ctrl.Log.Error(errors.New("no build log"), "No build log found for image "+image)
+			os.Exit(1)
+		}
+		build = builds[0]
+	}
+	if err := cliCtx.StreamNodepoolBuildLog(build, follow, os.Stdout); err != nil {
+		ctrl.Log.Error(err, "Unable to read the log of build "+build)
+		os.Exit(1)
+	}
+}
+
+func npImagesRebuild(kmd *cobra.Command, args []string) {
+	cliCtx := cliutils.GetCLIContext(kmd)
+	if err := cliCtx.NodepoolImageBuild(args[0]); err != nil {
+		ctrl.Log.Error(err, "Unable to request a build of image "+args[0])
+		os.Exit(1)
+	}
+	ctrl.Log.Info("Build of image " + args[0] + " requested, follow it with \"nodepool images logs " + args[0] + " --follow\"")
+}
+
+func npImagesDelete(kmd *cobra.Command, args []string) {
+	cliCtx := cliutils.GetCLIContext(kmd)
+	if err := cliCtx.NodepoolDibImageDelete(args[0]); err != nil {
+		ctrl.Log.Error(err, "Unable to delete build "+args[0])
+		os.Exit(1)
+	}
+}
+
+func mkNodepoolImagesCmd() *cobra.Command {
+	imagesCmd := &cobra.Command{
+		Use:   "images",
+		Short: "Inspect and manage the diskimage builds",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the diskimage builds with their state and uploads",
+		Args:  cobra.NoArgs,
+		Run:   npImagesList,
+	}
+	listCmd.Flags().Bool("failed", false, "only list the failed builds")
+	logsCmd := &cobra.Command{
+		Use:   "logs IMAGE",
+		Short: "Show the diskimage-builder log of the latest build of an image",
+		Long: "Show the diskimage-builder log of the latest build of an image, read from the nodepool-builder pod. " +
+			"The logs of the failed builds are kept after nodepool removes the builds from the list.",
+		Args: cobra.ExactArgs(1),
+		Run:  npImagesLogs,
+	}
+	logsCmd.Flags().String("build", "", "the build id, defaults to the latest build")
+	logsCmd.Flags().BoolP("follow", "f", false, "keep on streaming the log of a running build")
+	rebuildCmd := &cobra.Command{
+		Use:   "rebuild IMAGE",
+		Short: "Request a new build of an image",
+		Args:  cobra.ExactArgs(1),
+		Run:   npImagesRebuild,
+	}
+	deleteCmd := &cobra.Command{
+		Use:   "delete ID",
+		Short: "Delete a diskimage build and its uploads, ID is the build ID shown by list",
+		Args:  cobra.ExactArgs(1),
+		Run:   npImagesDelete,
+	}
+	imagesCmd.AddCommand(listCmd, logsCmd, rebuildCmd, deleteCmd)
+	return imagesCmd
+}
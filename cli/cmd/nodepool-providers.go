@@ -0,0 +1,492 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+/*
+"nodepool providers" subcommands manage the entries of the clouds.yaml and kube.config files of the nodepool providers secret.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	cliapi "k8s.io/client-go/tools/clientcmd/api"
+
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	"github.com/softwarefactory-project/sf-operator/controllers"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+var npProvidersKinds = []string{"cloud", "context"}
+
+// npProvidersKeys are the providers secret keys managed per kind
+var npProvidersKeys = map[string]string{
+	"cloud":   "clouds.yaml",
+	"context": "kube.config",
+}
+
+// npProvidersChange is the result of a change on an entry of the providers secret
+type npProvidersChange struct {
+	// Before and After are the redacted entry
+	Before string
+	After  string
+	// Data is the new content of the secret key, nil when the key must be removed
+	Data []byte
+}
+
+// redactedValue replaces the credentials and certificates. It does not depend on the value, since a checksum
+// of a short secret, such as a password, could be brute-forced.
+const redactedValue = "<redacted>"
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"password", "secret", "token", "api_key", "key-data", "certificate-data", "authority-data"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactEntry replaces the credentials and certificates of an entry
+func redactEntry(entry interface{}) interface{} {
+	switch value := entry.(type) {
+	case map[string]interface{}:
+		redacted := map[string]interface{}{}
+		for key, item := range value {
+			if _, isMap := item.(map[string]interface{}); !isMap && isSensitiveKey(key) {
+				redacted[key] = redactedValue
+			} else {
+				redacted[key] = redactEntry(item)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := []interface{}{}
+		for _, item := range value {
+			redacted = append(redacted, redactEntry(item))
+		}
+		return redacted
+	default:
+		return entry
+	}
+}
+
+func dumpRedacted(entry interface{}) string {
+	if entry == nil {
+		return ""
+	}
+	out, err := yaml.Marshal(redactEntry(entry))
+	if err != nil {
+		ctrl.Log.Error(err, "Could not serialize the provider entry")
+		os.Exit(1)
+	}
+	return string(out)
+}
+
+func loadClouds(data []byte) (map[string]interface{}, map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	if root == nil {
+		root = map[string]interface{}{}
+	}
+	clouds, ok := root["clouds"].(map[string]interface{})
+	if !ok {
+		if root["clouds"] != nil {
+			return nil, nil, errors.New("the clouds key must be a mapping")
+		}
+		clouds = map[string]interface{}{}
+	}
+	return root, clouds, nil
+}
+
+// validateCloud checks the structure and the required auth keys of a clouds.yaml entry
+func validateCloud(name string, entry interface{}) error {
+	cloud, ok := entry.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the cloud %s must be a mapping", name)
+	}
+	auth, ok := cloud["auth"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the cloud %s must define an auth mapping", name)
+	}
+	// A vendor profile provides the auth_url
+	if _, hasProfile := cloud["profile"]; !hasProfile && auth["auth_url"] == nil {
+		return fmt.Errorf("the cloud %s must define auth.auth_url", name)
+	}
+	hasAll := func(keys ...string) bool {
+		for _, key := range keys {
+			if auth[key] == nil || auth[key] == "" {
+				return false
+			}
+		}
+		return true
+	}
+	switch authType, _ := cloud["auth_type"].(string); authType {
+	case "v3applicationcredential":
+		if !hasAll("application_credential_secret") || !(hasAll("application_credential_id") || hasAll("application_credential_name", "username")) {
+			return fmt.Errorf("the cloud %s must define auth.application_credential_id and auth.application_credential_secret", name)
+		}
+	case "token", "v3token", "v2token":
+		if !hasAll("token") {
+			return fmt.Errorf("the cloud %s must define auth.token", name)
+		}
+	case "", "password", "v3password", "v2password":
+		if !hasAll("password") || !(hasAll("username") || hasAll("user_id")) {
+			return fmt.Errorf("the cloud %s must define auth.username and auth.password", name)
+		}
+	}
+	return nil
+}
+
+// setCloud adds or replaces a cloud of the clouds.yaml with the entry of the source clouds.yaml
+func setCloud(current []byte, name string, source []byte, sourceName string) (npProvidersChange, error) {
+	root, clouds, err := loadClouds(current)
+	if err != nil {
+		return npProvidersChange{}, fmt.Errorf("invalid clouds.yaml in the providers secret: %w", err)
+	}
+	_, sourceClouds, err := loadClouds(source)
+	if err != nil {
+		return npProvidersChange{}, fmt.Errorf("invalid source clouds.yaml: %w", err)
+	}
+	entry, ok := sourceClouds[sourceName]
+	if !ok {
+		return npProvidersChange{}, fmt.Errorf("the cloud %s is not defined in the source clouds.yaml", sourceName)
+	}
+	if err := validateCloud(sourceName, entry); err != nil {
+		return npProvidersChange{}, err
+	}
+	change := npProvidersChange{Before: dumpRedacted(clouds[name]), After: dumpRedacted(entry)}
+	clouds[name] = entry
+	root["clouds"] = clouds
+	change.Data, err = yaml.Marshal(root)
+	return change, err
+}
+
+func removeCloud(current []byte, name string) (npProvidersChange, error) {
+	root, clouds, err := loadClouds(current)
+	if err != nil {
+		return npProvidersChange{}, fmt.Errorf("invalid clouds.yaml in the providers secret: %w", err)
+	}
+	if _, ok := clouds[name]; !ok {
+		return npProvidersChange{}, fmt.Errorf("the cloud %s is not defined in the providers secret", name)
+	}
+	change := npProvidersChange{Before: dumpRedacted(clouds[name])}
+	delete(clouds, name)
+	if len(clouds) > 0 {
+		root["clouds"] = clouds
+		change.Data, err = yaml.Marshal(root)
+	}
+	return change, err
+}
+
+// kubeContextEntry returns a context with its cluster and user, as a generic value that can be redacted
+func kubeContextEntry(config *cliapi.Config, name string) interface{} {
+	context, ok := config.Contexts[name]
+	if !ok {
+		return nil
+	}
+	entry := cliapi.NewConfig()
+	entry.Contexts[name] = context
+	if cluster, ok := config.Clusters[context.Cluster]; ok {
+		entry.Clusters[context.Cluster] = cluster
+	}
+	if user, ok := config.AuthInfos[context.AuthInfo]; ok {
+		entry.AuthInfos[context.AuthInfo] = user
+	}
+	out, _ := clientcmd.Write(*entry)
+	var value map[string]interface{}
+	yaml.Unmarshal(out, &value)
+	delete(value, "apiVersion")
+	delete(value, "kind")
+	delete(value, "preferences")
+	delete(value, "current-context")
+	return value
+}
+
+// validateKubeContext checks that a context can be used from the nodepool pods
+func validateKubeContext(config *cliapi.Config, name string) error {
+	context, ok := config.Contexts[name]
+	if !ok {
+		return fmt.Errorf("the context %s is not defined in the source kubeconfig", name)
+	}
+	cluster, ok := config.Clusters[context.Cluster]
+	if !ok || cluster.Server == "" {
+		return fmt.Errorf("the context %s must reference a cluster with a server", name)
+	}
+	if cluster.CertificateAuthority != "" {
+		return fmt.Errorf("the cluster of the context %s must embed its certificate authority data", name)
+	}
+	user, ok := config.AuthInfos[context.AuthInfo]
+	if !ok {
+		return fmt.Errorf("the context %s must reference a user", name)
+	}
+	if user.ClientCertificate != "" || user.ClientKey != "" || user.TokenFile != "" {
+		return fmt.Errorf("the user of the context %s must embed its credentials, files are not available in the nodepool pods", name)
+	}
+	if user.Token == "" && len(user.ClientKeyData) == 0 && user.Password == "" {
+		return fmt.Errorf("the user of the context %s must define a token, a client certificate or a password", name)
+	}
+	return nil
+}
+
+// removeKubeContext removes a context, and its cluster and user when no other context uses them
+func removeKubeContext(config *cliapi.Config, name string) {
+	context := config.Contexts[name]
+	delete(config.Contexts, name)
+	clusterUsed, userUsed := false, false
+	for _, other := range config.Contexts {
+		clusterUsed = clusterUsed || other.Cluster == context.Cluster
+		userUsed = userUsed || other.AuthInfo == context.AuthInfo
+	}
+	if !clusterUsed {
+		delete(config.Clusters, context.Cluster)
+	}
+	if !userUsed {
+		delete(config.AuthInfos, context.AuthInfo)
+	}
+	if config.CurrentContext == name {
+		config.CurrentContext = ""
+	}
+}
+
+func writeKubeConfig(config *cliapi.Config) ([]byte, error) {
+	if len(config.Contexts) == 0 {
+		return nil, nil
+	}
+	if config.CurrentContext == "" {
+		config.CurrentContext = slices.Sorted(maps.Keys(config.Contexts))[0]
+	}
+	return clientcmd.Write(*config)
+}
+
+// setKubeContext adds or replaces a context of the kube.config with a context of the source kubeconfig.
+// The cluster and user are stored with the context name, so that the contexts do not share them.
+func setKubeContext(current []byte, name string, source []byte, sourceName string) (npProvidersChange, error) {
+	config, err := clientcmd.Load(current)
+	if err != nil {
+		return npProvidersChange{}, fmt.Errorf("invalid kube.config in the providers secret: %w", err)
+	}
+	sourceConfig, err := clientcmd.Load(source)
+	if err != nil {
+		return npProvidersChange{}, fmt.Errorf("invalid source kubeconfig: %w", err)
+	}
+	if err := validateKubeContext(sourceConfig, sourceName); err != nil {
+		return npProvidersChange{}, err
+	}
+	change := npProvidersChange{Before: dumpRedacted(kubeContextEntry(config, name))}
+	if _, ok := config.Contexts[name]; ok {
+		removeKubeContext(config, name)
+	}
+	context := sourceConfig.Contexts[sourceName].DeepCopy()
+	config.Clusters[name] = sourceConfig.Clusters[context.Cluster].DeepCopy()
+	config.AuthInfos[name] = sourceConfig.AuthInfos[context.AuthInfo].DeepCopy()
+	context.Cluster = name
+	context.AuthInfo = name
+	config.Contexts[name] = context
+	change.After = dumpRedacted(kubeContextEntry(config, name))
+	change.Data, err = writeKubeConfig(config)
+	return change, err
+}
+
+func removeKubeContextEntry(current []byte, name string) (npProvidersChange, error) {
+	config, err := clientcmd.Load(current)
+	if err != nil {
+		return npProvidersChange{}, fmt.Errorf("invalid kube.config in the providers secret: %w", err)
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		return npProvidersChange{}, fmt.Errorf("the context %s is not defined in the providers secret", name)
+	}
+	change := npProvidersChange{Before: dumpRedacted(kubeContextEntry(config, name))}
+	removeKubeContext(config, name)
+	change.Data, err = writeKubeConfig(config)
+	return change, err
+}
+
+// writeNodepoolProvidersSecretOrDie sets a key of the providers secret, the key is removed when data is nil
+func writeNodepoolProvidersSecretOrDie(env *controllers.SFKubeContext, key string, data []byte) {
+	var secret apiv1.Secret
+	exists := env.GetOrDie(controllers.NodepoolProvidersSecretsName, &secret)
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	if data == nil {
+		delete(secret.Data, key)
+	} else {
+		secret.Data[key] = data
+	}
+	if exists {
+		env.UpdateROrDie(&secret)
+	} else {
+		secret.Name = controllers.NodepoolProvidersSecretsName
+		env.CreateROrDie(&secret)
+	}
+	ctrl.Log.Info("Secret \"" + controllers.NodepoolProvidersSecretsName + "\" updated, run the deploy command to restart the nodepool services")
+}
+
+func readNodepoolProvidersSecret(env *controllers.SFKubeContext) map[string][]byte {
+	var secret apiv1.Secret
+	env.GetOrDie(controllers.NodepoolProvidersSecretsName, &secret)
+	return secret.Data
+}
+
+// mkNodepoolProvidersChange computes the change of a set or remove command
+func mkNodepoolProvidersChange(kmd *cobra.Command, env *controllers.SFKubeContext, action string, kind string, name string) npProvidersChange {
+	current := readNodepoolProvidersSecret(env)[npProvidersKeys[kind]]
+	var (
+		change npProvidersChange
+		err    error
+	)
+	if action == "remove" {
+		if kind == "cloud" {
+			change, err = removeCloud(current, name)
+		} else {
+			change, err = removeKubeContextEntry(current, name)
+		}
+	} else {
+		sourcePath, _ := kmd.Flags().GetString("file")
+		sourceName, _ := kmd.Flags().GetString("source-name")
+		if sourceName == "" {
+			sourceName = name
+		}
+		source, readErr := os.ReadFile(sourcePath)
+		if readErr != nil {
+			ctrl.Log.Error(readErr, "Could not read the source file, set it with --file")
+			os.Exit(1)
+		}
+		if kind == "cloud" {
+			change, err = setCloud(current, name, source, sourceName)
+		} else {
+			change, err = setKubeContext(current, name, source, sourceName)
+		}
+	}
+	if err != nil {
+		ctrl.Log.Error(err, "Invalid provider "+kind+" "+name)
+		os.Exit(1)
+	}
+	return change
+}
+
+func printNodepoolProvidersDiff(kind string, name string, change npProvidersChange) bool {
+	if change.Before == change.After {
+		fmt.Printf("The %s %s is up to date\n", kind, name)
+		return false
+	}
+	fmt.Printf("--- %s %s (current)\n+++ %s %s (new)\n", kind, name, kind, name)
+	fmt.Print(cliutils.DiffLines(change.Before, change.After))
+	return true
+}
+
+func npProvidersGet(kmd *cobra.Command, args []string) {
+	env := cliutils.GetCLIContext(kmd)
+	data := readNodepoolProvidersSecret(env)
+	if len(args) == 0 {
+		names := controllers.GetNodepoolProvidersNames(data)
+		fmt.Println("clouds (clouds.yaml): " + strings.Join(names.Clouds, ", "))
+		fmt.Println("contexts (kube.config): " + strings.Join(names.Contexts, ", "))
+		fmt.Println("profiles (aws.config): " + strings.Join(names.Profiles, ", "))
+		return
+	}
+	if len(args) != 2 || !slices.Contains(npProvidersKinds, args[0]) {
+		ctrl.Log.Error(errors.New("invalid arguments"), "usage: nodepool providers get [{cloud, context} NAME]")
+		os.Exit(1)
+	}
+	var entry interface{}
+	if args[0] == "cloud" {
+		_, clouds, err := loadClouds(data["clouds.yaml"])
+		if err != nil {
+			ctrl.Log.Error(err, "Invalid clouds.yaml in the providers secret")
+			os.Exit(1)
+		}
+		entry = clouds[args[1]]
+	} else {
+		config, err := clientcmd.Load(data["kube.config"])
+		if err != nil {
+			ctrl.Log.Error(err, "Invalid kube.config in the providers secret")
+			os.Exit(1)
+		}
+		entry = kubeContextEntry(config, args[1])
+	}
+	if entry == nil {
+		ctrl.Log.Error(errors.New("not found"), "The "+args[0]+" "+args[1]+" is not defined in the providers secret")
+		os.Exit(1)
+	}
+	fmt.Print(dumpRedacted(entry))
+}
+
+func npProvidersSet(kmd *cobra.Command, args []string) {
+	env := cliutils.GetCLIContext(kmd)
+	change := mkNodepoolProvidersChange(kmd, env, "set", args[0], args[1])
+	if printNodepoolProvidersDiff(args[0], args[1], change) {
+		writeNodepoolProvidersSecretOrDie(env, npProvidersKeys[args[0]], change.Data)
+	}
+}
+
+func npProvidersDiff(kmd *cobra.Command, args []string) {
+	env := cliutils.GetCLIContext(kmd)
+	change := mkNodepoolProvidersChange(kmd, env, "set", args[0], args[1])
+	printNodepoolProvidersDiff(args[0], args[1], change)
+}
+
+func npProvidersRemove(kmd *cobra.Command, args []string) {
+	env := cliutils.GetCLIContext(kmd)
+	change := mkNodepoolProvidersChange(kmd, env, "remove", args[0], args[1])
+	printNodepoolProvidersDiff(args[0], args[1], change)
+	writeNodepoolProvidersSecretOrDie(env, npProvidersKeys[args[0]], change.Data)
+}
+
+func mkNodepoolProvidersCmd() *cobra.Command {
+	providersCmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Manage the clouds and kube contexts of the nodepool providers secret",
+	}
+	kindArgs := cobra.MatchAll(cobra.ExactArgs(2), func(cmd *cobra.Command, args []string) error {
+		if !slices.Contains(npProvidersKinds, args[0]) {
+			return fmt.Errorf("invalid kind %s, expected one of %s", args[0], strings.Join(npProvidersKinds, ", "))
+		}
+		return nil
+	})
+	addSourceFlags := func(cmd *cobra.Command) {
+		cmd.Flags().String("file", "", "the clouds.yaml (cloud) or the kubeconfig (context) holding the entry")
+		cmd.Flags().String("source-name", "", "the name of the entry in the file, defaults to NAME")
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get [{cloud, context} NAME]",
+		Short: "List the providers, or show a provider entry with its credentials redacted",
+		Run:   npProvidersGet,
+	}
+	setCmd := &cobra.Command{
+		Use:   "set {cloud, context} NAME",
+		Short: "Add or replace a provider entry, after showing the redacted diff",
+		Args:  kindArgs,
+		Run:   npProvidersSet,
+	}
+	addSourceFlags(setCmd)
+	diffCmd := &cobra.Command{
+		Use:   "diff {cloud, context} NAME",
+		Short: "Show the redacted diff of a provider entry without applying it",
+		Args:  kindArgs,
+		Run:   npProvidersDiff,
+	}
+	addSourceFlags(diffCmd)
+	removeCmd := &cobra.Command{
+		Use:   "remove {cloud, context} NAME",
+		Short: "Remove a provider entry",
+		Args:  kindArgs,
+		Run:   npProvidersRemove,
+	}
+	providersCmd.AddCommand(getCmd, setCmd, diffCmd, removeCmd)
+	return providersCmd
+}
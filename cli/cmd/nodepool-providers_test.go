@@ -0,0 +1,196 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	cliapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+)
+
+func TestValidateCloud(t *testing.T) {
+	tests := []struct {
+		name  string
+		cloud string
+		err   string
+	}{
+		{"password", `{auth: {auth_url: https://keystone, username: user, password: pass}}`, ""},
+		{"user id", `{auth_type: v3password, auth: {auth_url: https://keystone, user_id: id, password: pass}}`, ""},
+		{"profile", `{profile: vexxhost, auth: {username: user, password: pass}}`, ""},
+		{"missing password", `{auth: {auth_url: https://keystone, username: user}}`, "must define auth.username and auth.password"},
+		{"missing auth_url", `{auth: {username: user, password: pass}}`, "must define auth.auth_url"},
+		{"missing auth", `{region_name: RegionOne}`, "must define an auth mapping"},
+		{"not a mapping", `[]`, "must be a mapping"},
+		{"token", `{auth_type: v3token, auth: {auth_url: https://keystone, token: secret}}`, ""},
+		{"missing token", `{auth_type: token, auth: {auth_url: https://keystone}}`, "must define auth.token"},
+		{"application credential", `{auth_type: v3applicationcredential, auth: {auth_url: https://keystone, application_credential_id: id, application_credential_secret: secret}}`, ""},
+		{"application credential name", `{auth_type: v3applicationcredential, auth: {auth_url: https://keystone, application_credential_name: name, username: user, application_credential_secret: secret}}`, ""},
+		{"missing application credential secret", `{auth_type: v3applicationcredential, auth: {auth_url: https://keystone, application_credential_id: id}}`, "must define auth.application_credential_id and auth.application_credential_secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entry interface{}
+			if err := yaml.Unmarshal([]byte(tt.cloud), &entry); err != nil {
+				t.Fatal(err)
+			}
+			err := validateCloud("cloud", entry)
+			if tt.err == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("Expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestRedactEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    string
+		expected string
+	}{
+		{
+			"cloud",
+			`{auth: {auth_url: https://keystone, username: user, password: pass, application_credential_secret: secret}}`,
+			`{auth: {auth_url: https://keystone, username: user, password: <redacted>, application_credential_secret: <redacted>}}`,
+		},
+		{
+			"kube context",
+			`{users: [{name: user, user: {token: secret, client-key-data: key}}], clusters: [{name: c, cluster: {server: https://k8s, certificate-authority-data: ca}}]}`,
+			`{users: [{name: user, user: {token: <redacted>, client-key-data: <redacted>}}], clusters: [{name: c, cluster: {server: https://k8s, certificate-authority-data: <redacted>}}]}`,
+		},
+		{
+			"nested mapping with a sensitive key",
+			`{secrets: {name: value}}`,
+			`{secrets: {name: value}}`,
+		},
+		{"scalar", `value`, `value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entry, expected interface{}
+			if err := yaml.Unmarshal([]byte(tt.entry), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if redacted := redactEntry(entry); !reflect.DeepEqual(redacted, expected) {
+				t.Errorf("Unexpected redaction: %v", redacted)
+			}
+		})
+	}
+}
+
+func mkTestKubeConfig(contexts ...string) []byte {
+	config := cliapi.NewConfig()
+	for _, name := range contexts {
+		config.Clusters[name+"-cluster"] = &cliapi.Cluster{Server: "https://" + name + ".k8s:6443", CertificateAuthorityData: []byte("ca")}
+		config.AuthInfos[name+"-user"] = &cliapi.AuthInfo{Token: name + "-token"}
+		config.Contexts[name] = &cliapi.Context{Cluster: name + "-cluster", AuthInfo: name + "-user"}
+	}
+	if len(contexts) > 0 {
+		config.CurrentContext = contexts[0]
+	}
+	out, _ := clientcmd.Write(*config)
+	return out
+}
+
+func TestSetKubeContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    []byte
+		source     []byte
+		sourceName string
+		err        string
+		contexts   []string
+	}{
+		{"add to an empty kube.config", nil, mkTestKubeConfig("dev"), "dev", "", []string{"np"}},
+		{"add to a kube.config", mkTestKubeConfig("other"), mkTestKubeConfig("dev"), "dev", "", []string{"np", "other"}},
+		{"replace", mkTestKubeConfig("np"), mkTestKubeConfig("dev"), "dev", "", []string{"np"}},
+		{"unknown source context", nil, mkTestKubeConfig("dev"), "prod", "is not defined in the source kubeconfig", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, err := setKubeContext(tt.current, "np", tt.source, tt.sourceName)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			config, err := clientcmd.Load(change.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.contexts {
+				if _, ok := config.Contexts[name]; !ok {
+					t.Errorf("Missing context %s", name)
+				}
+			}
+			if len(config.Contexts) != len(tt.contexts) {
+				t.Errorf("Unexpected contexts: %v", config.Contexts)
+			}
+			// The cluster and user are stored under the context name
+			if config.Contexts["np"].Cluster != "np" || config.Clusters["np"].Server != "https://dev.k8s:6443" || config.AuthInfos["np"].Token != "dev-token" {
+				t.Errorf("Unexpected context: %v", config.Contexts["np"])
+			}
+			if strings.Contains(change.After, "dev-token") || !strings.Contains(change.After, redactedValue) {
+				t.Errorf("The change is not redacted: %s", change.After)
+			}
+		})
+	}
+}
+
+func TestRemoveKubeContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   func() *cliapi.Config
+		clusters int
+		users    int
+		current  string
+	}{
+		{
+			"remove the cluster and user",
+			func() *cliapi.Config {
+				config, _ := clientcmd.Load(mkTestKubeConfig("np", "other"))
+				return config
+			},
+			1, 1, "",
+		},
+		{
+			"keep the shared cluster and user",
+			func() *cliapi.Config {
+				config, _ := clientcmd.Load(mkTestKubeConfig("other"))
+				config.Contexts["np"] = &cliapi.Context{Cluster: "other-cluster", AuthInfo: "other-user"}
+				config.CurrentContext = "np"
+				return config
+			},
+			1, 1, "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config()
+			removeKubeContext(config, "np")
+			if _, ok := config.Contexts["np"]; ok {
+				t.Errorf("The context is not removed")
+			}
+			if len(config.Clusters) != tt.clusters || len(config.AuthInfos) != tt.users {
+				t.Errorf("Unexpected clusters %v or users %v", config.Clusters, config.AuthInfos)
+			}
+			if config.CurrentContext != tt.current {
+				t.Errorf("Unexpected current context %s", config.CurrentContext)
+			}
+		})
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"strings"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	cliapi "k8s.io/client-go/tools/clientcmd/api"
 
 	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
@@ -29,7 +31,7 @@ import (
 )
 
 var npGetAllowedArgs = []string{"builder-ssh-key"}
-var npCreateAllowedArgs = []string{"openshiftpods-namespace"}
+var npCreateAllowedArgs = []string{"openshiftpods-namespace", "kubernetes-namespace"}
 
 // openshiftpods namespace default values
 var (
@@ -38,8 +40,35 @@ var (
 	nodepoolRoleBinding    = "nodepool-rb"
 	nodepoolToken          = "nodepool-token"
 	nodepoolKubeContext    = "openshiftpods"
+	nodepoolKubeNames      = nodepoolKubeConfigNames{
+		Context: nodepoolKubeContext,
+		Cluster: "OpenshiftPodsCluster",
+		User:    nodepoolServiceAccount,
+	}
 )
 
+// kubernetes namespace default values
+var (
+	nodepoolClusterRole      = "nodepool-namespaces-role"
+	nodepoolNodesRole        = "nodepool-nodes-role"
+	nodepoolNodesRoleBinding = "nodepool-nodes-rb"
+	// The Role that the kubernetes driver creates in a namespace node and binds to its zuul-worker service account
+	nodepoolDriverRole        = "zuul"
+	nodepoolKubernetesContext = "kubernetes"
+	nodepoolKubernetesNames   = nodepoolKubeConfigNames{
+		Context: nodepoolKubernetesContext,
+		Cluster: "KubernetesCluster",
+		User:    nodepoolServiceAccount + "-kubernetes",
+	}
+)
+
+// nodepoolKubeConfigNames are the names of the context, cluster and user of a nodepool kubeconfig
+type nodepoolKubeConfigNames struct {
+	Context string
+	Cluster string
+	User    string
+}
+
 func npGet(kmd *cobra.Command, args []string) {
 	cliCtx := cliutils.GetCLIContext(kmd)
 	target := args[0]
@@ -64,6 +93,29 @@ func npCreate(kmd *cobra.Command, args []string) {
 			fmt.Println(configTemplate)
 		}
 	}
+	if args[0] == "kubernetes-namespace" {
+		nodepoolContext, _ := kmd.Flags().GetString("nodepool-context")
+		nodepoolNamespace, _ := kmd.Flags().GetString("nodepool-namespace")
+		showConfigTemplate, _ := kmd.Flags().GetBool("show-config-template")
+		skipProvidersSecrets, _ := kmd.Flags().GetBool("skip-providers-secrets")
+
+		CreateKubernetesNamespaceForNodepool(cliCtx, nodepoolContext, nodepoolNamespace, skipProvidersSecrets)
+		if showConfigTemplate {
+			configTemplate := mkNodepoolKubernetesConfigTemplate()
+			fmt.Println("Nodepool configuration template:")
+			fmt.Println(configTemplate)
+		}
+	}
+}
+
+func npLint(kmd *cobra.Command, args []string) {
+	cliCtx := cliutils.GetCLIContext(kmd)
+	configRepo, _ := kmd.Flags().GetString("config-repo")
+	preview, _ := kmd.Flags().GetBool("preview")
+	if err := cliCtx.LintNodepoolConfig(configRepo, preview); err != nil {
+		ctrl.Log.Error(err, "The nodepool configuration of "+configRepo+" is not valid")
+		os.Exit(1)
+	}
 }
 
 func CreateNamespaceForNodepool(sfEnv *controllers.SFKubeContext, nodepoolContext, nodepoolNamespace string, skipProvidersSecrets bool) {
@@ -77,7 +129,7 @@ func CreateNamespaceForNodepool(sfEnv *controllers.SFKubeContext, nodepoolContex
 	npEnv.EnsureServiceAccountOrDie(nodepoolServiceAccount)
 	ensureNodepoolRole(&npEnv)
 	token := ensureNodepoolServiceAccountSecret(&npEnv)
-	npKubeConfig := createNodepoolKubeConfigOrDie(nodepoolContext, nodepoolNamespace, token)
+	npKubeConfig := createNodepoolKubeConfigOrDie(nodepoolContext, nodepoolNamespace, token, nodepoolKubeNames)
 	kconfig, err := clientcmd.Write(npKubeConfig)
 
 	if err != nil {
@@ -92,6 +144,62 @@ func CreateNamespaceForNodepool(sfEnv *controllers.SFKubeContext, nodepoolContex
 
 }
 
+// CreateKubernetesNamespaceForNodepool sets up a service account allowed to create the namespaces of the nodes
+// spawned by nodepool's kubernetes driver, and merges its kubeconfig context into the providers secrets
+func CreateKubernetesNamespaceForNodepool(sfEnv *controllers.SFKubeContext, nodepoolContext, nodepoolNamespace string, skipProvidersSecrets bool) {
+	npEnv, err := controllers.MkSFKubeContext("", nodepoolNamespace, nodepoolContext, false)
+	if err != nil {
+		logging.LogE(err, "Could not create nodepool kube client")
+		os.Exit(1)
+	}
+
+	npEnv.EnsureNamespaceOrDie(nodepoolNamespace)
+	npEnv.EnsureServiceAccountOrDie(nodepoolServiceAccount)
+	ensureNodepoolClusterRole(&npEnv, nodepoolClusterRole, nodepoolNamespacesRules, nodepoolNamespace)
+	ensureNodepoolClusterRole(&npEnv, nodepoolNodesRole, nodepoolNodesRules, nodepoolNamespace)
+	token := ensureNodepoolServiceAccountSecret(&npEnv)
+	npKubeConfig := createNodepoolKubeConfigOrDie(nodepoolContext, nodepoolNamespace, token, nodepoolKubernetesNames)
+
+	if skipProvidersSecrets {
+		kconfig, err := clientcmd.Write(npKubeConfig)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not serialize nodepool's kubeconfig")
+			os.Exit(1)
+		}
+		fmt.Println("Provider kubeconfig:")
+		fmt.Println(string(kconfig))
+	} else {
+		var secret apiv1.Secret
+		var current []byte
+		if sfEnv.GetOrDie(controllers.NodepoolProvidersSecretsName, &secret) {
+			current = secret.Data["kube.config"]
+		}
+		ensureNodepoolKubeProvidersSecrets(sfEnv, mergeNodepoolKubeConfigOrDie(current, npKubeConfig))
+	}
+}
+
+// mergeNodepoolKubeConfigOrDie adds the clusters, contexts and users of the nodepool kubeconfig to the current
+// kube.config of the providers secrets. The current context is kept.
+func mergeNodepoolKubeConfigOrDie(current []byte, npKubeConfig cliapi.Config) []byte {
+	merged, err := clientcmd.Load(current)
+	if err != nil {
+		ctrl.Log.Error(err, "Could not read the kube.config of the providers secrets")
+		os.Exit(1)
+	}
+	maps.Copy(merged.Clusters, npKubeConfig.Clusters)
+	maps.Copy(merged.Contexts, npKubeConfig.Contexts)
+	maps.Copy(merged.AuthInfos, npKubeConfig.AuthInfos)
+	if merged.CurrentContext == "" {
+		merged.CurrentContext = npKubeConfig.CurrentContext
+	}
+	kconfig, err := clientcmd.Write(*merged)
+	if err != nil {
+		ctrl.Log.Error(err, "Could not serialize nodepool's kubeconfig")
+		os.Exit(1)
+	}
+	return kconfig
+}
+
 func ensureNodepoolKubeProvidersSecrets(env *controllers.SFKubeContext, kubeconfig []byte) {
 
 	var secret apiv1.Secret
@@ -182,6 +290,80 @@ func ensureNodepoolRole(env *controllers.SFKubeContext) {
 	}
 }
 
+// nodepoolNamespacesRules allow the nodepool service account to create and delete the nodes namespaces,
+// and to list them to clean up the leaked ones
+var nodepoolNamespacesRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"namespaces"},
+		Verbs:     []string{"create", "delete", "get", "list"},
+	},
+}
+
+// nodepoolDriverRoleRules are the rules of the Role that the kubernetes driver creates in a namespace node
+var nodepoolDriverRoleRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "pods/exec", "pods/log", "pods/portforward", "services", "endpoints", "crontabs", "jobs", "deployments", "replicasets", "configmaps", "secrets"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	},
+}
+
+// nodepoolNodesRules allow the nodepool service account to populate a nodes namespace: the pod, the zuul-worker
+// service account and its token, and the Role bound to it. The rights of the Role must be held to create it,
+// since the escalate verb is not granted, and only this Role can be bound.
+var nodepoolNodesRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: append([]string{"serviceaccounts"}, nodepoolDriverRoleRules[0].Resources...),
+		Verbs:     nodepoolDriverRoleRules[0].Verbs,
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"roles", "rolebindings"},
+		Verbs:     []string{"create", "delete", "get", "list"},
+	},
+	{
+		APIGroups:     []string{"rbac.authorization.k8s.io"},
+		Resources:     []string{"roles"},
+		ResourceNames: []string{nodepoolDriverRole},
+		Verbs:         []string{"bind"},
+	},
+}
+
+// ensureNodepoolClusterRole grants a ClusterRole to the nodepool service account in every namespace. The nodes
+// namespaces are created by the driver for each node, thus their rights cannot be granted per namespace.
+func ensureNodepoolClusterRole(env *controllers.SFKubeContext, name string, rules []rbacv1.PolicyRule, nodepoolNamespace string) {
+	var clusterRole rbacv1.ClusterRole
+	var clusterRoleBinding rbacv1.ClusterRoleBinding
+
+	if !env.GetOrDie(name, &clusterRole) {
+		clusterRole.Name = name
+		clusterRole.Rules = rules
+		env.CreateROrDie(&clusterRole)
+	} else if !equality.Semantic.DeepEqual(clusterRole.Rules, rules) {
+		clusterRole.Rules = rules
+		env.UpdateROrDie(&clusterRole)
+	}
+
+	// The binding is cluster-scoped, its name is unique to the service account namespace
+	bindingName := name + "-" + nodepoolNamespace
+	if !env.GetOrDie(bindingName, &clusterRoleBinding) {
+		clusterRoleBinding.Name = bindingName
+		clusterRoleBinding.Subjects = []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      nodepoolServiceAccount,
+				Namespace: nodepoolNamespace,
+			},
+		}
+		clusterRoleBinding.RoleRef.Kind = "ClusterRole"
+		clusterRoleBinding.RoleRef.Name = name
+		clusterRoleBinding.RoleRef.APIGroup = "rbac.authorization.k8s.io"
+		env.CreateROrDie(&clusterRoleBinding)
+	}
+}
+
 func ensureNodepoolServiceAccountSecret(env *controllers.SFKubeContext) string {
 	var secret apiv1.Secret
 	if !env.GetOrDie(nodepoolToken, &secret) {
@@ -208,7 +390,7 @@ func ensureNodepoolServiceAccountSecret(env *controllers.SFKubeContext) string {
 	return string(token)
 }
 
-func createNodepoolKubeConfigOrDie(contextName string, ns string, token string) cliapi.Config {
+func createNodepoolKubeConfigOrDie(contextName string, ns string, token string, names nodepoolKubeConfigNames) cliapi.Config {
 	ctx, err := controllers.MkSFKubeContext("", ns, contextName, false)
 	if err != nil {
 		logging.LogE(err, "Could not build kube context")
@@ -226,21 +408,21 @@ func createNodepoolKubeConfigOrDie(contextName string, ns string, token string)
 		Kind:       "Config",
 		APIVersion: "v1",
 		Clusters: map[string]*cliapi.Cluster{
-			"OpenshiftPodsCluster": {
+			names.Cluster: {
 				Server:                   currentConfig.Host + currentConfig.APIPath,
 				CertificateAuthorityData: currentConfig.TLSClientConfig.CAData,
 			},
 		},
 		Contexts: map[string]*cliapi.Context{
-			nodepoolKubeContext: {
-				Cluster:   "OpenshiftPodsCluster",
+			names.Context: {
+				Cluster:   names.Cluster,
 				Namespace: ns,
-				AuthInfo:  nodepoolServiceAccount,
+				AuthInfo:  names.User,
 			},
 		},
-		CurrentContext: nodepoolKubeContext,
+		CurrentContext: names.Context,
 		AuthInfos: map[string]*cliapi.AuthInfo{
-			nodepoolServiceAccount: {
+			names.User: {
 				Token: token,
 			},
 		},
@@ -294,6 +476,69 @@ func mkNodepoolOpenshiftPodsConfigTemplate(nodepoolNamespace string) string {
 	return string(templateYaml)
 }
 
+func mkNodepoolKubernetesConfigTemplate() string {
+
+	type Label struct {
+		Name        string `json:"name"`
+		Type        string `json:"type"`
+		Image       string `json:"image,omitempty"`
+		CPU         int    `json:"cpu,omitempty"`
+		Memory      int    `json:"memory,omitempty"`
+		CPULimit    int    `json:"cpu-limit,omitempty"`
+		MemoryLimit int    `json:"memory-limit,omitempty"`
+	}
+	type Pool struct {
+		Name    string  `json:"name"`
+		MaxPods int     `json:"max-pods"`
+		Labels  []Label `json:"labels"`
+	}
+	type Provider struct {
+		Name    string `json:"name"`
+		Driver  string `json:"driver"`
+		Context string `json:"context"`
+		Pools   []Pool `json:"pools"`
+	}
+	type ProvidersConfig struct {
+		Providers []Provider `json:"providers"`
+	}
+	templateConfig := ProvidersConfig{
+		Providers: []Provider{
+			{
+				Name:    "kubernetes",
+				Driver:  "kubernetes",
+				Context: nodepoolKubernetesContext,
+				Pools: []Pool{
+					{
+						Name:    "main",
+						MaxPods: 10,
+						Labels: []Label{
+							{
+								Name:        "kubernetes-fedora-latest",
+								Type:        "pod",
+								Image:       "quay.io/fedora/fedora:latest",
+								CPU:         1,
+								Memory:      1024,
+								CPULimit:    2,
+								MemoryLimit: 2048,
+							},
+							{
+								Name: "kubernetes-namespace",
+								Type: "namespace",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	templateYaml, err := yaml.Marshal(templateConfig)
+	if err != nil {
+		ctrl.Log.Error(err, "Could not serialize sample provider configuration")
+		os.Exit(1)
+	}
+	return string(templateYaml)
+}
+
 func MkNodepoolCmd() *cobra.Command {
 
 	var (
@@ -302,6 +547,8 @@ func MkNodepoolCmd() *cobra.Command {
 		nodepoolNamespace    string
 		showConfigTemplate   bool
 		skipProvidersSecrets bool
+		configRepo           string
+		preview              bool
 
 		nodepoolCmd = &cobra.Command{
 			Use:   "nodepool",
@@ -318,15 +565,29 @@ func MkNodepoolCmd() *cobra.Command {
 	getCmd.Flags().StringVar(&builderPubKey, "pubkey", "", "(use with builder-ssh-key) File where to dump nodepool-builder's SSH public key")
 
 	createCmd.Run = npCreate
-	createCmd.Use = "create {openshiftpods-namespace}"
-	createCmd.Long = "Create a nodepool resource. The resource can be: a namespace that can be used with the \"openshiftpods\" provider."
+	createCmd.Use = "create {" + strings.Join(npCreateAllowedArgs, ", ") + "}"
+	createCmd.Long = "Create a nodepool resource. The resource can be: a namespace that can be used with the \"openshiftpods\" provider, " +
+		"or a namespace holding a service account allowed to create the nodes namespaces of the \"kubernetes\" provider."
 	createCmd.ValidArgs = npCreateAllowedArgs
-	createCmd.Flags().StringVar(&nodepoolContext, "nodepool-context", "", "(openshiftpods-namespace) the kube context nodepool will use to configure the namespace")
-	createCmd.Flags().StringVar(&nodepoolNamespace, "nodepool-namespace", "nodepool", "(openshiftpods-namespace) the name of the namespace to create")
-	createCmd.Flags().BoolVar(&showConfigTemplate, "show-config-template", false, "(openshiftpods-namespace) display a YAML snippet that can be used to configure an \"openshiftpods\" provider with nodepool")
-	createCmd.Flags().BoolVar(&skipProvidersSecrets, "skip-providers-secrets", false, "openshiftpods-namespace) do not update providers secrets, and instead display the nodepool kube config on stdout")
+	createCmd.Flags().StringVar(&nodepoolContext, "nodepool-context", "", "(openshiftpods-namespace, kubernetes-namespace) the kube context nodepool will use to configure the namespace")
+	createCmd.Flags().StringVar(&nodepoolNamespace, "nodepool-namespace", "nodepool", "(openshiftpods-namespace, kubernetes-namespace) the name of the namespace to create")
+	createCmd.Flags().BoolVar(&showConfigTemplate, "show-config-template", false, "(openshiftpods-namespace, kubernetes-namespace) display a YAML snippet that can be used to configure an \"openshiftpods\" or \"kubernetes\" provider with nodepool")
+	createCmd.Flags().BoolVar(&skipProvidersSecrets, "skip-providers-secrets", false, "(openshiftpods-namespace, kubernetes-namespace) do not update providers secrets, and instead display the nodepool kube config on stdout")
+
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Lint the nodepool configuration of a config repository",
+		Long: "Render the nodepool configuration of a local copy of the config repository as a config-update would, then validate it. " +
+			"The providers are checked against the providers secret, and the labels of the Zuul nodesets against the nodepool labels.",
+		Run: npLint,
+	}
+	lintCmd.Flags().StringVar(&configRepo, "config-repo", ".", "the path to the local copy of the config repository")
+	lintCmd.Flags().BoolVar(&preview, "preview", false, "display the rendered nodepool configuration")
 
 	nodepoolCmd.AddCommand(createCmd)
 	nodepoolCmd.AddCommand(getCmd)
+	nodepoolCmd.AddCommand(lintCmd)
+	nodepoolCmd.AddCommand(mkNodepoolProvidersCmd())
+	nodepoolCmd.AddCommand(mkNodepoolImagesCmd())
 	return nodepoolCmd
 }
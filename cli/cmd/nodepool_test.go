@@ -0,0 +1,73 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// ruleAllows tells whether the rules allow a request, the rules of this package do not use wildcards
+func ruleAllows(rules []rbacv1.PolicyRule, group string, resource string, verb string, name string) bool {
+	for _, rule := range rules {
+		if slices.Contains(rule.APIGroups, group) && slices.Contains(rule.Resources, resource) &&
+			slices.Contains(rule.Verbs, verb) && (len(rule.ResourceNames) == 0 || slices.Contains(rule.ResourceNames, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNodepoolKubernetesRules(t *testing.T) {
+	// The requests of the kubernetes driver to populate a node namespace
+	requests := []struct {
+		group    string
+		resource string
+		verb     string
+		name     string
+	}{
+		{"", "serviceaccounts", "create", "zuul-worker"},
+		{"", "secrets", "create", "zuul-worker"},
+		{"", "secrets", "get", "zuul-worker"},
+		{"rbac.authorization.k8s.io", "roles", "create", nodepoolDriverRole},
+		{"rbac.authorization.k8s.io", "rolebindings", "create", "zuul-role"},
+		{"rbac.authorization.k8s.io", "roles", "bind", nodepoolDriverRole},
+		{"", "pods", "create", "node"},
+		{"", "pods", "get", "node"},
+		{"", "pods", "delete", "node"},
+	}
+	for _, request := range requests {
+		if !ruleAllows(nodepoolNodesRules, request.group, request.resource, request.verb, request.name) {
+			t.Errorf("The nodes rules do not allow to %s the %s %s", request.verb, request.resource, request.name)
+		}
+	}
+	// Creating the driver Role requires to hold its rights, since escalate is not granted
+	for _, rule := range nodepoolDriverRoleRules {
+		for _, resource := range rule.Resources {
+			for _, verb := range rule.Verbs {
+				if !ruleAllows(nodepoolNodesRules, rule.APIGroups[0], resource, verb, "") {
+					t.Errorf("The nodes rules do not hold %s on %s", verb, resource)
+				}
+			}
+		}
+	}
+
+	all := append(slices.Clone(nodepoolNodesRules), nodepoolNamespacesRules...)
+	for _, forbidden := range []struct {
+		resource string
+		verb     string
+		name     string
+	}{
+		{"roles", "escalate", nodepoolDriverRole},
+		{"roles", "bind", "admin"},
+		{"clusterroles", "bind", "cluster-admin"},
+		{"clusterrolebindings", "create", ""},
+	} {
+		if ruleAllows(all, "rbac.authorization.k8s.io", forbidden.resource, forbidden.verb, forbidden.name) {
+			t.Errorf("The rules allow to %s the %s %s", forbidden.verb, forbidden.resource, forbidden.name)
+		}
+	}
+}
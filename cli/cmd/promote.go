@@ -0,0 +1,55 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+/*
+"promote-database" subcommand promotes a MariaDB replica as the new primary.
+*/
+
+import (
+	"errors"
+	"os"
+
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func promoteDatabaseCmd(kmd *cobra.Command, args []string) {
+	env := cliutils.GetCLIContext(kmd)
+
+	if env.Ns == "" {
+		ctrl.Log.Error(errors.New("no namespace set"), "You need to specify the namespace!")
+		os.Exit(1)
+	}
+
+	if err := env.PromoteMariaDB(args[0]); err != nil {
+		ctrl.Log.Error(err, "Couldn't promote "+args[0])
+		os.Exit(1)
+	}
+	ctrl.Log.Info("Run a deploy to re-seed the replicas that could not follow the new primary")
+}
+
+func MkPromoteDatabaseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "promote-database REPLICA_POD",
+		Short: "Promote a MariaDB replica as the new primary",
+		Long:  `This command stops the writes on the current primary, waits for the replica to catch up, then promotes it`,
+		Args:  cobra.ExactArgs(1),
+		Run:   promoteDatabaseCmd,
+	}
+}
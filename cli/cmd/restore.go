@@ -23,6 +23,7 @@ package cmd
 import (
 	"errors"
 	"os"
+	"time"
 
 	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
 
@@ -49,6 +50,15 @@ func restoreCmd(kmd *cobra.Command, args []string) {
 
 	}
 
+	var toTime time.Time
+	if toTimeStr, _ := kmd.Flags().GetString("to-time"); toTimeStr != "" {
+		var err error
+		if toTime, err = time.Parse(time.RFC3339, toTimeStr); err != nil {
+			ctrl.Log.Error(err, "The '--to-time' parameter must be a RFC3339 date, such as 2026-01-02T15:04:05Z")
+			os.Exit(1)
+		}
+	}
+
 	env, cr := cliutils.GetCLICRContext(kmd, args)
 
 	if env.Ns == "" {
@@ -63,7 +73,7 @@ func restoreCmd(kmd *cobra.Command, args []string) {
 
 	env.EnsureStandaloneOwner(cr.Spec)
 
-	if err := env.DoRestore(backupDir, cr); err != nil {
+	if err := env.DoRestore(backupDir, cr, toTime); err != nil {
 		os.Exit(1)
 	}
 }
@@ -72,6 +82,7 @@ func MkRestoreCmd() *cobra.Command {
 
 	var (
 		backupDir  string
+		toTime     string
 		restoreCmd = &cobra.Command{
 			Use:   "restore",
 			Short: "Restore a deployment to a previous backup",
@@ -79,6 +90,7 @@ func MkRestoreCmd() *cobra.Command {
 		}
 	)
 	restoreCmd.Flags().StringVar(&backupDir, "backup_dir", "", "The path to the dir where backup is located")
+	restoreCmd.Flags().StringVar(&toTime, "to-time", "", "Replay the archived MariaDB binary logs up to this RFC3339 date")
 
 	return restoreCmd
 }
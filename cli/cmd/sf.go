@@ -35,7 +35,10 @@ func MkSFCmd() *cobra.Command {
 
 	sfCmd.AddCommand(MkBackupCmd())
 	sfCmd.AddCommand(MkRestoreCmd())
+	sfCmd.AddCommand(MkPromoteDatabaseCmd())
 	sfCmd.AddCommand(bootstraptenantconfigrepo.MkBootstrapCmd())
+	sfCmd.AddCommand(MkExportCmd())
+	sfCmd.AddCommand(MkDiffCmd())
 
 	return sfCmd
 }
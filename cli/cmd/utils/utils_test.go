@@ -0,0 +1,29 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import "testing"
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", "  a\n  b\n"},
+		{"added", "", "a\nb\n", "+ a\n+ b\n"},
+		{"removed", "a\nb\n", "", "- a\n- b\n"},
+		{"both empty", "", "", ""},
+		{"changed", "a\nb\nc\n", "a\nB\nc\n", "  a\n- b\n+ B\n  c\n"},
+		{"inserted", "a\nc", "a\nb\nc", "  a\n+ b\n  c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := DiffLines(tt.before, tt.after); diff != tt.expected {
+				t.Errorf("Unexpected diff:\n%s", diff)
+			}
+		})
+	}
+}
@@ -83,5 +83,7 @@ func MkZuulCmd() *cobra.Command {
 	createCmd.Flags().BoolVar(&insecure, "insecure", false, "do not verify SSL certificates when connection to Zuul")
 
 	zuulCmd.AddCommand(createCmd)
+	zuulCmd.AddCommand(mkZuulOpsCmds()...)
+	zuulCmd.AddCommand(mkZuulLintCmd())
 	return zuulCmd
 }
@@ -0,0 +1,319 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zuul
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// zuul-web REST API client.
+
+// ZuulAPI performs calls against the tenant scoped endpoints of zuul-web, through the gateway.
+type ZuulAPI struct {
+	URL    string
+	Tenant string
+	// Token is the bearer token used for the admin endpoints, as returned by zuul-admin create-auth-token.
+	Token  string
+	client *http.Client
+}
+
+type ZuulAPIAutoholdNodes struct {
+	Build string   `json:"build"`
+	Nodes []string `json:"nodes"`
+}
+
+type ZuulAPIAutohold struct {
+	ID             string                 `json:"id"`
+	Tenant         string                 `json:"tenant"`
+	Project        string                 `json:"project"`
+	Job            string                 `json:"job"`
+	RefFilter      string                 `json:"ref_filter"`
+	MaxCount       int                    `json:"max_count"`
+	CurrentCount   int                    `json:"current_count"`
+	NodeExpiration int                    `json:"node_expiration"`
+	Reason         string                 `json:"reason"`
+	Nodes          []ZuulAPIAutoholdNodes `json:"nodes"`
+}
+
+type ZuulAPIAutoholdRequest struct {
+	Change             string `json:"change,omitempty"`
+	Ref                string `json:"ref,omitempty"`
+	Reason             string `json:"reason"`
+	Count              int    `json:"count"`
+	Job                string `json:"job"`
+	NodeHoldExpiration int    `json:"node_hold_expiration,omitempty"`
+}
+
+type ZuulAPIChangeRequest struct {
+	Pipeline string `json:"pipeline"`
+	Change   string `json:"change,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+	OldRev   string `json:"oldrev,omitempty"`
+	NewRev   string `json:"newrev,omitempty"`
+}
+
+type ZuulAPIPromoteRequest struct {
+	Pipeline string   `json:"pipeline"`
+	Changes  []string `json:"changes"`
+}
+
+type ZuulAPIStatusJob struct {
+	Name          string  `json:"name"`
+	Result        *string `json:"result"`
+	WaitingStatus *string `json:"waiting_status"`
+	Voting        bool    `json:"voting"`
+}
+
+type ZuulAPIStatusRef struct {
+	ID      string `json:"id"`
+	Project string `json:"project"`
+	Ref     string `json:"ref"`
+}
+
+type ZuulAPIStatusItem struct {
+	ID          string             `json:"id"`
+	Project     string             `json:"project"`
+	Live        bool               `json:"live"`
+	EnqueueTime int64              `json:"enqueue_time"`
+	Refs        []ZuulAPIStatusRef `json:"refs"`
+	Jobs        []ZuulAPIStatusJob `json:"jobs"`
+}
+
+type ZuulAPIStatusQueue struct {
+	Name  string                `json:"name"`
+	Heads [][]ZuulAPIStatusItem `json:"heads"`
+}
+
+type ZuulAPIStatusPipeline struct {
+	Name         string               `json:"name"`
+	ChangeQueues []ZuulAPIStatusQueue `json:"change_queues"`
+}
+
+type ZuulAPIStatus struct {
+	ZuulVersion string                  `json:"zuul_version"`
+	Pipelines   []ZuulAPIStatusPipeline `json:"pipelines"`
+}
+
+type ZuulAPIBuildRef struct {
+	Project  string `json:"project"`
+	Change   *int   `json:"change"`
+	Patchset string `json:"patchset"`
+	Branch   string `json:"branch"`
+	Ref      string `json:"ref"`
+}
+
+type ZuulAPIBuild struct {
+	UUID      string          `json:"uuid"`
+	JobName   string          `json:"job_name"`
+	Result    string          `json:"result"`
+	StartTime string          `json:"start_time"`
+	Duration  float64         `json:"duration"`
+	Pipeline  string          `json:"pipeline"`
+	LogURL    string          `json:"log_url"`
+	Ref       ZuulAPIBuildRef `json:"ref"`
+}
+
+type NodepoolAPINodeRequest struct {
+	ID        string   `json:"id"`
+	State     string   `json:"state"`
+	Requestor string   `json:"requestor"`
+	NodeTypes []string `json:"node_types"`
+	Nodes     []string `json:"nodes"`
+	EventID   string   `json:"event_id"`
+}
+
+type NodepoolAPINode struct {
+	ID             string   `json:"id"`
+	Provider       string   `json:"provider"`
+	Label          []string `json:"label"`
+	State          string   `json:"state"`
+	Hostname       string   `json:"hostname"`
+	PublicIPv4     string   `json:"public_ipv4"`
+	PrivateIPv4    string   `json:"private_ipv4"`
+	IPv6           string   `json:"ipv6"`
+	Username       string   `json:"username"`
+	ConnectionPort int      `json:"connection_port"`
+	ConnectionType string   `json:"connection_type"`
+	HoldJob        string   `json:"hold_job"`
+	Comment        string   `json:"comment"`
+}
+
+func MkZuulAPI(fqdn string, tenant string, token string, verify bool) ZuulAPI {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: !verify,
+		},
+	}
+	return ZuulAPI{
+		URL:    "https://" + fqdn,
+		Tenant: tenant,
+		Token:  strings.TrimPrefix(strings.TrimSpace(token), "Bearer "),
+		client: &http.Client{Transport: tr},
+	}
+}
+
+// call performs a request on the gateway and decodes the JSON response in result when it is not nil.
+func (z *ZuulAPI) call(method string, path string, payload any, result any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, z.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if z.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+z.Token)
+	}
+	resp, err := z.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s %s returned status %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (z *ZuulAPI) tenantPath(path string) string {
+	return "/zuul/api/tenant/" + url.PathEscape(z.Tenant) + path
+}
+
+// projectPath escapes each segment of the project name: the gateway rejects the encoded slashes, so the
+// slashes of a namespaced project such as org/repo are kept.
+func (z *ZuulAPI) projectPath(project string, path string) string {
+	segments := strings.Split(project, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return z.tenantPath("/project/" + strings.Join(segments, "/") + path)
+}
+
+func (z *ZuulAPI) Enqueue(project string, req ZuulAPIChangeRequest) error {
+	return z.call(http.MethodPost, z.projectPath(project, "/enqueue"), req, nil)
+}
+
+func (z *ZuulAPI) Dequeue(project string, req ZuulAPIChangeRequest) error {
+	return z.call(http.MethodPost, z.projectPath(project, "/dequeue"), req, nil)
+}
+
+func (z *ZuulAPI) Promote(req ZuulAPIPromoteRequest) error {
+	return z.call(http.MethodPost, z.tenantPath("/promote"), req, nil)
+}
+
+func (z *ZuulAPI) ListAutoholds() ([]ZuulAPIAutohold, error) {
+	autoholds := []ZuulAPIAutohold{}
+	err := z.call(http.MethodGet, z.tenantPath("/autohold"), nil, &autoholds)
+	return autoholds, err
+}
+
+func (z *ZuulAPI) GetAutohold(id string) (ZuulAPIAutohold, error) {
+	var autohold ZuulAPIAutohold
+	err := z.call(http.MethodGet, z.tenantPath("/autohold/"+url.PathEscape(id)), nil, &autohold)
+	return autohold, err
+}
+
+func (z *ZuulAPI) CreateAutohold(project string, req ZuulAPIAutoholdRequest) error {
+	return z.call(http.MethodPost, z.projectPath(project, "/autohold"), req, nil)
+}
+
+func (z *ZuulAPI) DeleteAutohold(id string) error {
+	return z.call(http.MethodDelete, z.tenantPath("/autohold/"+url.PathEscape(id)), nil, nil)
+}
+
+func (z *ZuulAPI) GetStatus() (ZuulAPIStatus, error) {
+	var status ZuulAPIStatus
+	err := z.call(http.MethodGet, z.tenantPath("/status"), nil, &status)
+	return status, err
+}
+
+// GetBuilds returns the builds matching the filters, see zuul-web's builds endpoint for the supported keys.
+func (z *ZuulAPI) GetBuilds(filters map[string]string, limit int) ([]ZuulAPIBuild, error) {
+	query := url.Values{}
+	for k, v := range filters {
+		if v != "" {
+			query.Set(k, v)
+		}
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	builds := []ZuulAPIBuild{}
+	err := z.call(http.MethodGet, z.tenantPath("/builds?"+query.Encode()), nil, &builds)
+	return builds, err
+}
+
+// GetNodeRequests returns the pending node requests from the nodepool-launcher webapp.
+func (z *ZuulAPI) GetNodeRequests() ([]NodepoolAPINodeRequest, error) {
+	requests := []NodepoolAPINodeRequest{}
+	err := z.call(http.MethodGet, "/nodepool/api/request-list", nil, &requests)
+	return requests, err
+}
+
+// GetNodepoolNode returns a node from the nodepool-launcher webapp.
+func (z *ZuulAPI) GetNodepoolNode(id string) (NodepoolAPINode, error) {
+	nodes := []NodepoolAPINode{}
+	if err := z.call(http.MethodGet, "/nodepool/api/node-list?node_id="+url.QueryEscape(id), nil, &nodes); err != nil {
+		return NodepoolAPINode{}, err
+	}
+	for _, node := range nodes {
+		if node.ID == id {
+			return node, nil
+		}
+	}
+	return NodepoolAPINode{}, fmt.Errorf("node %s not found", id)
+}
+
+// GetID returns the change or ref identifier of a queue item.
+func (item ZuulAPIStatusItem) GetID() string {
+	if item.ID == "" && len(item.Refs) > 0 {
+		return item.Refs[0].ID
+	}
+	return item.ID
+}
+
+// GetProject returns the project name of a queue item.
+func (item ZuulAPIStatusItem) GetProject() string {
+	if item.Project == "" && len(item.Refs) > 0 {
+		return item.Refs[0].Project
+	}
+	return item.Project
+}
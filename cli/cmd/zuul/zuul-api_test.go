@@ -0,0 +1,58 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zuul
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestZuulAPIProjectPath(t *testing.T) {
+	paths := []string{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+	}))
+	defer server.Close()
+	api := MkZuulAPI(strings.TrimPrefix(server.URL, "https://"), "local", "token", false)
+
+	for _, tc := range []struct {
+		project  string
+		expected string
+	}{
+		{"config", "/zuul/api/tenant/local/project/config/enqueue"},
+		{"org/repo", "/zuul/api/tenant/local/project/org/repo/enqueue"},
+		{"gerrit.example.com/org/my repo", "/zuul/api/tenant/local/project/gerrit.example.com/org/my%20repo/enqueue"},
+	} {
+		paths = paths[:0]
+		if err := api.Enqueue(tc.project, ZuulAPIChangeRequest{Pipeline: "check", Change: "1,1"}); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(paths) != 1 || paths[0] != tc.expected {
+			t.Errorf("Unexpected path for %s: %v", tc.project, paths)
+		}
+	}
+
+	paths = paths[:0]
+	if err := api.CreateAutohold("org/repo", ZuulAPIAutoholdRequest{Job: "unit", Count: 1}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(paths) != 1 || paths[0] != "/zuul/api/tenant/local/project/org/repo/autohold" {
+		t.Errorf("Unexpected autohold path: %v", paths)
+	}
+}
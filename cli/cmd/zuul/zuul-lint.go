@@ -0,0 +1,78 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zuul
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	"github.com/softwarefactory-project/sf-operator/controllers"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/zuulcf"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Offline validation of the Zuul configuration of a config repository.
+
+func zuulLint(kmd *cobra.Command, args []string) {
+	cliutils.SetLogger(kmd)
+	crPath, _ := kmd.Flags().GetString("cr")
+
+	var connections []string
+	if crPath != "" {
+		sf, err := controllers.ReadSFYAML(crPath)
+		if err != nil {
+			ctrl.Log.Error(err, "Could not read the SoftwareFactory resource "+crPath)
+			os.Exit(1)
+		}
+		if connections, err = controllers.GetZuulConnections(&sf.Spec.Zuul); err != nil {
+			ctrl.Log.Error(err, "Invalid Zuul connections in "+crPath)
+			os.Exit(1)
+		}
+	} else {
+		ctrl.Log.Info("No SoftwareFactory resource provided with --cr, the connections are not checked")
+	}
+
+	items, errs := zuulcf.LoadConfigRepo(args[0])
+	errs = append(errs, zuulcf.Lint(items, connections)...)
+	zuulcf.SortErrors(errs)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	if len(errs) > 0 {
+		ctrl.Log.Error(errors.New(strconv.Itoa(len(errs))+" error(s)"), "The Zuul configuration of "+args[0]+" is not valid")
+		os.Exit(1)
+	}
+	ctrl.Log.Info("The Zuul configuration of " + args[0] + " is valid (" + strconv.Itoa(len(items)) + " items)")
+}
+
+func mkZuulLintCmd() *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:   "lint CONFIG_REPO",
+		Short: "Check the Zuul configuration of a config repository, without a deployment",
+		Long: "Parse zuul/main.yaml and the zuul.d/ files of a local copy of a config repository, and report the unknown keys, " +
+			"the duplicated definitions, the nodesets and semaphores used by the jobs that are not defined, and, when the " +
+			"SoftwareFactory resource is given, the connections used by the pipelines and the tenants that are not defined.",
+		Args: cobra.ExactArgs(1),
+		Run:  zuulLint,
+	}
+	lintCmd.Flags().String("cr", "", "the path to the SoftwareFactory resource, to check the connections")
+	return lintCmd
+}
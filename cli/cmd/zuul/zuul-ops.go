@@ -0,0 +1,397 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zuul
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Day-to-day operations on a running Zuul, through the REST API.
+
+var outputFormats = []string{"table", "json"}
+
+func getFQDN(kmd *cobra.Command) string {
+	fqdn, _ := kmd.Flags().GetString("fqdn")
+	if fqdn == "" {
+		fqdn = "sfop.me"
+	}
+	return fqdn
+}
+
+func getRequiredFlag(kmd *cobra.Command, flag string) string {
+	value, _ := kmd.Flags().GetString(flag)
+	if value == "" {
+		ctrl.Log.Error(errors.New("missing argument"), "The --"+flag+" flag is required")
+		os.Exit(1)
+	}
+	return value
+}
+
+// getZuulAPI returns a read-only client for the tenant set in the command's flags.
+func getZuulAPI(kmd *cobra.Command) ZuulAPI {
+	tenant := getRequiredFlag(kmd, "tenant")
+	insecure, _ := kmd.Flags().GetBool("insecure")
+	return MkZuulAPI(getFQDN(kmd), tenant, "", !insecure)
+}
+
+// getAdminZuulAPI returns a client authorized to perform tenant admin actions. When no token is
+// passed with --auth-token, one is generated on the scheduler with zuul-admin.
+func getAdminZuulAPI(kmd *cobra.Command) ZuulAPI {
+	api := getZuulAPI(kmd)
+	token, _ := kmd.Flags().GetString("auth-token")
+	if token == "" {
+		env := cliutils.GetCLIContext(kmd)
+		authConfig, _ := kmd.Flags().GetString("auth-config")
+		user, _ := kmd.Flags().GetString("user")
+		token = CreateAuthToken(env, authConfig, api.Tenant, user, 600)
+	}
+	api.Token = strings.TrimPrefix(strings.TrimSpace(token), "Bearer ")
+	if api.Token == "" {
+		ctrl.Log.Error(errors.New("no token"), "Could not get an authentication token for tenant "+api.Tenant)
+		os.Exit(1)
+	}
+	return api
+}
+
+func exitOnAPIError(err error, msg string) {
+	if err != nil {
+		ctrl.Log.Error(err, msg)
+		os.Exit(1)
+	}
+}
+
+func getOutputFormat(kmd *cobra.Command) string {
+	output, _ := kmd.Flags().GetString("output")
+	for _, f := range outputFormats {
+		if output == f {
+			return output
+		}
+	}
+	ctrl.Log.Error(errors.New("invalid argument"), "Unsupported output format "+output+", expected one of: "+strings.Join(outputFormats, ", "))
+	os.Exit(1)
+	return ""
+}
+
+// printResult prints data as JSON, or as a table with the given header and rows.
+func printResult(kmd *cobra.Command, data any, header []string, rows [][]string) {
+	if getOutputFormat(kmd) == "json" {
+		out, err := json.MarshalIndent(data, "", "  ")
+		exitOnAPIError(err, "Error marshalling JSON output")
+		fmt.Println(string(out))
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+func getChangeRequest(kmd *cobra.Command) ZuulAPIChangeRequest {
+	pipeline := getRequiredFlag(kmd, "pipeline")
+	change, _ := kmd.Flags().GetString("change")
+	ref, _ := kmd.Flags().GetString("ref")
+	oldrev, _ := kmd.Flags().GetString("oldrev")
+	newrev, _ := kmd.Flags().GetString("newrev")
+	if (change == "") == (ref == "") {
+		ctrl.Log.Error(errors.New("invalid arguments"), "Exactly one of --change or --ref must be set")
+		os.Exit(1)
+	}
+	return ZuulAPIChangeRequest{
+		Pipeline: pipeline,
+		Change:   change,
+		Ref:      ref,
+		OldRev:   oldrev,
+		NewRev:   newrev,
+	}
+}
+
+func zuulEnqueue(kmd *cobra.Command, args []string) {
+	project := getRequiredFlag(kmd, "project")
+	req := getChangeRequest(kmd)
+	api := getAdminZuulAPI(kmd)
+	exitOnAPIError(api.Enqueue(project, req), "Could not enqueue into pipeline "+req.Pipeline)
+	ctrl.Log.Info("Enqueued into pipeline " + req.Pipeline)
+}
+
+func zuulDequeue(kmd *cobra.Command, args []string) {
+	project := getRequiredFlag(kmd, "project")
+	req := getChangeRequest(kmd)
+	api := getAdminZuulAPI(kmd)
+	exitOnAPIError(api.Dequeue(project, req), "Could not dequeue from pipeline "+req.Pipeline)
+	ctrl.Log.Info("Dequeued from pipeline " + req.Pipeline)
+}
+
+func zuulPromote(kmd *cobra.Command, args []string) {
+	pipeline := getRequiredFlag(kmd, "pipeline")
+	changes, _ := kmd.Flags().GetStringSlice("changes")
+	if len(changes) == 0 {
+		ctrl.Log.Error(errors.New("missing argument"), "The --changes flag is required")
+		os.Exit(1)
+	}
+	api := getAdminZuulAPI(kmd)
+	exitOnAPIError(api.Promote(ZuulAPIPromoteRequest{Pipeline: pipeline, Changes: changes}), "Could not promote changes in pipeline "+pipeline)
+	ctrl.Log.Info("Promoted " + strings.Join(changes, ", ") + " in pipeline " + pipeline)
+}
+
+func zuulAutoholdList(kmd *cobra.Command, args []string) {
+	api := getZuulAPI(kmd)
+	autoholds, err := api.ListAutoholds()
+	exitOnAPIError(err, "Could not list autohold requests")
+	rows := [][]string{}
+	for _, ah := range autoholds {
+		rows = append(rows, []string{
+			ah.ID, ah.Project, ah.Job, ah.RefFilter,
+			fmt.Sprintf("%d/%d", ah.CurrentCount, ah.MaxCount),
+			strconv.Itoa(ah.NodeExpiration), ah.Reason,
+		})
+	}
+	printResult(kmd, autoholds, []string{"ID", "PROJECT", "JOB", "REF FILTER", "COUNT", "EXPIRATION", "REASON"}, rows)
+}
+
+func zuulAutoholdCreate(kmd *cobra.Command, args []string) {
+	project := getRequiredFlag(kmd, "project")
+	job := getRequiredFlag(kmd, "job")
+	reason := getRequiredFlag(kmd, "reason")
+	change, _ := kmd.Flags().GetString("change")
+	ref, _ := kmd.Flags().GetString("ref")
+	count, _ := kmd.Flags().GetInt("count")
+	expiration, _ := kmd.Flags().GetInt("node-hold-expiration")
+	api := getAdminZuulAPI(kmd)
+	req := ZuulAPIAutoholdRequest{
+		Change:             change,
+		Ref:                ref,
+		Reason:             reason,
+		Count:              count,
+		Job:                job,
+		NodeHoldExpiration: expiration,
+	}
+	exitOnAPIError(api.CreateAutohold(project, req), "Could not create autohold request")
+	ctrl.Log.Info("Autohold request created for job " + job + " on project " + project)
+}
+
+func zuulAutoholdDelete(kmd *cobra.Command, args []string) {
+	api := getAdminZuulAPI(kmd)
+	exitOnAPIError(api.DeleteAutohold(args[0]), "Could not delete autohold request "+args[0])
+	ctrl.Log.Info("Autohold request " + args[0] + " deleted")
+}
+
+func zuulStatus(kmd *cobra.Command, args []string) {
+	pipelineFilter, _ := kmd.Flags().GetString("pipeline")
+	api := getZuulAPI(kmd)
+	status, err := api.GetStatus()
+	exitOnAPIError(err, "Could not get the tenant status")
+	pipelines := []ZuulAPIStatusPipeline{}
+	rows := [][]string{}
+	for _, pipeline := range status.Pipelines {
+		if pipelineFilter != "" && pipeline.Name != pipelineFilter {
+			continue
+		}
+		pipelines = append(pipelines, pipeline)
+		for _, queue := range pipeline.ChangeQueues {
+			for _, head := range queue.Heads {
+				for _, item := range head {
+					completed := 0
+					for _, job := range item.Jobs {
+						if job.Result != nil {
+							completed++
+						}
+					}
+					enqueued := ""
+					if item.EnqueueTime > 0 {
+						enqueued = time.UnixMilli(item.EnqueueTime).Format(time.RFC3339)
+					}
+					rows = append(rows, []string{
+						pipeline.Name, queue.Name, item.GetProject(), item.GetID(),
+						strconv.FormatBool(item.Live),
+						fmt.Sprintf("%d/%d", completed, len(item.Jobs)), enqueued,
+					})
+				}
+			}
+		}
+	}
+	printResult(kmd, pipelines, []string{"PIPELINE", "QUEUE", "PROJECT", "CHANGE", "LIVE", "JOBS", "ENQUEUED"}, rows)
+}
+
+func zuulNodeRequests(kmd *cobra.Command, args []string) {
+	insecure, _ := kmd.Flags().GetBool("insecure")
+	api := MkZuulAPI(getFQDN(kmd), "", "", !insecure)
+	requests, err := api.GetNodeRequests()
+	exitOnAPIError(err, "Could not get the node requests")
+	rows := [][]string{}
+	for _, req := range requests {
+		rows = append(rows, []string{
+			req.ID, req.State, req.Requestor,
+			strings.Join(req.NodeTypes, ","), strings.Join(req.Nodes, ","),
+		})
+	}
+	printResult(kmd, requests, []string{"ID", "STATE", "REQUESTOR", "NODE TYPES", "NODES"}, rows)
+}
+
+func zuulBuilds(kmd *cobra.Command, args []string) {
+	project := getRequiredFlag(kmd, "project")
+	pipeline, _ := kmd.Flags().GetString("pipeline")
+	job, _ := kmd.Flags().GetString("job")
+	limit, _ := kmd.Flags().GetInt("limit")
+	api := getZuulAPI(kmd)
+	builds, err := api.GetBuilds(map[string]string{
+		"project":  project,
+		"pipeline": pipeline,
+		"job_name": job,
+	}, limit)
+	exitOnAPIError(err, "Could not get the builds of project "+project)
+	rows := [][]string{}
+	for _, build := range builds {
+		change := build.Ref.Ref
+		if build.Ref.Change != nil {
+			change = fmt.Sprintf("%d,%s", *build.Ref.Change, build.Ref.Patchset)
+		}
+		rows = append(rows, []string{
+			build.UUID, build.JobName, build.Pipeline, change, build.Result,
+			build.StartTime, fmt.Sprintf("%.0fs", build.Duration),
+		})
+	}
+	printResult(kmd, builds, []string{"UUID", "JOB", "PIPELINE", "CHANGE", "RESULT", "START", "DURATION"}, rows)
+}
+
+func addAPIFlags(kmd *cobra.Command) {
+	kmd.Flags().String("tenant", "", "the Zuul tenant")
+	kmd.Flags().Bool("insecure", false, "do not verify SSL certificates when connection to Zuul")
+}
+
+func addAdminFlags(kmd *cobra.Command) {
+	addAPIFlags(kmd)
+	kmd.Flags().String("auth-token", "", "an authentication token with admin access on the tenant. If unset, a token is generated with zuul-admin")
+	kmd.Flags().String("auth-config", "zuul_client", "the local authentication config to use to generate a token")
+	kmd.Flags().String("user", "John Doe", "a username, only used for audit purposes in Zuul's access logs")
+}
+
+func addOutputFlag(kmd *cobra.Command) {
+	kmd.Flags().StringP("output", "o", "table", "output format, one of: "+strings.Join(outputFormats, ", "))
+}
+
+func addChangeFlags(kmd *cobra.Command) {
+	kmd.Flags().String("project", "", "the project name")
+	kmd.Flags().String("pipeline", "", "the pipeline name")
+	kmd.Flags().String("change", "", "the change, as \"<number>,<patchset>\"")
+	kmd.Flags().String("ref", "", "the git ref, for ref-based pipelines")
+	kmd.Flags().String("oldrev", "", "the old revision of the ref")
+	kmd.Flags().String("newrev", "", "the new revision of the ref")
+}
+
+func mkZuulOpsCmds() []*cobra.Command {
+	enqueueCmd := &cobra.Command{
+		Use:   "enqueue",
+		Short: "Enqueue a change or a ref into a pipeline",
+		Run:   zuulEnqueue,
+	}
+	addAdminFlags(enqueueCmd)
+	addChangeFlags(enqueueCmd)
+
+	dequeueCmd := &cobra.Command{
+		Use:   "dequeue",
+		Short: "Dequeue a change or a ref from a pipeline",
+		Run:   zuulDequeue,
+	}
+	addAdminFlags(dequeueCmd)
+	addChangeFlags(dequeueCmd)
+
+	promoteCmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Promote changes at the top of a dependent pipeline",
+		Run:   zuulPromote,
+	}
+	addAdminFlags(promoteCmd)
+	promoteCmd.Flags().String("pipeline", "", "the pipeline name")
+	promoteCmd.Flags().StringSlice("changes", []string{}, "the changes to promote, as a comma separated list of \"<number>,<patchset>\"")
+
+	autoholdCmd := &cobra.Command{
+		Use:   "autohold",
+		Short: "Manage autohold requests",
+	}
+	autoholdListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the autohold requests of a tenant",
+		Run:   zuulAutoholdList,
+	}
+	addAPIFlags(autoholdListCmd)
+	addOutputFlag(autoholdListCmd)
+	autoholdCreateCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an autohold request",
+		Run:   zuulAutoholdCreate,
+	}
+	addAdminFlags(autoholdCreateCmd)
+	autoholdCreateCmd.Flags().String("project", "", "the project name")
+	autoholdCreateCmd.Flags().String("job", "", "the job name")
+	autoholdCreateCmd.Flags().String("reason", "", "the reason for the hold")
+	autoholdCreateCmd.Flags().String("change", "", "only hold nodes for this change")
+	autoholdCreateCmd.Flags().String("ref", "", "only hold nodes for refs matching this regular expression")
+	autoholdCreateCmd.Flags().Int("count", 1, "how many times the nodes should be held")
+	autoholdCreateCmd.Flags().Int("node-hold-expiration", 0, "how long in seconds nodes are held, 0 uses the tenant's default")
+	autoholdDeleteCmd := &cobra.Command{
+		Use:   "delete REQUEST_ID",
+		Short: "Delete an autohold request",
+		Args:  cobra.ExactArgs(1),
+		Run:   zuulAutoholdDelete,
+	}
+	addAdminFlags(autoholdDeleteCmd)
+	autoholdCmd.AddCommand(autoholdListCmd, autoholdCreateCmd, autoholdDeleteCmd, mkAutoholdSSHCmd())
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the items queued in the pipelines of a tenant",
+		Run:   zuulStatus,
+	}
+	addAPIFlags(statusCmd)
+	addOutputFlag(statusCmd)
+	statusCmd.Flags().String("pipeline", "", "only show this pipeline")
+
+	nodeRequestsCmd := &cobra.Command{
+		Use:   "node-requests",
+		Short: "List the pending node requests",
+		Run:   zuulNodeRequests,
+	}
+	nodeRequestsCmd.Flags().Bool("insecure", false, "do not verify SSL certificates when connection to Nodepool")
+	addOutputFlag(nodeRequestsCmd)
+
+	buildsCmd := &cobra.Command{
+		Use:   "builds",
+		Short: "List the builds of a project",
+		Run:   zuulBuilds,
+	}
+	addAPIFlags(buildsCmd)
+	addOutputFlag(buildsCmd)
+	buildsCmd.Flags().String("project", "", "the project name")
+	buildsCmd.Flags().String("pipeline", "", "only show builds from this pipeline")
+	buildsCmd.Flags().String("job", "", "only show builds of this job")
+	buildsCmd.Flags().Int("limit", 20, "the maximum number of builds to show")
+
+	return []*cobra.Command{enqueueCmd, dequeueCmd, promoteCmd, autoholdCmd, statusCmd, nodeRequestsCmd, buildsCmd}
+}
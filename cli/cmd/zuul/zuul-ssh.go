@@ -0,0 +1,209 @@
+/*
+Copyright © 2026 Red Hat
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zuul
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	cliutils "github.com/softwarefactory-project/sf-operator/cli/cmd/utils"
+	sfop "github.com/softwarefactory-project/sf-operator/controllers"
+	"github.com/spf13/cobra"
+	apiv1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SSH access to the nodes held by an autohold request.
+
+// The pods used to reach nodes that are only routable from the cluster, with the path
+// where the private key is mounted.
+type sshJumpPod struct {
+	pod       string
+	container string
+	keyPath   string
+}
+
+var sshKeys = map[string]string{
+	"zuul":    "zuul-ssh-key",
+	"builder": "nodepool-builder-ssh-key",
+}
+
+var sshJumpPods = map[string]sshJumpPod{
+	"zuul":    {"zuul-executor-0", "zuul-executor", "/var/lib/zuul-ssh/priv"},
+	"builder": {"nodepool-builder-0", "nodepool-builder", "/var/lib/nodepool-ssh-key/priv"},
+}
+
+// getHeldNode returns the nodepool node held by an autohold request. When nodeID is empty,
+// the first held node is returned.
+func getHeldNode(api ZuulAPI, requestID string, nodeID string) NodepoolAPINode {
+	autohold, err := api.GetAutohold(requestID)
+	exitOnAPIError(err, "Could not get autohold request "+requestID)
+	held := []string{}
+	for _, build := range autohold.Nodes {
+		held = append(held, build.Nodes...)
+	}
+	if len(held) == 0 {
+		ctrl.Log.Error(errors.New("no held node"), "Autohold request "+requestID+" did not hold any node yet")
+		os.Exit(1)
+	}
+	if nodeID == "" {
+		nodeID = held[0]
+		if len(held) > 1 {
+			ctrl.Log.Info("Several nodes are held, connecting to the first one. Use --node to select another one", "nodes", held)
+		}
+	} else if !slices.Contains(held, nodeID) {
+		ctrl.Log.Error(errors.New("unknown node"), "Node "+nodeID+" is not held by autohold request "+requestID, "nodes", held)
+		os.Exit(1)
+	}
+	node, err := api.GetNodepoolNode(nodeID)
+	exitOnAPIError(err, "Could not get node "+nodeID+" from the nodepool launcher")
+	return node
+}
+
+// getNodeAddress returns the address to use to reach the node, and whether it is only
+// reachable from inside the cluster.
+func getNodeAddress(node NodepoolAPINode) (string, bool) {
+	for _, addr := range []string{node.PublicIPv4, node.IPv6} {
+		if addr != "" {
+			return addr, false
+		}
+	}
+	if node.PrivateIPv4 != "" {
+		return node.PrivateIPv4, true
+	}
+	if ip := net.ParseIP(node.Hostname); ip != nil {
+		return node.Hostname, ip.IsPrivate()
+	}
+	return node.Hostname, true
+}
+
+// getSSHPrivateKeys writes the private keys to use in a temporary directory and returns their paths.
+func getSSHPrivateKeys(env *sfop.SFKubeContext, keys []string, dir string) []string {
+	paths := []string{}
+	for _, key := range keys {
+		var secret apiv1.Secret
+		if !env.GetOrDie(sshKeys[key], &secret) {
+			ctrl.Log.Error(errors.New("Secret "+sshKeys[key]+" not found in namespace "+env.Ns), "Error fetching SSH key")
+			os.Exit(1)
+		}
+		path := filepath.Join(dir, sshKeys[key])
+		if err := os.WriteFile(path, secret.Data["priv"], 0600); err != nil {
+			ctrl.Log.Error(err, "Could not write SSH key "+path)
+			os.Exit(1)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func zuulAutoholdSSH(kmd *cobra.Command, args []string) {
+	nodeID, _ := kmd.Flags().GetString("node")
+	key, _ := kmd.Flags().GetString("key")
+	viaExecutor, _ := kmd.Flags().GetBool("via-executor")
+	if key != "auto" && sshKeys[key] == "" {
+		ctrl.Log.Error(errors.New("invalid argument"), "Unsupported key "+key+", expected one of: auto, zuul, builder")
+		os.Exit(1)
+	}
+
+	api := getZuulAPI(kmd)
+	node := getHeldNode(api, args[0], nodeID)
+	if node.ConnectionType != "" && node.ConnectionType != "ssh" && node.ConnectionType != "network_cli" {
+		ctrl.Log.Error(errors.New("unsupported connection"), "Node "+node.ID+" uses the "+node.ConnectionType+" connection type, SSH is not available")
+		os.Exit(1)
+	}
+	address, internal := getNodeAddress(node)
+	if address == "" {
+		ctrl.Log.Error(errors.New("no address"), "Node "+node.ID+" does not have an address")
+		os.Exit(1)
+	}
+	user := node.Username
+	if user == "" {
+		user = "zuul"
+	}
+	port := node.ConnectionPort
+	if port == 0 {
+		port = 22
+	}
+	sshArgs := []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"-p", strconv.Itoa(port),
+	}
+	ctrl.Log.Info("Connecting to node "+node.ID, "provider", node.Provider, "label", node.Label, "address", address)
+
+	env := cliutils.GetCLIContext(kmd)
+	if viaExecutor || internal {
+		// Nodes are reached from a pod with the private key already mounted. The executor
+		// uses the zuul key, which is the one set on the nodes running jobs.
+		if key == "auto" {
+			key = "zuul"
+		}
+		jump := sshJumpPods[key]
+		cmd := append([]string{"ssh", "-t", "-i", jump.keyPath}, sshArgs...)
+		cmd = append(cmd, user+"@"+address)
+		if err := env.PodExecTTY(jump.pod, jump.container, cmd); err != nil {
+			ctrl.Log.Error(err, "SSH session through "+jump.pod+" failed")
+			os.Exit(1)
+		}
+		return
+	}
+
+	// When unsure, offer both keys: the zuul key is set on the nodes by the jobs, the
+	// builder key is baked in the images built by the nodepool-builder.
+	keys := []string{"zuul", "builder"}
+	if key != "auto" {
+		keys = []string{key}
+	}
+	dir, err := os.MkdirTemp("", "sf-operator-ssh-")
+	if err != nil {
+		ctrl.Log.Error(err, "Could not create a temporary directory")
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+	for _, path := range getSSHPrivateKeys(env, keys, dir) {
+		sshArgs = append(sshArgs, "-i", path)
+	}
+	sshArgs = append(sshArgs, "-o", "IdentitiesOnly=yes", user+"@"+address)
+	sshCmd := exec.Command("ssh", sshArgs...)
+	sshCmd.Stdin = os.Stdin
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
+	if err := sshCmd.Run(); err != nil {
+		ctrl.Log.Error(err, "SSH session failed")
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+}
+
+func mkAutoholdSSHCmd() *cobra.Command {
+	sshCmd := &cobra.Command{
+		Use:   "ssh REQUEST_ID",
+		Short: "Open an SSH session on a node held by an autohold request",
+		Args:  cobra.ExactArgs(1),
+		Run:   zuulAutoholdSSH,
+	}
+	addAPIFlags(sshCmd)
+	sshCmd.Flags().String("node", "", "the held node to connect to, defaults to the first one")
+	sshCmd.Flags().String("key", "auto", "the private key to use, one of: auto, zuul, builder")
+	sshCmd.Flags().Bool("via-executor", false, "connect from the zuul-executor pod, for nodes only reachable from the cluster")
+	return sshCmd
+}
@@ -4,22 +4,36 @@
 package controllers
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	batchv1 "k8s.io/api/batch/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
 
 const (
 	zuulBackupPod     = "zuul-kazoo"
-	dbBackupPod       = "mariadb-0"
+	dbClientJob       = "zuul-db-client"
 	DBBackupPath      = "mariadb/db-zuul.sql"
+	BinlogBackupPath  = "mariadb/binlog/"
+	binlogDir         = "/var/log/mariadb"
 	ZuulBackupPath    = "zuul/zuul.keys"
 	SecretsBackupPath = "secrets/"
 )
@@ -37,6 +51,25 @@ var SecretsToBackup = []string{
 	"logserver-uploader-spare-keys",
 }
 
+// backupSecrets returns the secrets referenced by the resource that are part of the backup
+func backupSecrets(cr sfv1.SoftwareFactory) []string {
+	secrets := append(slices.Clone(SecretsToBackup), CRSecrets(cr)...)
+	if isLogserverS3(cr.Spec.Logserver) {
+		// The logserver keys are not created with the s3 backend
+		secrets = slices.DeleteFunc(secrets, func(name string) bool {
+			return strings.HasPrefix(name, "logserver-")
+		})
+		secrets = append(secrets, cr.Spec.Logserver.S3.SecretName)
+	}
+	if ext := cr.Spec.ExternalDatabase; ext != nil {
+		secrets = append(secrets, ext.SecretName)
+		if ext.TLSCA != nil && ext.TLSCA.Name != ext.SecretName {
+			secrets = append(secrets, ext.TLSCA.Name)
+		}
+	}
+	return secrets
+}
+
 func (r *SFKubeContext) createSecretBackup(backupDir string, cr sfv1.SoftwareFactory) error {
 	ctrl.Log.Info("Creating secrets backup...")
 
@@ -46,7 +79,7 @@ func (r *SFKubeContext) createSecretBackup(backupDir string, cr sfv1.SoftwareFac
 		return err
 	}
 
-	for _, secName := range append(SecretsToBackup, CRSecrets(cr)...) {
+	for _, secName := range backupSecrets(cr) {
 		secret := apiv1.Secret{}
 		exist, err := r.Get(secName, &secret)
 		if err != nil {
@@ -131,7 +164,118 @@ func (r *SFKubeContext) createZuulKeypairBackup(backupDir string) error {
 	return nil
 }
 
-func (r *SFKubeContext) createMySQLBackup(backupDir string) error {
+// mkDBClientJob returns a Job running an idle database client, used to dump and restore an external database.
+// The connection settings are read from the zuul-db-connection secret.
+func (r *SFKubeContext) mkDBClientJob(spec *sfv1.ExternalDatabaseSpec) *batchv1.Job {
+	image := base.MariaDBImage()
+	if getExternalDBDriver(spec) == "postgresql" {
+		image = base.PostgreSQLImage()
+	}
+	container := base.MkContainer(dbClientJob, image, r.IsOpenShift)
+	base.SetContainerLimitsLowProfile(&container)
+	container.Command = []string{"sleep", "3600"}
+	container.Env = []apiv1.EnvVar{
+		base.MkSecretEnvVar("DB_HOST", zuulDBConfigSecret, "host"),
+		base.MkSecretEnvVar("DB_PORT", zuulDBConfigSecret, "port"),
+		base.MkSecretEnvVar("DB_USER", zuulDBConfigSecret, "username"),
+		base.MkSecretEnvVar("DB_NAME", zuulDBConfigSecret, "database"),
+		base.MkSecretEnvVar("MYSQL_PWD", zuulDBConfigSecret, "password"),
+		base.MkSecretEnvVar("PGPASSWORD", zuulDBConfigSecret, "password"),
+	}
+	container.VolumeMounts = mkExternalDBCAVolumeMount(spec)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dbClientJob,
+			Namespace: r.Ns,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          ptr.To[int32](0),
+			ActiveDeadlineSeconds: ptr.To[int64](3600),
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					RestartPolicy: apiv1.RestartPolicyNever,
+					Containers:    []apiv1.Container{container},
+					Volumes:       mkExternalDBCAVolume(spec),
+				},
+			},
+		},
+	}
+}
+
+// ensureDBClientJob starts the database client Job and returns the name of its pod once it is running
+func (r *SFKubeContext) ensureDBClientJob(spec *sfv1.ExternalDatabaseSpec) (string, error) {
+	return r.ensureIdleJob(r.mkDBClientJob(spec))
+}
+
+func (r *SFKubeContext) deleteDBClientJob() {
+	r.deleteIdleJob(dbClientJob)
+}
+
+// ensureIdleJob starts a Job running an idle pod and returns the name of its pod once it is running
+func (r *SFKubeContext) ensureIdleJob(newJob *batchv1.Job) (string, error) {
+	var job batchv1.Job
+	if !r.GetOrDie(newJob.Name, &job) {
+		r.CreateR(newJob)
+	}
+	podName := ""
+	ready := WaitFor(func() bool {
+		var pods apiv1.PodList
+		if err := r.Client.List(r.Ctx, &pods, client.InNamespace(r.Ns), client.MatchingLabels{"job-name": newJob.Name}); err != nil {
+			return false
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == apiv1.PodRunning {
+				podName = pod.Name
+				return true
+			}
+		}
+		return false
+	})
+	if !ready {
+		return "", errors.New(newJob.Name + ": the job pod is not running")
+	}
+	return podName, nil
+}
+
+func (r *SFKubeContext) deleteIdleJob(name string) {
+	job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.Ns}}
+	if err := r.Client.Delete(r.Ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		ctrl.Log.Error(err, "Couldn't delete the job "+name)
+	}
+}
+
+// dbClientTLSArgs returns the client options to verify the external database server certificate
+func dbClientTLSArgs(spec *sfv1.ExternalDatabaseSpec) string {
+	if spec.TLSCA == nil {
+		return ""
+	}
+	if getExternalDBDriver(spec) == "postgresql" {
+		return " sslmode=verify-full sslrootcert=" + externalDBCAPath + "/ca.crt"
+	}
+	return " --ssl-ca=" + externalDBCAPath + "/ca.crt"
+}
+
+func mkExternalDBDumpCMD(spec *sfv1.ExternalDatabaseSpec) []string {
+	if getExternalDBDriver(spec) == "postgresql" {
+		return []string{"sh", "-c",
+			`pg_dump --clean --if-exists --no-owner "host=$DB_HOST port=$DB_PORT user=$DB_USER dbname=$DB_NAME` + dbClientTLSArgs(spec) + `"`}
+	}
+	return []string{"sh", "-c",
+		`mysqldump --host="$DB_HOST" --port="$DB_PORT" --user="$DB_USER" --single-transaction` + dbClientTLSArgs(spec) + ` "$DB_NAME"`}
+}
+
+func mkExternalDBRestoreCMD(spec *sfv1.ExternalDatabaseSpec) []string {
+	if getExternalDBDriver(spec) == "postgresql" {
+		return []string{"sh", "-c",
+			`psql --quiet --set ON_ERROR_STOP=1 "host=$DB_HOST port=$DB_PORT user=$DB_USER dbname=$DB_NAME` + dbClientTLSArgs(spec) + `"`}
+	}
+	return []string{"sh", "-c",
+		`mysql --host="$DB_HOST" --port="$DB_PORT" --user="$DB_USER"` + dbClientTLSArgs(spec) + ` "$DB_NAME"`}
+}
+
+func (r *SFKubeContext) createMySQLBackup(backupDir string, cr sfv1.SoftwareFactory) error {
+	dbBackupPod := r.GetMariaDBPrimary()
 	ctrl.Log.Info("Doing DB backup...")
 
 	// create MariaDB dir
@@ -143,6 +287,25 @@ func (r *SFKubeContext) createMySQLBackup(backupDir string) error {
 		return err
 	}
 
+	if ext := cr.Spec.ExternalDatabase; ext != nil {
+		podName, err := r.ensureDBClientJob(ext)
+		defer r.deleteDBClientJob()
+		if err != nil {
+			return err
+		}
+		commandBuffer, err := r.PodExecBytes(podName, dbClientJob, mkExternalDBDumpCMD(ext))
+		if err != nil {
+			ctrl.Log.Error(err, "Couldn't read backup")
+			return err
+		}
+		if err := os.WriteFile(mariadbBackupPath, commandBuffer.Bytes(), 0640); err != nil {
+			ctrl.Log.Error(err, "Couldn't write:"+mariadbBackupPath)
+			return err
+		}
+		ctrl.Log.Info("Finished doing external DB backup!")
+		return nil
+	}
+
 	pod := apiv1.Pod{}
 	exist, err := r.Get(dbBackupPod, &pod)
 	if err != nil {
@@ -160,6 +323,11 @@ func (r *SFKubeContext) createMySQLBackup(backupDir string) error {
 		"zuul",
 		"--single-transaction",
 	}
+	binlogEnabled := getBinlogRetentionDays(cr.Spec.MariaDB.Binlog) > 0
+	if binlogEnabled {
+		// Start a new binlog and record its position in the dump, so that the binlogs can be replayed on top of it
+		backupZuulCMD = append(backupZuulCMD, "--flush-logs", "--master-data=2")
+	}
 
 	// just create Zuul DB backup
 	commandBuffer, err := r.PodExecBytes(pod.Name, MariaDBIdent, backupZuulCMD)
@@ -173,10 +341,72 @@ func (r *SFKubeContext) createMySQLBackup(backupDir string) error {
 		ctrl.Log.Error(err, "Couldn't write:"+mariadbBackupPath)
 		return err
 	}
+	if binlogEnabled {
+		if err := r.archiveBinlogs(backupDir); err != nil {
+			return err
+		}
+	}
 	ctrl.Log.Info("Finished doing DBs backup!")
 	return nil
 }
 
+// archiveBinlogs copies the binary logs of the MariaDB server to the backup directory.
+// The binlogs already archived with the same size are skipped.
+func (r *SFKubeContext) archiveBinlogs(backupDir string) error {
+	dbBackupPod := r.GetMariaDBPrimary()
+	binlogBackupDir := filepath.Join(backupDir, BinlogBackupPath)
+	if err := os.MkdirAll(binlogBackupDir, 0750); err != nil {
+		ctrl.Log.Error(err, "Couldn't create backup dir:"+binlogBackupDir)
+		return err
+	}
+
+	output, err := r.PodExecBytes(dbBackupPod, MariaDBIdent, []string{"mysql", "--skip-column-names", "-e", "SHOW BINARY LOGS"})
+	if err != nil {
+		ctrl.Log.Error(err, "Couldn't list the binary logs")
+		return err
+	}
+	archived := 0
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		name := fields[0]
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		dest := filepath.Join(binlogBackupDir, name)
+		if stat, err := os.Stat(dest); err == nil && stat.Size() == size {
+			continue
+		}
+		content, err := r.PodExecBytes(dbBackupPod, MariaDBIdent, []string{"cat", binlogDir + "/" + name})
+		if err != nil {
+			ctrl.Log.Error(err, "Couldn't read the binary log "+name)
+			return err
+		}
+		if err := os.WriteFile(dest, content.Bytes(), 0640); err != nil {
+			ctrl.Log.Error(err, "Couldn't write:"+dest)
+			return err
+		}
+		archived += 1
+	}
+	ctrl.Log.Info(fmt.Sprintf("Archived %d binary logs in %s", archived, binlogBackupDir))
+	return nil
+}
+
+// DoBinlogArchive closes the current binary log and archives the binary logs in the backup directory.
+// It is meant to be run between two full backups to reduce the amount of data that can be lost.
+func (r *SFKubeContext) DoBinlogArchive(backupDir string, cr sfv1.SoftwareFactory) error {
+	dbBackupPod := r.GetMariaDBPrimary()
+	if cr.Spec.ExternalDatabase != nil || getBinlogRetentionDays(cr.Spec.MariaDB.Binlog) == 0 {
+		return errors.New("the binary logs are not enabled, set spec.mariadb.binlog")
+	}
+	if err := r.PodExecOut(dbBackupPod, MariaDBIdent, []string{"mysql", "-e", "FLUSH BINARY LOGS"}, os.Stdout); err != nil {
+		ctrl.Log.Error(err, "Couldn't flush the binary logs")
+		return err
+	}
+	return r.archiveBinlogs(backupDir)
+}
+
 func (r *SFKubeContext) DoBackup(backupDir string, cr sfv1.SoftwareFactory) error {
 	// TODO: check that the CR name and the FQDN match the cr being backuped
 	ctrl.Log.Info("Starting backup process for services in namespace: " + r.Ns)
@@ -192,7 +422,7 @@ func (r *SFKubeContext) DoBackup(backupDir string, cr sfv1.SoftwareFactory) erro
 	}
 
 	// create DB backup
-	if err := r.createMySQLBackup(backupDir); err != nil {
+	if err := r.createMySQLBackup(backupDir, cr); err != nil {
 		return err
 	}
 	return nil
@@ -202,7 +432,7 @@ func (r *SFKubeContext) DoBackup(backupDir string, cr sfv1.SoftwareFactory) erro
 func (r *SFKubeContext) restoreSecret(backupDir string, cr sfv1.SoftwareFactory) error {
 	ctrl.Log.Info("Restoring secrets...")
 
-	for _, sec := range append(SecretsToBackup, CRSecrets(cr)...) {
+	for _, sec := range backupSecrets(cr) {
 		pathToSecret := backupDir + "/" + SecretsBackupPath + "/" + sec + ".yaml"
 		data, err := os.ReadFile(pathToSecret)
 		if err != nil {
@@ -220,7 +450,94 @@ func (r *SFKubeContext) restoreSecret(backupDir string, cr sfv1.SoftwareFactory)
 	return nil
 }
 
-func (r *SFKubeContext) restoreDB(backupDir string) error {
+func (r *SFKubeContext) restoreExternalDB(backupDir string, spec *sfv1.ExternalDatabaseSpec) error {
+	ctrl.Log.Info("Restoring external DB...")
+	data, err := os.ReadFile(backupDir + "/" + DBBackupPath)
+	if err != nil {
+		ctrl.Log.Error(err, "Couldn't read sql dump")
+		return err
+	}
+
+	podName, err := r.ensureDBClientJob(spec)
+	defer r.deleteDBClientJob()
+	if err != nil {
+		return err
+	}
+	// The dump drops the existing tables before re-creating them
+	if err := r.PodExecIn(podName, dbClientJob, mkExternalDBRestoreCMD(spec), bytes.NewReader(data)); err != nil {
+		ctrl.Log.Error(err, "Couldn't inject sql dump")
+		return err
+	}
+
+	ctrl.Log.Info("Finished restoring external DB from backup!")
+	return nil
+}
+
+var dumpBinlogPosition = regexp.MustCompile(`CHANGE MASTER TO MASTER_LOG_FILE='([^']+)', MASTER_LOG_POS=([0-9]+)`)
+
+// replayBinlogs applies the archived binary logs, starting at the position recorded in the dump, up to toTime
+func (r *SFKubeContext) replayBinlogs(backupDir string, dump []byte, toTime time.Time) error {
+	dbBackupPod := r.GetMariaDBPrimary()
+	ctrl.Log.Info("Replaying binary logs up to " + toTime.UTC().Format(time.RFC3339))
+	match := dumpBinlogPosition.FindSubmatch(dump)
+	if match == nil {
+		return errors.New("the sql dump does not record a binlog position, was the backup taken with spec.mariadb.binlog set?")
+	}
+	startFile, startPos := string(match[1]), string(match[2])
+
+	binlogBackupDir := filepath.Join(backupDir, BinlogBackupPath)
+	entries, err := os.ReadDir(binlogBackupDir)
+	if err != nil {
+		ctrl.Log.Error(err, "Couldn't read the binary logs archive")
+		return err
+	}
+	binlogs := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && entry.Name() >= startFile {
+			binlogs = append(binlogs, entry.Name())
+		}
+	}
+	sort.Strings(binlogs)
+	if len(binlogs) == 0 || binlogs[0] != startFile {
+		return errors.New(startFile + ": missing binary log in the archive")
+	}
+
+	tmpDir := "/tmp/binlog-restore"
+	r.PodExecM(dbBackupPod, MariaDBIdent, []string{"sh", "-c", "rm -rf " + tmpDir + " && mkdir -p " + tmpDir})
+	defer r.PodExecM(dbBackupPod, MariaDBIdent, []string{"rm", "-rf", tmpDir})
+	paths := []string{}
+	for _, binlog := range binlogs {
+		data, err := os.ReadFile(filepath.Join(binlogBackupDir, binlog))
+		if err != nil {
+			ctrl.Log.Error(err, "Couldn't read binary log "+binlog)
+			return err
+		}
+		if err := r.PodExecIn(dbBackupPod, MariaDBIdent, []string{"sh", "-c", "cat > " + tmpDir + "/" + binlog}, bytes.NewReader(data)); err != nil {
+			ctrl.Log.Error(err, "Couldn't copy binary log "+binlog)
+			return err
+		}
+		paths = append(paths, tmpDir+"/"+binlog)
+	}
+
+	// The start position applies to the first binlog, and the stop datetime is read in the pod time zone
+	replayCMD := fmt.Sprintf("TZ=UTC mysqlbinlog --start-position=%s --stop-datetime='%s' %s | mysql",
+		startPos, toTime.UTC().Format(time.DateTime), strings.Join(paths, " "))
+	if err := r.PodExecOut(dbBackupPod, MariaDBIdent, []string{"sh", "-c", "set -o pipefail; " + replayCMD}, os.Stdout); err != nil {
+		ctrl.Log.Error(err, "Couldn't replay the binary logs")
+		return err
+	}
+	ctrl.Log.Info(fmt.Sprintf("Replayed %d binary logs", len(binlogs)))
+	return nil
+}
+
+func (r *SFKubeContext) restoreDB(backupDir string, cr sfv1.SoftwareFactory, toTime time.Time) error {
+	dbBackupPod := r.GetMariaDBPrimary()
+	if ext := cr.Spec.ExternalDatabase; ext != nil {
+		if !toTime.IsZero() {
+			return errors.New("point-in-time recovery is not supported with an external database")
+		}
+		return r.restoreExternalDB(backupDir, ext)
+	}
 	ctrl.Log.Info("Restoring DB...")
 	pod := apiv1.Pod{}
 	exist, err := r.Get(dbBackupPod, &pod)
@@ -243,12 +560,21 @@ func (r *SFKubeContext) restoreDB(backupDir string) error {
 		return err
 	}
 
-	err = r.PodExecIn("mariadb-0", "mariadb", []string{"mysql", "-h0"}, bytes.NewReader(data))
+	err = r.PodExecIn(pod.Name, MariaDBIdent, []string{"mysql", "-h0"}, bytes.NewReader(data))
 	if err != nil {
 		ctrl.Log.Error(err, "Couldn't inject sql dump")
 		return err
 	}
 
+	if !toTime.IsZero() {
+		if err := r.fetchArchivedBinlogs(backupDir); err != nil {
+			return err
+		}
+		if err := r.replayBinlogs(backupDir, data, toTime); err != nil {
+			return err
+		}
+	}
+
 	ctrl.Log.Info("Finished restoring DB from backup!")
 	return nil
 }
@@ -283,17 +609,23 @@ func (r *SFKubeContext) restoreZuul(backupDir string) error {
 	return nil
 }
 
-func (r *SFKubeContext) DoRestore(backupDir string, cr sfv1.SoftwareFactory) error {
+// DoRestore restores a backup. When toTime is set, the archived binary logs are replayed on top of the sql dump
+// up to that time.
+func (r *SFKubeContext) DoRestore(backupDir string, cr sfv1.SoftwareFactory, toTime time.Time) error {
 	if err := r.restoreSecret(backupDir, cr); err != nil {
 		return err
 	}
 
 	ctrl.Log.Info("Spawning backend services...")
 	sfCtrl := MkSFController(*r, cr)
-	sfCtrl.DeployMariadb()
+	deployDB := sfCtrl.DeployMariadb
+	if cr.Spec.ExternalDatabase != nil {
+		deployDB = sfCtrl.DeployExternalDatabase
+	}
+	deployDB()
 	sfCtrl.DeployZookeeper()
 	ctrl.Log.Info("Waiting for backend services...")
-	WaitFor(sfCtrl.DeployMariadb)
+	WaitFor(deployDB)
 	WaitFor(sfCtrl.DeployZookeeper)
 
 	sfCtrl.DeployZuulSecrets()
@@ -304,7 +636,7 @@ func (r *SFKubeContext) DoRestore(backupDir string, cr sfv1.SoftwareFactory) err
 	if err := r.restoreZuul(backupDir); err != nil {
 		return err
 	}
-	if err := r.restoreDB(backupDir); err != nil {
+	if err := r.restoreDB(backupDir, cr, toTime); err != nil {
 		return err
 	}
 
@@ -0,0 +1,169 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the pruning of the Zuul database.
+
+package controllers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
+)
+
+//go:embed static/zuul/prune-builds.py
+var zuulPruneBuilds string
+
+const (
+	buildRetentionIdent    = "zuul-db-prune"
+	buildArchiveIdent      = "zuul-db-archive"
+	buildArchiveMountPath  = "/var/lib/zuul-db-archive"
+	defaultPruningSchedule = "0 2 * * *"
+)
+
+// ValidateBuildRetention checks the build retention policy. The returned warnings report the retentions
+// that differ from the logserver retention, since the builds would link to purged logs, or the logs would
+// no longer be reachable from the builds.
+func ValidateBuildRetention(cr sfv1.SoftwareFactory) ([]string, error) {
+	retention := cr.Spec.Zuul.BuildRetention
+	if retention == nil {
+		return nil, nil
+	}
+	if retention.Days < 1 {
+		return nil, fmt.Errorf("invalid build retention: %d days", retention.Days)
+	}
+	logsRetention := cr.Spec.Logserver.RetentionDays
+	if logsRetention == 0 {
+		logsRetention = 60
+	}
+
+	warnings := []string{}
+	checkDays := func(name string, days int) {
+		if days < logsRetention {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s are pruned after %d days, but their logs are kept for %d days", name, days, logsRetention))
+		} else if days > logsRetention {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s are kept for %d days, but their logs are purged after %d days", name, days, logsRetention))
+		}
+	}
+	checkDays("builds", retention.Days)
+	seen := []string{}
+	for _, pipeline := range retention.Pipelines {
+		if slices.Contains(seen, pipeline.Name) {
+			return nil, fmt.Errorf("duplicate build retention for the pipeline %s", pipeline.Name)
+		}
+		if pipeline.Days < 1 {
+			return nil, fmt.Errorf("invalid build retention for the pipeline %s: %d days", pipeline.Name, pipeline.Days)
+		}
+		seen = append(seen, pipeline.Name)
+		checkDays("builds of the "+pipeline.Name+" pipeline", pipeline.Days)
+	}
+	return warnings, nil
+}
+
+func (r *SFController) mkBuildRetentionCronJob(retention *sfv1.BuildRetentionSpec) batchv1.CronJob {
+	schedule := retention.Schedule
+	if schedule == "" {
+		schedule = defaultPruningSchedule
+	}
+	command := []string{"python3", "/usr/local/bin/prune-builds.py", "--days", strconv.Itoa(retention.Days)}
+	for _, pipeline := range retention.Pipelines {
+		command = append(command, "--pipeline", fmt.Sprintf("%s=%d", pipeline.Name, pipeline.Days))
+	}
+
+	// The pruning runs with the zuul-kazoo pod settings: zuul.conf, zookeeper and the tooling scripts
+	spec := r.mkKazooPod().Spec
+	container := spec.Containers[0]
+	container.Name = buildRetentionIdent
+	container.Command = command
+	container.VolumeMounts = append(container.VolumeMounts, apiv1.VolumeMount{
+		Name:      "tooling-vol",
+		SubPath:   "prune-builds.py",
+		MountPath: "/usr/local/bin/prune-builds.py",
+		ReadOnly:  true,
+	})
+	container.VolumeMounts = append(container.VolumeMounts, mkExternalDBCAVolumeMount(r.cr.Spec.ExternalDatabase)...)
+	spec.Volumes = append(spec.Volumes, mkExternalDBCAVolume(r.cr.Spec.ExternalDatabase)...)
+	if retention.Archive {
+		container.Command = append(container.Command, "--archive-dir", buildArchiveMountPath)
+		container.VolumeMounts = append(container.VolumeMounts, apiv1.VolumeMount{
+			Name:      buildArchiveIdent,
+			MountPath: buildArchiveMountPath,
+		})
+		spec.Volumes = append(spec.Volumes, apiv1.Volume{
+			Name: buildArchiveIdent,
+			VolumeSource: apiv1.VolumeSource{
+				PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: buildArchiveIdent},
+			},
+		})
+	}
+	base.SetContainerLimitsLowProfile(&container)
+	spec.Containers = []apiv1.Container{container}
+	spec.RestartPolicy = apiv1.RestartPolicyNever
+
+	cj := batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildRetentionIdent,
+			Namespace: r.Ns,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   schedule,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: ptr.To[int32](3),
+			FailedJobsHistoryLimit:     ptr.To[int32](3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To[int32](0),
+					Template: apiv1.PodTemplateSpec{
+						Spec: spec,
+					},
+				},
+			},
+		},
+	}
+	specJSON, _ := json.Marshal(cj.Spec)
+	cj.Annotations = map[string]string{"spec-hash": utils.Checksum(specJSON)}
+	return cj
+}
+
+// EnsureBuildRetention manages the database pruning cronjob and its archive volume
+func (r *SFController) EnsureBuildRetention() {
+	retention := r.cr.Spec.Zuul.BuildRetention
+	var current batchv1.CronJob
+	exists := r.GetOrDie(buildRetentionIdent, &current)
+	if retention == nil {
+		if exists {
+			r.DeleteR(&current)
+		}
+		return
+	}
+
+	if retention.Archive {
+		pvc := base.MkPVC(buildArchiveIdent, r.Ns, r.getStorageConfOrDefault(retention.ArchiveStorage), apiv1.ReadWriteOnce)
+		r.GetOrCreate(&pvc)
+	}
+
+	cj := r.mkBuildRetentionCronJob(retention)
+	if !exists {
+		logging.LogI("Creating the database pruning cronjob")
+		r.CreateR(&cj)
+	} else if current.Annotations["spec-hash"] != cj.Annotations["spec-hash"] {
+		logging.LogI("Updating the database pruning cronjob")
+		current.Annotations = cj.Annotations
+		current.Spec = cj.Spec
+		r.UpdateR(&current)
+	}
+}
@@ -8,11 +8,21 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 )
 
+const zuulCapacityIdent = "zuul-capacity"
+
+// TerminateZuulCapacity removes the zuul-capacity service. The sidecar container is removed with the nodepool-launcher update.
+func (r *SFController) TerminateZuulCapacity() {
+	var srv apiv1.Service
+	if r.GetOrDie(zuulCapacityIdent, &srv) {
+		r.DeleteR(&srv)
+	}
+}
+
 func MkZuulCapacityContainer(
 	openshiftUser bool,
 	corporateCMExists bool,
 ) apiv1.Container {
-	container := base.MkContainer("zuul-capacity", base.ZuulCapacityImage(), openshiftUser)
+	container := base.MkContainer(zuulCapacityIdent, base.ZuulCapacityImage(), openshiftUser)
 	container.Args = []string{"--port", "9100"}
 	container.Env = []apiv1.EnvVar{
 		base.MkEnvVar("OS_CLIENT_CONFIG_FILE", "/.openstack/clouds.yaml"),
@@ -23,7 +33,9 @@ func MkZuulCapacityContainer(
 	container.ReadinessProbe = base.MkReadinessHTTPProbe("/", 9100)
 	container.VolumeMounts = []apiv1.VolumeMount{
 		{
+			// The configuration of every provider, including the ones of the launcher groups
 			Name:      "nodepool-config",
+			SubPath:   "all-providers",
 			MountPath: "/etc/nodepool",
 			ReadOnly:  true,
 		},
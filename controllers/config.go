@@ -131,6 +131,10 @@ func (r *SFController) SetupBaseSecrets(internalTenantSecretsVersion string) boo
 		}...)
 	}
 
+	if isLogserverS3(r.cr.Spec.Logserver) {
+		extraCmdVars = append(extraCmdVars, r.mkLogserverS3Env()...)
+	}
+
 	if !found {
 		logging.LogI("Creating base secret job")
 		r.CreateR(r.RunCommand(jobName, []string{"config-create-zuul-secrets"}, extraCmdVars))
@@ -190,6 +194,9 @@ func (r *SFController) SetupConfigJob() bool {
 		extraSettingsChecksum = utils.Checksum([]byte(
 			r.cr.Spec.ConfigRepositoryLocation.ClusterAPIURL + r.cr.Spec.ConfigRepositoryLocation.LogserverHost))[0:5]
 	}
+	if isLogserverS3(r.cr.Spec.Logserver) {
+		extraSettingsChecksum = utils.Checksum([]byte(extraSettingsChecksum + r.getLogserverS3Version()))[0:5]
+	}
 
 	var (
 		// We use the CM to store versions that can trigger internal tenant secrets update
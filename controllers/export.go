@@ -0,0 +1,132 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the reconstruction of the SoftwareFactory resource deployed in standalone mode.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	legacyyaml "gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+)
+
+// ReadStandaloneOwnerSF reconstructs the SoftwareFactory resource stored in the sf-standalone-owner ConfigMap.
+// The returned warnings report what could not be recovered: the ConfigMaps written by older versions store
+// the spec with the Go field names, which is decoded without the storage sizes, and without the resource name.
+func ReadStandaloneOwnerSF(cm corev1.ConfigMap) (sfv1.SoftwareFactory, []string, error) {
+	var sf sfv1.SoftwareFactory
+	warnings := []string{}
+	data, ok := cm.Data["spec"]
+	if !ok {
+		return sf, nil, fmt.Errorf("the %s ConfigMap has no spec", cm.Name)
+	}
+	if err := yaml.UnmarshalStrict([]byte(data), &sf.Spec); err != nil {
+		sf.Spec = sfv1.SoftwareFactorySpec{}
+		if legacyErr := legacyyaml.Unmarshal([]byte(data), &sf.Spec); legacyErr != nil {
+			return sf, nil, fmt.Errorf("unable to decode the spec of the %s ConfigMap: %w", cm.Name, err)
+		}
+		warnings = append(warnings, "The spec was stored by an older version, the storage sizes are not recovered")
+	}
+
+	sf.APIVersion = sfv1.GroupVersion.String()
+	sf.Kind = "SoftwareFactory"
+	sf.Name = cm.Annotations["sf-name"]
+	if sf.Name == "" {
+		sf.Name = "my-sf"
+		warnings = append(warnings, "The resource name is unknown, it is set to "+sf.Name)
+	}
+	if _, ok := cm.Annotations["last-reconcile"]; !ok {
+		warnings = append(warnings, "The deployment was never reconciled successfully, the spec is the one of the first deployment")
+	}
+	sf.Annotations = map[string]string{}
+	for _, key := range []string{"sf-operator-version", "last-reconcile", "sf-fqdn"} {
+		if value, ok := cm.Annotations[key]; ok {
+			sf.Annotations[key] = value
+		}
+	}
+	if _, ok := sf.Annotations["sf-fqdn"]; !ok {
+		sf.Annotations["sf-fqdn"] = sf.Spec.FQDN
+	}
+	return sf, warnings, nil
+}
+
+// flattenValue lists the leaves of a decoded JSON value by path
+func flattenValue(path string, value any, leaves map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, leaves)
+		}
+	case []any:
+		for i, child := range v {
+			flattenValue(path+"["+strconv.Itoa(i)+"]", child, leaves)
+		}
+	default:
+		encoded, _ := json.Marshal(v)
+		leaves[path] = string(encoded)
+	}
+}
+
+func flattenSpec(spec sfv1.SoftwareFactorySpec) (map[string]string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	leaves := map[string]string{}
+	flattenValue("", value, leaves)
+	return leaves, nil
+}
+
+// DiffSpec returns the differences between two specs, one line per changed attribute. The specs are compared
+// after their decoding, thus the formatting, the order of the keys and the notation of the quantities do not matter.
+func DiffSpec(before sfv1.SoftwareFactorySpec, after sfv1.SoftwareFactorySpec) ([]string, error) {
+	beforeLeaves, err := flattenSpec(before)
+	if err != nil {
+		return nil, err
+	}
+	afterLeaves, err := flattenSpec(after)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for path := range beforeLeaves {
+		paths = append(paths, path)
+	}
+	for path := range afterLeaves {
+		if _, ok := beforeLeaves[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	diff := []string{}
+	for _, path := range paths {
+		beforeValue, inBefore := beforeLeaves[path]
+		afterValue, inAfter := afterLeaves[path]
+		switch {
+		case !inBefore:
+			diff = append(diff, "+ "+path+": "+afterValue)
+		case !inAfter:
+			diff = append(diff, "- "+path+": "+beforeValue)
+		case beforeValue != afterValue:
+			diff = append(diff, "~ "+path+": "+beforeValue+" -> "+afterValue)
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	legacyyaml "gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+)
+
+func TestReadStandaloneOwnerSF(t *testing.T) {
+	spec := sfv1.SoftwareFactorySpec{FQDN: "sfop.me"}
+	spec.Logserver.Storage.Size = resource.MustParse("10Gi")
+	spec.Zuul.GerritConns = []sfv1.GerritConnection{{Name: "gerrit", Hostname: "review.sfop.me"}}
+
+	data, _ := yaml.Marshal(spec)
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: controllerCMName, Annotations: map[string]string{
+			"sf-name": "my-sf", "sf-fqdn": "sfop.me", "sf-operator-version": "v0.0.69", "last-reconcile": "1760000000",
+		}},
+		Data: map[string]string{"spec": string(data)},
+	}
+	sf, warnings, err := ReadStandaloneOwnerSF(cm)
+	if err != nil || len(warnings) > 0 {
+		t.Fatalf("Unexpected result: %v %v", warnings, err)
+	}
+	if sf.Name != "my-sf" || sf.Annotations["sf-operator-version"] != "v0.0.69" {
+		t.Errorf("Unexpected metadata: %v", sf.ObjectMeta)
+	}
+	if diff, _ := DiffSpec(spec, sf.Spec); len(diff) > 0 {
+		t.Errorf("The spec is not recovered: %v", diff)
+	}
+
+	// The ConfigMaps of the older versions have the Go field names
+	data, _ = legacyyaml.Marshal(spec)
+	cm = corev1.ConfigMap{Data: map[string]string{"spec": string(data)}}
+	sf, warnings, err = ReadStandaloneOwnerSF(cm)
+	if err != nil || len(warnings) != 3 {
+		t.Fatalf("Unexpected result: %v %v", warnings, err)
+	}
+	if sf.Spec.FQDN != "sfop.me" || sf.Spec.Zuul.GerritConns[0].Hostname != "review.sfop.me" {
+		t.Errorf("The legacy spec is not recovered: %v", sf.Spec)
+	}
+}
+
+func TestDiffSpec(t *testing.T) {
+	before := sfv1.SoftwareFactorySpec{FQDN: "sfop.me"}
+	before.Logserver.Storage.Size = resource.MustParse("1024Mi")
+	before.Zuul.GerritConns = []sfv1.GerritConnection{{Name: "gerrit", Hostname: "review.sfop.me"}}
+	after := before
+	after.Logserver.Storage.Size = resource.MustParse("1Gi")
+	after.FQDN = "sf.sfop.me"
+	after.Zuul.GerritConns = nil
+	after.Zuul.GitConns = []sfv1.GitConnection{{Name: "git", Baseurl: "https://git.sfop.me"}}
+
+	diff, err := DiffSpec(before, after)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []string{
+		`~ fqdn: "sfop.me" -> "sf.sfop.me"`,
+		`- zuul.gerritconns[0].hostname: "review.sfop.me"`,
+		`- zuul.gerritconns[0].name: "gerrit"`,
+		`+ zuul.gitconns[0].baseurl: "https://git.sfop.me"`,
+		`+ zuul.gitconns[0].name: "git"`,
+	}
+	if !slices.Equal(diff, expected) {
+		t.Errorf("Unexpected diff:\n%s", strings.Join(diff, "\n"))
+	}
+}
@@ -1,15 +1,22 @@
 // Copyright (C) 2024 Red Hat
 // SPDX-License-Identifier: Apache-2.0
 //
-// This package contains the git-server configuration.
+// This package contains the gateway configuration.
 
 package controllers
 
 import (
 	_ "embed"
 	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
 
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/conds"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
 	apiv1 "k8s.io/api/core/v1"
@@ -18,11 +25,230 @@ import (
 //go:embed static/gateway/gateway.conf
 var gatewayConfig string
 
+//go:embed static/gateway/index.html
+var gatewayIndex string
+
+//go:embed static/gateway/maintenance.html
+var gatewayMaintenance string
+
 func logCMError(cmName string) {
 	cmError := errors.New("ConfigMap missing: " + cmName)
 	logging.LogE(cmError, "Please create the configmap or remove it from your Software Factory manifest")
 }
 
+const (
+	gatewayPagesIdent = "gateway-pages"
+	gatewayPagesDir   = "/var/www/sf"
+	// The key of the pages ConfigMap enabling the maintenance page
+	gatewayMaintenanceFlag = "maintenance-enabled"
+)
+
+// gatewayService is a service listed on the landing page
+type gatewayService struct {
+	Name        string
+	Path        string
+	Description string
+}
+
+// gatewayAccessRule is an access rule, with the regular expression matching its path prefix
+type gatewayAccessRule struct {
+	sfv1.GatewayAccessRuleSpec
+	Pattern string
+}
+
+// gatewayConfigData holds the settings rendered in gateway.conf
+type gatewayConfigData struct {
+	FQDN            string
+	Codesearch      bool
+	LogJuicer       bool
+	Weeder          bool
+	ZuulCapacity    bool
+	NodepoolBuilder bool
+	LogsS3URL       string
+	AccessRules     []gatewayAccessRule
+	TrustedProxies  []string
+	LandingPage     bool
+	Maintenance     bool
+}
+
+func validateIPOrCIDR(value string) error {
+	if net.ParseIP(value) != nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		return fmt.Errorf("%s is not an IP address or a CIDR range", value)
+	}
+	return nil
+}
+
+// isGatewayPathRestricted tells if an access rule applies to the path or to a part of it
+func isGatewayPathRestricted(path string, rulePath string) bool {
+	path = strings.TrimSuffix(path, "/") + "/"
+	rulePath = strings.TrimSuffix(rulePath, "/") + "/"
+	return strings.HasPrefix(path, rulePath) || strings.HasPrefix(rulePath, path)
+}
+
+// ValidateGateway checks the gateway access rules
+func ValidateGateway(spec sfv1.SoftwareFactorySpec) error {
+	if spec.Gateway == nil {
+		return nil
+	}
+	for _, proxy := range spec.Gateway.TrustedProxies {
+		if err := validateIPOrCIDR(proxy); err != nil {
+			return fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+	}
+	seen := []string{}
+	for _, rule := range spec.Gateway.AccessRules {
+		if !strings.HasPrefix(rule.Path, "/") {
+			return fmt.Errorf("the access rule path %s must start with '/'", rule.Path)
+		}
+		if slices.Contains(seen, rule.Path) {
+			return fmt.Errorf("duplicate access rule for the path %s", rule.Path)
+		}
+		seen = append(seen, rule.Path)
+		if len(rule.AllowedIPs) == 0 {
+			return fmt.Errorf("the access rule of %s requires allowed IPs", rule.Path)
+		}
+		// The s3 bucket allows the anonymous downloads, a rule on the gateway would not protect the logs
+		if isLogserverS3(spec.Logserver) && isGatewayPathRestricted("/logs", rule.Path) {
+			return fmt.Errorf("the access rule of %s can not restrict the logs of the s3 backend, whose bucket is public", rule.Path)
+		}
+		for _, ip := range rule.AllowedIPs {
+			if err := validateIPOrCIDR(ip); err != nil {
+				return fmt.Errorf("invalid allowed IP for %s: %w", rule.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getGatewayServices returns the services served by the gateway
+func (r *SFController) getGatewayServices() []gatewayService {
+	services := []gatewayService{
+		{"Zuul", "/zuul/", "the CI system status, builds and jobs"},
+		{"Logs", "/logs/", "the build logs"},
+	}
+	if r.IsNodepoolBuilderEnabled() {
+		services = append(services, gatewayService{"Nodepool builds", "/nodepool/builds/", "the logs of the image builds"})
+	}
+	if r.IsCodesearchEnabled() {
+		services = append(services, gatewayService{"Codesearch", "/codesearch/", "search the code of the projects"})
+	}
+	if r.IsLogJuicerEnabled() {
+		services = append(services, gatewayService{"LogJuicer", "/logjuicer/", "analyze the build failures"})
+	}
+	if r.IsWeederEnabled() {
+		services = append(services, gatewayService{"Weeder", "/weeder/", "inspect the Zuul configuration"})
+	}
+	if r.IsZuulCapacityEnabled() {
+		services = append(services, gatewayService{"Zuul capacity", "/zuul-capacity/", "the cloud providers usage"})
+	}
+	return services
+}
+
+// mkGatewayPages renders the landing and maintenance pages
+func (r *SFController) mkGatewayPages() (map[string]string, error) {
+	pages := map[string]string{}
+	spec := r.cr.Spec.Gateway
+	if spec == nil {
+		return pages, nil
+	}
+	if spec.LandingPage != nil {
+		title := spec.LandingPage.Title
+		if title == "" {
+			title = r.cr.Spec.FQDN
+		}
+		index, err := utils.ParseString(gatewayIndex, struct {
+			Title    string
+			Banner   string
+			Services []gatewayService
+		}{title, spec.LandingPage.Banner, r.getGatewayServices()})
+		if err != nil {
+			return nil, err
+		}
+		pages["index.html"] = index
+	}
+	if spec.Maintenance != nil {
+		message := spec.Maintenance.Message
+		if message == "" {
+			message = "The services are being updated, please come back in a few minutes."
+		}
+		maintenance, err := utils.ParseString(gatewayMaintenance, struct {
+			FQDN    string
+			Message string
+		}{r.cr.Spec.FQDN, message})
+		if err != nil {
+			return nil, err
+		}
+		pages["maintenance.html"] = maintenance
+	}
+	return pages, nil
+}
+
+// gatewayCoreServices are the services without which the deployment is not usable. The other services,
+// such as codesearch or logjuicer, do not trigger the maintenance page when they are not ready.
+var gatewayCoreServices = []string{"GitServer", "MariaDB", "Logserver", "Zookeeper", "Zuul"}
+
+// isGatewayMaintenanceNeeded tells if the maintenance page is served: when a core service is not ready, or
+// while an upgrade, that is a reconcile by another operator version, is not complete.
+func (r *SFController) isGatewayMaintenanceNeeded(services map[string]bool) bool {
+	spec := r.cr.Spec.Gateway
+	if spec == nil || spec.Maintenance == nil {
+		return false
+	}
+	if spec.Maintenance.Always {
+		return true
+	}
+	for _, service := range gatewayCoreServices {
+		if !services[service] {
+			return true
+		}
+	}
+	reconciledBy := r.cr.Status.ReconciledBy
+	upgrading := reconciledBy != "" && reconciledBy != conds.GetOperatorConditionName()
+	return upgrading && !isOperatorReady(services)
+}
+
+// EnsureGatewayMaintenance sets the maintenance flag while the deployment is not ready. The flag is read by
+// the gateway from the pages volume, so the gateway is not restarted.
+func (r *SFController) EnsureGatewayMaintenance(services map[string]bool) {
+	var cm apiv1.ConfigMap
+	if !r.GetOrDie(gatewayPagesIdent+"-config-map", &cm) {
+		return
+	}
+	enabled := r.isGatewayMaintenanceNeeded(services)
+	_, current := cm.Data[gatewayMaintenanceFlag]
+	if enabled == current {
+		return
+	}
+	if enabled {
+		logging.LogI("Enabling the gateway maintenance page")
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[gatewayMaintenanceFlag] = "true"
+	} else {
+		logging.LogI("Disabling the gateway maintenance page")
+		delete(cm.Data, gatewayMaintenanceFlag)
+	}
+	r.UpdateR(&cm)
+}
+
+func mkGatewayAccessRules(spec *sfv1.GatewaySpec) []gatewayAccessRule {
+	rules := []gatewayAccessRule{}
+	if spec == nil {
+		return rules
+	}
+	for _, rule := range spec.AccessRules {
+		rules = append(rules, gatewayAccessRule{
+			GatewayAccessRuleSpec: rule,
+			Pattern:               regexp.QuoteMeta(strings.TrimSuffix(rule.Path, "/")),
+		})
+	}
+	return rules
+}
+
 func (r *SFController) DeployHTTPDGateway() bool {
 
 	const (
@@ -33,12 +259,57 @@ func (r *SFController) DeployHTTPDGateway() bool {
 	srv := base.MkService(ident, r.Ns, ident, []int32{port}, ident, r.cr.Spec.ExtraLabels)
 	r.GetOrCreate(&srv)
 
+	if err := ValidateGateway(r.cr.Spec); err != nil {
+		logging.LogE(err, "Invalid gateway access rules")
+		return false
+	}
+	var trustedProxies []string
+	landingPage, maintenance := false, false
+	if r.cr.Spec.Gateway != nil {
+		trustedProxies = r.cr.Spec.Gateway.TrustedProxies
+		landingPage = r.cr.Spec.Gateway.LandingPage != nil
+		maintenance = r.cr.Spec.Gateway.Maintenance != nil
+	}
+
+	pages, err := r.mkGatewayPages()
+	if err != nil {
+		logging.LogE(err, "Unable to render the gateway pages")
+		return false
+	}
+	// The maintenance flag is managed by EnsureGatewayMaintenance
+	var currentPages apiv1.ConfigMap
+	if r.GetOrDie(gatewayPagesIdent+"-config-map", &currentPages) {
+		if flag, ok := currentPages.Data[gatewayMaintenanceFlag]; ok && maintenance {
+			pages[gatewayMaintenanceFlag] = flag
+		}
+	}
+	r.EnsureConfigMap(gatewayPagesIdent, pages)
+
+	// Only proxy the enabled companion services
+	config, err := utils.ParseString(gatewayConfig, gatewayConfigData{
+		FQDN:            r.cr.Spec.FQDN,
+		Codesearch:      r.IsCodesearchEnabled(),
+		LogJuicer:       r.IsLogJuicerEnabled(),
+		Weeder:          r.IsWeederEnabled(),
+		ZuulCapacity:    r.IsZuulCapacityEnabled(),
+		NodepoolBuilder: r.IsNodepoolBuilderEnabled(),
+		LogsS3URL:       getLogsS3URL(r.cr.Spec.Logserver),
+		AccessRules:     mkGatewayAccessRules(r.cr.Spec.Gateway),
+		TrustedProxies:  trustedProxies,
+		LandingPage:     landingPage,
+		Maintenance:     maintenance,
+	})
+	if err != nil {
+		logging.LogE(err, "Unable to render the gateway configuration")
+		return false
+	}
 	r.EnsureConfigMap(ident, map[string]string{
-		"gateway.conf": gatewayConfig,
+		"gateway.conf": config,
 	})
 
 	volumes := []apiv1.Volume{
 		base.MkVolumeCM(ident, ident+"-config-map"),
+		base.MkVolumeCM(gatewayPagesIdent, gatewayPagesIdent+"-config-map"),
 	}
 	volumeMounts := []apiv1.VolumeMount{
 		{
@@ -47,12 +318,18 @@ func (r *SFController) DeployHTTPDGateway() bool {
 			ReadOnly:  true,
 			SubPath:   "gateway.conf",
 		},
+		// Not mounted with a subPath, so that the page updates are visible without a restart
+		{
+			Name:      gatewayPagesIdent,
+			MountPath: gatewayPagesDir,
+			ReadOnly:  true,
+		},
 	}
 
-	configHash := gatewayConfig
+	configHash := config
 
 	gatewaySpec := r.cr.Spec.Gateway
-	if gatewaySpec != nil {
+	if gatewaySpec != nil && gatewaySpec.ExtraConfigurationConfigMap != "" {
 		extraConfigCMName := gatewaySpec.ExtraConfigurationConfigMap
 		var extraConfigCM apiv1.ConfigMap
 		if !r.GetOrDie(extraConfigCMName, &extraConfigCM) {
@@ -0,0 +1,126 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
+)
+
+func TestGatewayAccessRules(t *testing.T) {
+	spec := sfv1.SoftwareFactorySpec{
+		FQDN: "sfop.me",
+		Gateway: &sfv1.GatewaySpec{
+			AccessRules: []sfv1.GatewayAccessRuleSpec{
+				{Path: "/weeder", AllowedIPs: []string{"10.0.0.0/8", "192.168.1.10"}},
+				{Path: "/zuul-capacity", AllowedIPs: []string{"192.168.1.10"}},
+			},
+			TrustedProxies: []string{"10.128.0.0/14"},
+		},
+	}
+	if err := ValidateGateway(spec); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	config, err := utils.ParseString(gatewayConfig, gatewayConfigData{
+		FQDN:           spec.FQDN,
+		AccessRules:    mkGatewayAccessRules(spec.Gateway),
+		TrustedProxies: spec.Gateway.TrustedProxies,
+	})
+	if err != nil {
+		t.Fatalf("Unable to render the gateway configuration: %s", err)
+	}
+	for _, expected := range []string{
+		"RemoteIPTrustedProxy 10.128.0.0/14",
+		"<LocationMatch \"^/weeder(/|$)\">\n    Require ip 10.0.0.0/8 192.168.1.10\n</LocationMatch>",
+		"<LocationMatch \"^/zuul-capacity(/|$)\">\n    Require ip 192.168.1.10\n</LocationMatch>",
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("%s is missing from the gateway configuration:\n%s", expected, config)
+		}
+	}
+
+	spec.Gateway.AccessRules = append(spec.Gateway.AccessRules, sfv1.GatewayAccessRuleSpec{Path: "/codesearch", AllowedIPs: []string{"not-an-ip"}})
+	if ValidateGateway(spec) == nil {
+		t.Errorf("An invalid allowed IP must be rejected")
+	}
+	spec.Gateway.AccessRules[2] = sfv1.GatewayAccessRuleSpec{Path: "/codesearch"}
+	if ValidateGateway(spec) == nil {
+		t.Errorf("An access rule without allowed IPs must be rejected")
+	}
+
+	spec.Logserver = sfv1.LogServerSpec{Backend: "s3"}
+	spec.Gateway.AccessRules = spec.Gateway.AccessRules[:2]
+	if err := ValidateGateway(spec); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	for _, path := range []string{"/logs", "/logs/periodic", "/"} {
+		spec.Gateway.AccessRules = []sfv1.GatewayAccessRuleSpec{{Path: path, AllowedIPs: []string{"10.0.0.0/8"}}}
+		if ValidateGateway(spec) == nil {
+			t.Errorf("The access rule of %s must be rejected with the public logs bucket", path)
+		}
+	}
+}
+
+func TestGatewayPages(t *testing.T) {
+	disabled := false
+	r := SFController{cr: sfv1.SoftwareFactory{Spec: sfv1.SoftwareFactorySpec{
+		FQDN:     "sfop.me",
+		Weeder:   sfv1.WeederSpec{Enabled: &disabled},
+		Nodepool: sfv1.NodepoolSpec{Builder: sfv1.NodepoolBuilderSpec{Enabled: &disabled}},
+		Gateway:  &sfv1.GatewaySpec{LandingPage: &sfv1.GatewayLandingPageSpec{Banner: "Upgrade <b>tonight</b>"}},
+	}}}
+	pages, err := r.mkGatewayPages()
+	if err != nil {
+		t.Fatalf("Unable to render the gateway pages: %s", err)
+	}
+	index := pages["index.html"]
+	for _, expected := range []string{"<title>sfop.me</title>", "Upgrade &lt;b&gt;tonight&lt;/b&gt;", `<a href="/codesearch/">`} {
+		if !strings.Contains(index, expected) {
+			t.Errorf("%s is missing from the landing page:\n%s", expected, index)
+		}
+	}
+	if strings.Contains(index, "/weeder/") {
+		t.Errorf("The disabled weeder is listed on the landing page")
+	}
+	if strings.Contains(index, "/nodepool/builds/") {
+		t.Errorf("The disabled nodepool-builder is listed on the landing page")
+	}
+	if _, ok := pages["maintenance.html"]; ok {
+		t.Errorf("The maintenance page is rendered without the maintenance setting")
+	}
+}
+
+func TestGatewayMaintenance(t *testing.T) {
+	r := SFController{cr: sfv1.SoftwareFactory{Spec: sfv1.SoftwareFactorySpec{
+		Gateway: &sfv1.GatewaySpec{Maintenance: &sfv1.GatewayMaintenanceSpec{}},
+	}}}
+	ready := map[string]bool{"GitServer": true, "MariaDB": true, "Logserver": true, "Zookeeper": true, "Zuul": true}
+	if r.isGatewayMaintenanceNeeded(ready) {
+		t.Errorf("The maintenance page is served while the deployment is ready")
+	}
+	ready["HoundSearch"] = false
+	if r.isGatewayMaintenanceNeeded(ready) {
+		t.Errorf("The maintenance page is served while only codesearch is not ready")
+	}
+	r.cr.Status.ReconciledBy = "sf-operator.v0.0.1"
+	if !r.isGatewayMaintenanceNeeded(ready) {
+		t.Errorf("The maintenance page is not served during an upgrade")
+	}
+	r.cr.Status.ReconciledBy = ""
+	ready["Zuul"] = false
+	if !r.isGatewayMaintenanceNeeded(ready) {
+		t.Errorf("The maintenance page is not served while Zuul is not ready")
+	}
+
+	config, err := utils.ParseString(gatewayConfig, gatewayConfigData{FQDN: "sfop.me", Maintenance: true})
+	if err != nil {
+		t.Fatalf("Unable to render the gateway configuration: %s", err)
+	}
+	if !strings.Contains(config, "RewriteCond %{REQUEST_URI} !^/zuul/api/\nRewriteRule ^ - [R=503,L]") {
+		t.Errorf("The Zuul API is not exempted from the maintenance mode:\n%s", config)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the maintenance jobs of the zuul-merger and zuul-executor git caches.
+
+package controllers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
+)
+
+//go:embed static/zuul/git-cache.py
+var zuulGitCacheScript string
+
+const gitCacheIdent = "zuul-git-cache"
+
+func (r *SFController) gitCacheLabels(service string) map[string]string {
+	labels := map[string]string{}
+	maps.Copy(labels, r.cr.Spec.ExtraLabels)
+	labels["app"] = "sf"
+	labels["run"] = gitCacheIdent
+	labels["zuul-service"] = service
+	return labels
+}
+
+// mkGitCachePodSpec returns a pod spec running the git-cache script on the volume of a statefulset replica.
+// The pod reuses the service container (zuul.conf, connections keys) and runs on the node of the replica,
+// since the volume can only be attached to a single node.
+func (r *SFController) mkGitCachePodSpec(sts appsv1.StatefulSet, ordinal int32, action string) apiv1.PodSpec {
+	service := sts.Name
+	podName := fmt.Sprintf("%s-%d", service, ordinal)
+	zuulWebURL := fmt.Sprintf("http://zuul-web:%d", zuulWEBPort)
+	if r.IsExternalExecutorEnabled() {
+		zuulWebURL = "https://" + r.cr.Spec.FQDN + "/zuul"
+	}
+
+	spec := *sts.Spec.Template.Spec.DeepCopy()
+	container := spec.Containers[0]
+	container.Name = gitCacheIdent
+	container.Command = []string{"python3", "/usr/local/bin/git-cache.py", action, service}
+	container.Args = nil
+	container.Ports = nil
+	container.ReadinessProbe = nil
+	container.LivenessProbe = nil
+	container.StartupProbe = nil
+	container.Env = append(container.Env, base.MkEnvVar("ZUUL_WEB_URL", zuulWebURL))
+	container.VolumeMounts = append(container.VolumeMounts, apiv1.VolumeMount{
+		Name:      gitCacheIdent,
+		SubPath:   "git-cache.py",
+		MountPath: "/usr/local/bin/git-cache.py",
+		ReadOnly:  true,
+	})
+	spec.Containers = []apiv1.Container{container}
+	spec.Volumes = append(spec.Volumes,
+		base.MkVolumeCM(gitCacheIdent, gitCacheIdent+"-config-map"),
+		apiv1.Volume{
+			Name: service,
+			VolumeSource: apiv1.VolumeSource{
+				PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{
+					ClaimName: service + "-" + podName,
+				},
+			},
+		})
+	spec.RestartPolicy = apiv1.RestartPolicyNever
+	spec.TerminationGracePeriodSeconds = nil
+	spec.Affinity = &apiv1.Affinity{
+		PodAffinity: &apiv1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"statefulset.kubernetes.io/pod-name": podName},
+				},
+				TopologyKey: "kubernetes.io/hostname",
+			}},
+		},
+	}
+	return spec
+}
+
+// ensureGitCacheWarmUp runs the warm-up Job once per volume. The Job name includes the volume UID, so
+// that a new Job runs when the volume is re-provisioned.
+func (r *SFController) ensureGitCacheWarmUp(sts appsv1.StatefulSet, ordinal int32) {
+	service := sts.Name
+	var pvc apiv1.PersistentVolumeClaim
+	if !r.GetOrDie(fmt.Sprintf("%s-%s-%d", service, service, ordinal), &pvc) {
+		return
+	}
+	prefix := fmt.Sprintf("%s-git-warmup-%d-", service, ordinal)
+	jobName := prefix + string(pvc.UID)[:8]
+
+	var jobs batchv1.JobList
+	r.ListOrDie(&jobs)
+	found := false
+	for _, job := range jobs.Items {
+		if job.Name == jobName {
+			found = true
+		} else if strings.HasPrefix(job.Name, prefix) {
+			// The job of a previous volume
+			r.DeleteR(&job)
+		}
+	}
+	if found {
+		return
+	}
+
+	labels := r.gitCacheLabels(service)
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: r.Ns,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](2),
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       r.mkGitCachePodSpec(sts, ordinal, "warmup"),
+			},
+		},
+	}
+	logging.LogI("Creating git cache warm-up job " + jobName)
+	r.CreateR(&job)
+}
+
+func (r *SFController) mkGitCacheGCCronJob(sts appsv1.StatefulSet, ordinal int32, schedule string) batchv1.CronJob {
+	labels := r.gitCacheLabels(sts.Name)
+	cj := batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-git-gc-%d", sts.Name, ordinal),
+			Namespace: r.Ns,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   schedule,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: ptr.To[int32](3),
+			FailedJobsHistoryLimit:     ptr.To[int32](3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To[int32](0),
+					Template: apiv1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       r.mkGitCachePodSpec(sts, ordinal, "gc"),
+					},
+				},
+			},
+		},
+	}
+	spec, _ := json.Marshal(cj.Spec)
+	cj.Annotations = map[string]string{"spec-hash": utils.Checksum(spec)}
+	return cj
+}
+
+// EnsureGitCache manages the warm-up Job and the gc CronJobs of the statefulset volumes.
+func (r *SFController) EnsureGitCache(sts *appsv1.StatefulSet, gitCache *sfv1.GitCacheSpec) {
+	if gitCache == nil {
+		gitCache = &sfv1.GitCacheSpec{}
+	}
+	service := sts.Name
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	if gitCache.WarmUp || gitCache.GCSchedule != "" {
+		r.EnsureConfigMap(gitCacheIdent, map[string]string{
+			"git-cache.py": zuulGitCacheScript,
+		})
+	}
+
+	wanted := map[string]batchv1.CronJob{}
+	for ordinal := range replicas {
+		if gitCache.WarmUp {
+			r.ensureGitCacheWarmUp(*sts, ordinal)
+		}
+		if gitCache.GCSchedule != "" {
+			cj := r.mkGitCacheGCCronJob(*sts, ordinal, gitCache.GCSchedule)
+			wanted[cj.Name] = cj
+		}
+	}
+
+	var cronJobs batchv1.CronJobList
+	r.ListOrDie(&cronJobs)
+	for _, current := range cronJobs.Items {
+		if current.Labels["run"] != gitCacheIdent || current.Labels["zuul-service"] != service {
+			continue
+		}
+		cj, ok := wanted[current.Name]
+		if !ok {
+			r.DeleteR(&current)
+			continue
+		}
+		delete(wanted, current.Name)
+		if current.Annotations["spec-hash"] != cj.Annotations["spec-hash"] {
+			logging.LogI("Updating git cache gc cronjob " + cj.Name)
+			current.Annotations = cj.Annotations
+			current.Spec = cj.Spec
+			r.UpdateR(&current)
+		}
+	}
+	for _, cj := range wanted {
+		logging.LogI("Creating git cache gc cronjob " + cj.Name)
+		r.CreateR(&cj)
+	}
+}
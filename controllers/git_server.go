@@ -68,6 +68,24 @@ func (r *SFController) MkPreInitScript() string {
 				return "gitlab"
 			}
 		}
+
+		for _, con := range r.cr.Spec.Zuul.GitHubConns {
+			if connName == con.Name {
+				return "github"
+			}
+		}
+
+		for _, con := range r.cr.Spec.Zuul.PagureConns {
+			if connName == con.Name {
+				return "pagure"
+			}
+		}
+
+		for _, con := range r.cr.Spec.Zuul.GitConns {
+			if connName == con.Name {
+				return "git"
+			}
+		}
 		// If not found, defaults to gerrit
 		return "gerrit"
 
@@ -92,10 +110,10 @@ func (r *SFController) MkPreInitScript() string {
 				Name:        "base",
 				Parent:      nil,
 				Description: "The base job.",
-				PreRun: []string{
+				PreRun: []interface{}{
 					"playbooks/base/pre.yaml",
 				},
-				PostRun: []string{
+				PostRun: []interface{}{
 					"playbooks/base/post.yaml",
 				},
 				Roles: []zuulcf.JobRoles{
@@ -142,83 +160,87 @@ func (r *SFController) MkPreInitScript() string {
 		},
 	}
 
-	// Check Pipeline
-	requireCheck, err := zuulcf.GetRequireCheckByDriver(driver, configRepoConnectionName)
-	if err != nil {
-		fmt.Println(err)
-	}
-	triggerCheck, err := zuulcf.GetTriggerCheckByDriver(driver, configRepoConnectionName)
-	if err != nil {
-		fmt.Println(err)
-	}
-	reportersCheck, err := zuulcf.GetReportersCheckByDriver(driver, configRepoConnectionName)
-	if err != nil {
-		fmt.Println(err)
-	}
-	// Gate Pipeline
-	requireGate, err := zuulcf.GetRequireGateByDriver(driver, configRepoConnectionName)
-	if err != nil {
-		fmt.Println(err)
-	}
-	triggerGate, err := zuulcf.GetTriggerGateByDriver(driver, configRepoConnectionName)
-	if err != nil {
-		fmt.Println(err)
-	}
-	reportersGate, err := zuulcf.GetReportersGateByDriver(driver, configRepoConnectionName)
-	if err != nil {
-		fmt.Println(err)
-	}
 	// Post Pipeline
 	triggerPost, err := zuulcf.GetTriggerPostByDriver(driver, configRepoConnectionName)
 	if err != nil {
 		fmt.Println(err)
 	}
 
-	pipelines := zuulcf.PipelineConfig{
-		{
-			Pipeline: zuulcf.PipelineBody{
-				Name:        "check",
-				Description: "Newly uploaded patchsets enter this pipeline to receive an initial +/-1 Verified vote.",
-				Manager:     zuulcf.Independent,
-				Require:     requireCheck,
-				Trigger:     triggerCheck,
-				Start:       reportersCheck[0],
-				Success:     reportersCheck[1],
-				Failure:     reportersCheck[2],
-			},
-		},
-		{
-			Pipeline: zuulcf.PipelineBody{
-				Name:           "gate",
-				Description:    "Changes that have been approved by core developers are enqueued in order in this pipeline, and if they pass tests, will be merged.",
-				SuccessMessage: "Build succeeded (gate pipeline).",
-				FailureMessage: "Build failed (gate pipeline).",
-				Manager:        zuulcf.Dependent,
-				Precedence:     zuulcf.High,
-				Supercedes: []string{
-					"check",
+	pipelines := zuulcf.PipelineConfig{}
+	// The post only drivers have no change to review, thus no check and gate pipelines
+	if !zuulcf.IsPostOnlyDriver(driver) {
+		// Check Pipeline
+		requireCheck, err := zuulcf.GetRequireCheckByDriver(driver, configRepoConnectionName)
+		if err != nil {
+			fmt.Println(err)
+		}
+		triggerCheck, err := zuulcf.GetTriggerCheckByDriver(driver, configRepoConnectionName)
+		if err != nil {
+			fmt.Println(err)
+		}
+		reportersCheck, err := zuulcf.GetReportersCheckByDriver(driver, configRepoConnectionName)
+		if err != nil {
+			fmt.Println(err)
+		}
+		// Gate Pipeline
+		requireGate, err := zuulcf.GetRequireGateByDriver(driver, configRepoConnectionName)
+		if err != nil {
+			fmt.Println(err)
+		}
+		triggerGate, err := zuulcf.GetTriggerGateByDriver(driver, configRepoConnectionName)
+		if err != nil {
+			fmt.Println(err)
+		}
+		reportersGate, err := zuulcf.GetReportersGateByDriver(driver, configRepoConnectionName)
+		if err != nil {
+			fmt.Println(err)
+		}
+		pipelines = zuulcf.PipelineConfig{
+			{
+				Pipeline: zuulcf.PipelineBody{
+					Name:        "check",
+					Description: "Newly uploaded patchsets enter this pipeline to receive an initial +/-1 Verified vote.",
+					Manager:     zuulcf.Independent,
+					Require:     requireCheck,
+					Trigger:     triggerCheck,
+					Start:       reportersCheck[0],
+					Success:     reportersCheck[1],
+					Failure:     reportersCheck[2],
 				},
-				PostReview:           true,
-				Require:              requireGate,
-				Trigger:              triggerGate,
-				Start:                reportersGate[0],
-				Success:              reportersGate[1],
-				Failure:              reportersGate[2],
-				WindowFloor:          20,
-				WindowIncreaseFactor: 2,
 			},
-		},
-		{
-			Pipeline: zuulcf.PipelineBody{
-				Name:        "post",
-				PostReview:  true,
-				Description: "This pipeline runs jobs that operate after each change is merged.",
-				Manager:     zuulcf.Supercedent,
-				Precedence:  zuulcf.Low,
-				Trigger:     triggerPost,
+			{
+				Pipeline: zuulcf.PipelineBody{
+					Name:           "gate",
+					Description:    "Changes that have been approved by core developers are enqueued in order in this pipeline, and if they pass tests, will be merged.",
+					SuccessMessage: "Build succeeded (gate pipeline).",
+					FailureMessage: "Build failed (gate pipeline).",
+					Manager:        zuulcf.Dependent,
+					Precedence:     zuulcf.High,
+					Supercedes: []string{
+						"check",
+					},
+					PostReview:           true,
+					Require:              requireGate,
+					Trigger:              triggerGate,
+					Start:                reportersGate[0],
+					Success:              reportersGate[1],
+					Failure:              reportersGate[2],
+					WindowFloor:          20,
+					WindowIncreaseFactor: 2,
+				},
 			},
-		},
+		}
 	}
+	pipelines = append(pipelines, zuulcf.Pipeline{
+		Pipeline: zuulcf.PipelineBody{
+			Name:        "post",
+			PostReview:  true,
+			Description: "This pipeline runs jobs that operate after each change is merged.",
+			Manager:     zuulcf.Supercedent,
+			Precedence:  zuulcf.Low,
+			Trigger:     triggerPost,
+		},
+	})
 
 	projects := zuulcf.ProjectConfig{
 		{
@@ -226,18 +248,18 @@ func (r *SFController) MkPreInitScript() string {
 				Name: configRepoName,
 				Pipeline: zuulcf.ZuulProjectPipelineMap{
 					"check": zuulcf.ZuulProjectPipeline{
-						Jobs: []string{
-							"config-check",
+						Jobs: []zuulcf.ProjectPipelineJob{
+							{Name: "config-check"},
 						},
 					},
 					"gate": zuulcf.ZuulProjectPipeline{
-						Jobs: []string{
-							"config-check",
+						Jobs: []zuulcf.ProjectPipelineJob{
+							{Name: "config-check"},
 						},
 					},
 					"post": zuulcf.ZuulProjectPipeline{
-						Jobs: []string{
-							"config-update",
+						Jobs: []zuulcf.ProjectPipelineJob{
+							{Name: "config-update"},
 						},
 					},
 				},
@@ -245,6 +267,11 @@ func (r *SFController) MkPreInitScript() string {
 		},
 	}
 
+	if zuulcf.IsPostOnlyDriver(driver) {
+		delete(projects[0].Project.Pipeline, "check")
+		delete(projects[0].Project.Pipeline, "gate")
+	}
+
 	semaphoreOutput, _ := yaml.Marshal(semaphore)
 	jobbaseOutput, _ := yaml.Marshal(jobs)
 	pipelineOutput, _ := yaml.Marshal(pipelines)
@@ -257,6 +284,9 @@ func (r *SFController) MkPreInitScript() string {
 	template = strings.Replace(template, "# JobsBase", string(jobbaseOutput), 1)
 	template = strings.Replace(template, "# Pipelines", string(pipelineOutput), 1)
 	template = strings.Replace(template, "# Projects", string(projectOutput), 1)
+	template = strings.Replace(template, "# NODEPOOL_GENERATE_CONFIG\n", r.generateConfigScript(), 1)
+	template = strings.Replace(template, "# NODEPOOL_LINT\n", nodepoolLintScript, 1)
+	template = strings.Replace(template, "# NODEPOOL_PROVIDERS\n", r.getNodepoolProvidersNamesYAML(), 1)
 
 	return strings.Replace(
 		template,
@@ -281,6 +311,12 @@ func (r *SFController) DeployGitServer() bool {
 		logserverHost = r.cr.Spec.ConfigRepositoryLocation.LogserverHost
 	}
 
+	logserverBackend := "pvc"
+	if isLogserverS3(r.cr.Spec.Logserver) {
+		logserverBackend = "s3"
+		annotations["logserver-backend"] = logserverBackend
+	}
+
 	if r.isConfigRepoSet() {
 		annotations["config-repo-name"] = r.cr.Spec.ConfigRepositoryLocation.Name
 		annotations["config-zuul-connection-name"] = r.cr.Spec.ConfigRepositoryLocation.ZuulConnectionName
@@ -338,6 +374,8 @@ func (r *SFController) DeployGitServer() bool {
 		base.MkEnvVar("FQDN", r.cr.Spec.FQDN),
 		base.MkEnvVar("ZUUL_LOGSERVER_HOST", logserverHost),
 		base.MkEnvVar("KUBERNETES_PUBLIC_API_URL", r.cr.Spec.ConfigRepositoryLocation.ClusterAPIURL),
+		base.MkEnvVar("LOGSERVER_BACKEND", logserverBackend),
+		base.MkEnvVar("NODEPOOL_BUILDER", strconv.FormatBool(r.IsNodepoolBuilderEnabled())),
 	}
 	initContainer.VolumeMounts = []apiv1.VolumeMount{
 		{
@@ -4,6 +4,10 @@
 package controllers
 
 import (
+	"encoding/json"
+	"slices"
+	"strconv"
+
 	v1 "github.com/softwarefactory-project/sf-operator/api/v1"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/conds"
@@ -54,6 +58,45 @@ func MkHoundSearchContainer(corporateCMExists bool, openshiftUser bool) apiv1.Co
 	return container
 }
 
+// mkHoundSearchSettings returns the repositories selection and the reindex interval read by hound-search-render.py
+func (r *SFController) mkHoundSearchSettings() string {
+	spec := r.cr.Spec.Codesearch
+	reindexInterval := spec.ReindexInterval
+	if reindexInterval == 0 {
+		reindexInterval = 720
+	}
+	settings, _ := json.Marshal(struct {
+		Repositories          []v1.CodesearchRepositoriesSpec `json:"repositories,omitempty"`
+		Branches              []v1.CodesearchBranchesSpec     `json:"branches,omitempty"`
+		ReindexInterval       int32                           `json:"reindexInterval"`
+		ConnectionCredentials bool                            `json:"connectionCredentials"`
+	}{spec.Repositories, spec.Branches, reindexInterval, spec.ConnectionCredentials})
+	return string(settings)
+}
+
+// mkHoundSearchAppKeys returns the volumes and mounts of the GitHub app keys, at the path set in zuul.conf
+func (r *SFController) mkHoundSearchAppKeys() ([]apiv1.Volume, []apiv1.VolumeMount) {
+	volumes := []apiv1.Volume{}
+	mounts := []apiv1.VolumeMount{}
+	if !r.cr.Spec.Codesearch.ConnectionCredentials {
+		return volumes, mounts
+	}
+	added := []string{}
+	for _, conn := range r.cr.Spec.Zuul.GitHubConns {
+		if conn.AppID > 0 && conn.Secrets != "" && !slices.Contains(added, conn.Secrets) {
+			volumes = append(volumes, base.MkVolumeSecret(conn.Secrets))
+			mounts = append(mounts, apiv1.VolumeMount{
+				Name:      conn.Secrets,
+				MountPath: "/var/lib/zuul/" + conn.Secrets + "/app_key",
+				SubPath:   "app_key",
+				ReadOnly:  true,
+			})
+			added = append(added, conn.Secrets)
+		}
+	}
+	return volumes, mounts
+}
+
 func (r *SFController) TerminateHoundSearch() {
 	r.DeleteR(&apiv1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -85,13 +128,19 @@ func (r *SFController) DeployHoundSearch() bool {
 	storage := r.getStorageConfOrDefault(r.cr.Spec.Codesearch.Storage)
 	pvc := base.MkPVC(houndSearchData, r.Ns, storage, apiv1.ReadWriteOnce)
 	container := MkHoundSearchContainer(corporateCMExists, r.IsOpenShift)
+	settings := r.mkHoundSearchSettings()
 	container.Env = []apiv1.EnvVar{
 		base.MkEnvVar("CONFIG_REPO_BASE_URL", r.configBaseURL),
 		base.MkEnvVar("CONFIG_REPO_NAME", r.cr.Spec.ConfigRepositoryLocation.Name),
+		base.MkEnvVar("CODESEARCH_SETTINGS", settings),
+		base.MkEnvVar("CODESEARCH_CREDENTIALS", strconv.FormatBool(r.cr.Spec.Codesearch.ConnectionCredentials)),
 	}
+	appKeyVolumes, appKeyMounts := r.mkHoundSearchAppKeys()
+	container.VolumeMounts = append(container.VolumeMounts, appKeyMounts...)
 	sts := base.MkStatefulset(houndSearchIdent, r.Ns, 1, houndSearchIdent, container, pvc, r.cr.Spec.ExtraLabels)
 	sts.Spec.Template.Spec.Volumes = AppendToolingVolume(sts.Spec.Template.Spec.Volumes)
 	sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, base.MkVolumeSecret("zuul-config"))
+	sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, appKeyVolumes...)
 
 	if corporateCMExists {
 		sts.Spec.Template.Spec.Volumes = append(
@@ -100,11 +149,16 @@ func (r *SFController) DeployHoundSearch() bool {
 			base.MkEmptyDirVolume("hound-search-ca"))
 	}
 
+	configHash := r.configBaseURL + r.cr.Spec.ConfigRepositoryLocation.Name + settings
+	for _, mount := range appKeyMounts {
+		configHash += mount.Name
+	}
+
 	annotations := map[string]string{
-		"config-hash":                utils.Checksum([]byte(r.configBaseURL + r.cr.Spec.ConfigRepositoryLocation.Name)),
+		"config-hash":                utils.Checksum([]byte(configHash)),
 		"corporate-ca-certs-version": getCMVersion(corporateCM, corporateCMExists),
 		"serial":                     "1",
-		"config-scripts":             utils.Checksum([]byte(houndSearchRender + houndSearchInit + houndSearchConfig)),
+		"config-scripts":             utils.Checksum([]byte(houndSearchRender + houndSearchInit + houndSearchConfig + houndSearchCredentials)),
 	}
 
 	limits := v1.LimitsSpec{
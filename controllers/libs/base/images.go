@@ -22,8 +22,14 @@ type ContainerImages struct {
 type Image struct {
 	Name      string `yaml:"name"`
 	Container string `yaml:"container"`
-	Version   string `yaml:"version"`
-	Source    string `yaml:"source,omitempty"`
+	// A tag, or a manifest digest like "sha256:..." for the images without immutable tags
+	Version string `yaml:"version"`
+	Source  string `yaml:"source,omitempty"`
+}
+
+// IsDigest tells if the image is pinned by its manifest digest
+func (image Image) IsDigest() bool {
+	return strings.HasPrefix(image.Version, "sha256:")
 }
 
 func loadImages() ContainerImages {
@@ -38,8 +44,10 @@ func getImage(name string) string {
 	images := loadImages()
 	for _, image := range images.Images {
 		if image.Name == name {
+			if image.IsDigest() {
+				return image.Container + "@" + image.Version
+			}
 			return image.Container + ":" + image.Version
-
 		}
 	}
 	panic("Unknown container image: " + name)
@@ -107,6 +115,14 @@ func MariaDBImage() string {
 	return getImage("mariadb")
 }
 
+func PostgreSQLImage() string {
+	return getImage("postgresql")
+}
+
+func MinioClientImage() string {
+	return getImage("minio-client")
+}
+
 func ZookeeperImage() string {
 	return getImage("zookeeper")
 }
@@ -30,6 +30,7 @@ type KubeClient struct {
 	RESTConfig  *rest.Config
 	ClientSet   *kubernetes.Clientset
 	Ns          string
+	KubeContext string
 	Ctx         context.Context
 	Cancel      context.CancelFunc
 	Owner       client.Object
@@ -57,16 +58,18 @@ func MkKubeClient(kubeconfig string, kubecontext string, namespace string, dryRu
 		CurrentContext: kubecontext,
 	})
 
-	// Discover the namespace
-	if namespace == "" {
-		rawConfig, err := config.RawConfig()
-		if err != nil {
-			return KubeClient{}, err
-		}
+	// Discover the namespace and record the context in use
+	if rawConfig, err := config.RawConfig(); err == nil {
 		if kubecontext == "" {
 			kubecontext = rawConfig.CurrentContext
 		}
-		namespace = rawConfig.Contexts[kubecontext].Namespace
+		if namespace == "" {
+			if kctx, ok := rawConfig.Contexts[kubecontext]; ok {
+				namespace = kctx.Namespace
+			}
+		}
+	} else if namespace == "" {
+		return KubeClient{}, err
 	}
 
 	// Setup clients
@@ -92,6 +95,7 @@ func MkKubeClient(kubeconfig string, kubecontext string, namespace string, dryRu
 		RESTConfig:  restconfig,
 		ClientSet:   clientset,
 		Ns:          namespace,
+		KubeContext: kubecontext,
 		Ctx:         ctx,
 		Cancel:      cancel,
 		Owner:       &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ""}},
@@ -0,0 +1,203 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package zuulcf
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// BuiltinTriggers are the pipeline triggers that do not need a connection
+var BuiltinTriggers = []string{"timer", "zuul"}
+
+// lintNames reports the items of a kind defined several times. The variants of a job are told apart by their branches.
+func lintNames(items []ConfigItem, kind string) []error {
+	errs := []error{}
+	seen := map[string]ConfigItem{}
+	for _, item := range items {
+		if item.Kind != kind {
+			continue
+		}
+		key := item.Name()
+		if job, ok := item.Body.(*JobBody); ok && len(job.Branches) > 0 {
+			branches := slices.Clone(job.Branches)
+			sort.Strings(branches)
+			key += " " + strings.Join(branches, ",")
+		}
+		if first, ok := seen[key]; ok {
+			errs = append(errs, ConfigError{item.Path, item.Line,
+				fmt.Sprintf("%s %s is already defined at %s:%d", kind, item.Name(), first.Path, first.Line)})
+			continue
+		}
+		seen[key] = item
+	}
+	return errs
+}
+
+// jobSemaphores returns the semaphores used by a job and by its playbooks
+func jobSemaphores(job *JobBody) []string {
+	semaphores := []string{}
+	if job.Semaphore != "" {
+		semaphores = append(semaphores, job.Semaphore)
+	}
+	for _, semaphore := range job.Semaphores {
+		semaphores = append(semaphores, semaphore.Name)
+	}
+	for _, playbooks := range []List[any]{job.PreRun, job.Run, job.PostRun, job.CleanupRun} {
+		for _, playbook := range playbooks {
+			attrs, ok := playbook.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch value := attrs["semaphores"].(type) {
+			case string:
+				semaphores = append(semaphores, value)
+			case []any:
+				for _, semaphore := range value {
+					if name, ok := semaphore.(string); ok {
+						semaphores = append(semaphores, name)
+					}
+				}
+			}
+		}
+	}
+	return semaphores
+}
+
+// jobNodeSets returns the nodesets referenced by name by a job
+func jobNodeSets(job *JobBody) []string {
+	if job.NodeSet == nil {
+		return nil
+	}
+	if job.NodeSet.Name != "" {
+		return []string{job.NodeSet.Name}
+	}
+	nodesets := []string{}
+	for _, alternative := range job.NodeSet.NodeSet.Alternatives {
+		nodesets = append(nodesets, string(alternative))
+	}
+	return nodesets
+}
+
+// pipelineConnections returns the connections used by a pipeline
+func pipelineConnections(pipeline *PipelineBody) []string {
+	connections := []string{}
+	for connection := range pipeline.Trigger {
+		if !slices.Contains(BuiltinTriggers, connection) {
+			connections = append(connections, connection)
+		}
+	}
+	for connection := range pipeline.Require {
+		connections = append(connections, connection)
+	}
+	for connection := range pipeline.Reject {
+		connections = append(connections, connection)
+	}
+	for _, reporter := range pipeline.Reporters() {
+		for connection := range reporter.Reporter {
+			connections = append(connections, connection)
+		}
+	}
+	sort.Strings(connections)
+	return slices.Compact(connections)
+}
+
+// projectJobs returns the jobs of the pipelines of a project or a project template, along with their variants
+func projectJobs(project *ZuulProjectBody) []*JobBody {
+	jobs := []*JobBody{}
+	for _, pipeline := range project.Pipeline {
+		for _, job := range pipeline.Jobs {
+			if job.Variant != nil {
+				jobs = append(jobs, job.Variant)
+			}
+		}
+	}
+	return jobs
+}
+
+// SortErrors sorts the errors by location, the errors that are not located first
+func SortErrors(errs []error) {
+	location := func(err error) (string, int) {
+		var configErr ConfigError
+		if errors.As(err, &configErr) {
+			return configErr.Path, configErr.Line
+		}
+		return "", 0
+	}
+	slices.SortStableFunc(errs, func(a, b error) int {
+		aPath, aLine := location(a)
+		bPath, bLine := location(b)
+		if c := strings.Compare(aPath, bPath); c != 0 {
+			return c
+		}
+		return aLine - bLine
+	})
+}
+
+// Lint checks the consistency of the loaded configuration items: the duplicated definitions, and the
+// nodesets and semaphores used by the jobs that are not defined. When connections is not nil,
+// the pipelines and the tenants sources using a connection missing from the list are reported.
+func Lint(items []ConfigItem, connections []string) []error {
+	errs := []error{}
+	for _, kind := range []string{"tenant", "job", "project-template", "pipeline", "nodeset", "semaphore", "secret", "queue"} {
+		errs = append(errs, lintNames(items, kind)...)
+	}
+
+	defined := map[string][]string{}
+	for _, item := range items {
+		kind := item.Kind
+		if kind == "global-semaphore" {
+			kind = "semaphore"
+		}
+		defined[kind] = append(defined[kind], item.Name())
+	}
+	checkJob := func(item ConfigItem, job *JobBody) {
+		for _, nodeset := range jobNodeSets(job) {
+			if !slices.Contains(defined["nodeset"], nodeset) {
+				errs = append(errs, ConfigError{item.Path, item.Line,
+					fmt.Sprintf("job %s uses the nodeset %s which is not defined", job.Name, nodeset)})
+			}
+		}
+		for _, semaphore := range jobSemaphores(job) {
+			if !slices.Contains(defined["semaphore"], semaphore) {
+				errs = append(errs, ConfigError{item.Path, item.Line,
+					fmt.Sprintf("job %s uses the semaphore %s which is not defined", job.Name, semaphore)})
+			}
+		}
+	}
+	checkConnection := func(item ConfigItem, connection string) {
+		if connections != nil && !slices.Contains(connections, connection) {
+			errs = append(errs, ConfigError{item.Path, item.Line,
+				fmt.Sprintf("%s %s uses the connection %s which is not defined in the SoftwareFactory resource", item.Kind, item.Name(), connection)})
+		}
+	}
+
+	for _, item := range items {
+		switch body := item.Body.(type) {
+		case *JobBody:
+			checkJob(item, body)
+		case *ZuulProjectBody:
+			for _, job := range projectJobs(body) {
+				checkJob(item, job)
+			}
+		case *PipelineBody:
+			for _, connection := range pipelineConnections(body) {
+				checkConnection(item, connection)
+			}
+		case *TenantBody:
+			sources := []string{}
+			for connection := range body.Source {
+				sources = append(sources, connection)
+			}
+			sort.Strings(sources)
+			for _, connection := range sources {
+				checkConnection(item, connection)
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package zuulcf
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const lintTestConfig = `
+- job:
+    name: base
+    parent: null
+    pre-run: playbooks/pre.yaml
+    nodeset: pod
+- job:
+    name: unit
+    runn: playbooks/unit.yaml
+- job:
+    name: publish
+    semaphores: publish
+- job:
+    name: publish
+- secret:
+    name: creds
+    data:
+      password: !encrypted/pkcs1-oaep
+        - c2VjcmV0
+- project:
+    check:
+      jobs:
+        - publish:
+            voting: false
+- pipeline:
+    name: check
+    manager: independent
+    trigger:
+      github:
+        - event: pull_request
+          action: opened
+      timer:
+        - time: '0 0 * * *'
+`
+
+func TestLoadConfig(t *testing.T) {
+	items, errs := LoadConfigData("jobs.yaml", []byte(lintTestConfig), false)
+	if len(errs) != 1 || errs[0].Error() != `jobs.yaml:9: job unit: unknown key "runn"` {
+		t.Errorf("Unexpected load errors: %v", errs)
+	}
+
+	out, err := yaml.Marshal(items)
+	if err != nil {
+		t.Fatalf("Unable to marshal the items: %s", err)
+	}
+	for _, expected := range []string{
+		"parent: null", "- playbooks/pre.yaml", "nodeset: pod", "!encrypted/pkcs1-oaep", "- publish:\n                voting: false",
+	} {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("Missing %q in the rendered configuration:\n%s", expected, out)
+		}
+	}
+	// A job without parent must not become a base job
+	if strings.Count(string(out), "parent:") != 1 {
+		t.Errorf("Unexpected parent in the rendered configuration:\n%s", out)
+	}
+
+	errs = Lint(items, []string{"gerrit"})
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	for _, expected := range []string{
+		"jobs.yaml:2: job base uses the nodeset pod which is not defined",
+		"jobs.yaml:10: job publish uses the semaphore publish which is not defined",
+		"jobs.yaml:13: job publish is already defined at jobs.yaml:10",
+		"jobs.yaml:25: pipeline check uses the connection github which is not defined in the SoftwareFactory resource",
+	} {
+		if !strings.Contains(strings.Join(messages, "\n"), expected) {
+			t.Errorf("Missing lint error %q in %v", expected, messages)
+		}
+	}
+	if len(errs) != 4 {
+		t.Errorf("Unexpected lint errors: %v", messages)
+	}
+}
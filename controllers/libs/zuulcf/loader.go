@@ -0,0 +1,373 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package zuulcf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// List is an attribute accepting either a single item or a list of items
+type List[T any] []T
+
+func (l *List[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		return decodeStrict(node, (*[]T)(l))
+	}
+	var item T
+	if err := decodeStrict(node, &item); err != nil {
+		return err
+	}
+	*l = List[T]{item}
+	return nil
+}
+
+// decodeStrict decodes a node, failing on the keys that are not defined by the target type.
+// The yaml.Node Decode method does not support it, so the node is encoded back first.
+func decodeStrict(node *yaml.Node, out any) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(out)
+}
+
+// removeKey removes a key from a mapping node, when the value matches
+func removeKey(node *yaml.Node, key string, match func(*yaml.Node) bool) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key && match(node.Content[i+1]) {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func hasKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+func anyValue(*yaml.Node) bool {
+	return true
+}
+
+func nullValue(node *yaml.Node) bool {
+	return node.Tag == "!!null"
+}
+
+type jobBody JobBody
+
+func (j JobBody) MarshalYAML() (interface{}, error) {
+	var node yaml.Node
+	if err := node.Encode(jobBody(j)); err != nil {
+		return nil, err
+	}
+	if j.parentUnset {
+		removeKey(&node, "parent", anyValue)
+	}
+	return &node, nil
+}
+
+func (j *JobBody) UnmarshalYAML(node *yaml.Node) error {
+	if err := decodeStrict(node, (*jobBody)(j)); err != nil {
+		return err
+	}
+	j.parentUnset = !hasKey(node, "parent")
+	return nil
+}
+
+// JobNodeSet is the nodeset of a job, either the name of a nodeset or an anonymous nodeset
+type JobNodeSet struct {
+	Name    string
+	NodeSet NodeSetBody
+}
+
+func (n JobNodeSet) MarshalYAML() (interface{}, error) {
+	if n.Name != "" {
+		return n.Name, nil
+	}
+	return n.NodeSet, nil
+}
+
+func (n *JobNodeSet) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		n.Name = node.Value
+		return nil
+	}
+	return decodeStrict(node, &n.NodeSet)
+}
+
+type jobRunNameAndSemaphore JobRunNameAndSemaphore
+
+// UnmarshalYAML accepts the name of a semaphore as well as a map
+func (s *JobRunNameAndSemaphore) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.Name = node.Value
+		return nil
+	}
+	return decodeStrict(node, (*jobRunNameAndSemaphore)(s))
+}
+
+// ProjectPipelineJob is a job of a project pipeline. Variant holds the attributes set on the job when
+// the job is given as a map.
+type ProjectPipelineJob struct {
+	Name    string
+	Variant *JobBody
+}
+
+func (j ProjectPipelineJob) MarshalYAML() (interface{}, error) {
+	if j.Variant == nil {
+		return j.Name, nil
+	}
+	var variant yaml.Node
+	if err := variant.Encode(j.Variant); err != nil {
+		return nil, err
+	}
+	removeKey(&variant, "name", anyValue)
+	removeKey(&variant, "parent", nullValue)
+	return map[string]*yaml.Node{j.Name: &variant}, nil
+}
+
+func (j *ProjectPipelineJob) UnmarshalYAML(node *yaml.Node) error {
+	switch {
+	case node.Kind == yaml.ScalarNode:
+		j.Name = node.Value
+		return nil
+	case node.Kind != yaml.MappingNode || len(node.Content) != 2:
+		return fmt.Errorf("line %d: a project pipeline job must be a job name or a map with a single job name", node.Line)
+	}
+	j.Name = node.Content[0].Value
+	if nullValue(node.Content[1]) {
+		return nil
+	}
+	j.Variant = &JobBody{}
+	if err := decodeStrict(node.Content[1], j.Variant); err != nil {
+		return err
+	}
+	j.Variant.Name = j.Name
+	return nil
+}
+
+type AdminRuleBody struct {
+	Name       string `yaml:"name"`
+	Conditions any    `yaml:"conditions"`
+}
+
+type APIRootBody struct {
+	AuthenticationRealm string       `yaml:"authentication-realm,omitempty"`
+	AccessRules         List[string] `yaml:"access-rules,omitempty"`
+}
+
+// tenantConfigKinds are the items of the tenants configuration file
+var tenantConfigKinds = map[string]func() any{
+	"tenant":             func() any { return &TenantBody{} },
+	"admin-rule":         func() any { return &AdminRuleBody{} },
+	"authorization-rule": func() any { return &AdminRuleBody{} },
+	"api-root":           func() any { return &APIRootBody{} },
+	"global-semaphore":   func() any { return &SemaphoreBody{} },
+}
+
+// projectConfigKinds are the items of the zuul.d configuration files of a project
+var projectConfigKinds = map[string]func() any{
+	"job":              func() any { return &JobBody{} },
+	"project":          func() any { return &ZuulProjectBody{} },
+	"project-template": func() any { return &ZuulProjectBody{} },
+	"pipeline":         func() any { return &PipelineBody{} },
+	"nodeset":          func() any { return &NodeSetBody{} },
+	"semaphore":        func() any { return &SemaphoreBody{} },
+	"secret":           func() any { return &SecretBody{} },
+	"queue":            func() any { return &QueueBody{} },
+	"pragma":           func() any { return &PragmaBody{} },
+}
+
+// ConfigItem is an item of a Zuul configuration file. Body is a pointer to the type of the item kind,
+// for instance a *JobBody for a job.
+type ConfigItem struct {
+	Path string
+	Line int
+	Kind string
+	Body any
+}
+
+func (i ConfigItem) MarshalYAML() (interface{}, error) {
+	return map[string]any{i.Kind: i.Body}, nil
+}
+
+// Name returns the name of the item, when its kind has one
+func (i ConfigItem) Name() string {
+	switch body := i.Body.(type) {
+	case *TenantBody:
+		return body.Name
+	case *AdminRuleBody:
+		return body.Name
+	case *SemaphoreBody:
+		return body.Name
+	case *JobBody:
+		return body.Name
+	case *ZuulProjectBody:
+		return body.Name
+	case *PipelineBody:
+		return body.Name
+	case *NodeSetBody:
+		return body.Name
+	case *SecretBody:
+		return body.Name
+	case *QueueBody:
+		return body.Name
+	}
+	return ""
+}
+
+// ConfigError is an error located in a Zuul configuration file
+type ConfigError struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e ConfigError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+}
+
+var unknownFieldRe = regexp.MustCompile(`^line \d+: field (.+) not found in type .*$`)
+var lineRe = regexp.MustCompile(`^line \d+: `)
+
+// findKeyLine returns the line of the first occurrence of a mapping key in a node
+func findKeyLine(node *yaml.Node, key string) int {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i].Line
+			}
+		}
+	}
+	for _, child := range node.Content {
+		if line := findKeyLine(child, key); line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+// mkDecodeErrors locates the errors of decodeStrict in the configuration file. The line numbers reported by
+// the decoder are relative to the encoded node, thus the unknown keys are searched in the node.
+func mkDecodeErrors(path string, kind string, name string, node *yaml.Node, err error) []error {
+	prefix := kind
+	if name != "" {
+		prefix += " " + name
+	}
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return []error{ConfigError{path, node.Line, prefix + ": " + strings.TrimPrefix(err.Error(), "yaml: ")}}
+	}
+	errs := []error{}
+	for _, msg := range typeErr.Errors {
+		line := node.Line
+		if m := unknownFieldRe.FindStringSubmatch(msg); m != nil {
+			if keyLine := findKeyLine(node, m[1]); keyLine != 0 {
+				line = keyLine
+			}
+			msg = "unknown key \"" + m[1] + "\""
+		}
+		errs = append(errs, ConfigError{path, line, prefix + ": " + lineRe.ReplaceAllString(msg, "")})
+	}
+	return errs
+}
+
+// LoadConfigData parses the items of a Zuul configuration file, allowing the kinds of tenantsConfig or not.
+// The errors are collected so that every problem of the file is reported.
+func LoadConfigData(path string, data []byte, tenantsConfig bool) ([]ConfigItem, []error) {
+	kinds := projectConfigKinds
+	if tenantsConfig {
+		kinds = tenantConfigKinds
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, []error{ConfigError{path, 0, strings.TrimPrefix(err.Error(), "yaml: ")}}
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return nil, []error{ConfigError{path, root.Line, "the configuration must be a list of items"}}
+	}
+	items := []ConfigItem{}
+	errs := []error{}
+	for _, node := range root.Content {
+		if node.Kind != yaml.MappingNode || len(node.Content) != 2 {
+			errs = append(errs, ConfigError{path, node.Line, "an item must be a map with a single key, the item kind"})
+			continue
+		}
+		kind := node.Content[0].Value
+		mkBody, ok := kinds[kind]
+		if !ok {
+			errs = append(errs, ConfigError{path, node.Line, "unknown item kind \"" + kind + "\""})
+			continue
+		}
+		item := ConfigItem{Path: path, Line: node.Line, Kind: kind, Body: mkBody()}
+		if err := decodeStrict(node.Content[1], item.Body); err != nil {
+			// The body is decoded as far as possible, the name tells the item in the errors
+			errs = append(errs, mkDecodeErrors(path, kind, item.Name(), node.Content[1], err)...)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, errs
+}
+
+// LoadConfigRepo parses the tenants configuration, zuul/main.yaml, and the zuul.d/ configuration files of a
+// config repository. The files that do not exist are skipped.
+func LoadConfigRepo(root string) ([]ConfigItem, []error) {
+	items := []ConfigItem{}
+	errs := []error{}
+	load := func(path string, tenantsConfig bool) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		fileItems, fileErrs := LoadConfigData(path, data, tenantsConfig)
+		items = append(items, fileItems...)
+		errs = append(errs, fileErrs...)
+	}
+
+	tenantsFile := filepath.Join(root, "zuul", "main.yaml")
+	if _, err := os.Stat(tenantsFile); err == nil {
+		load(tenantsFile, true)
+	}
+	paths := []string{}
+	err := filepath.WalkDir(filepath.Join(root, "zuul.d"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".yaml") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, err)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		load(path, false)
+	}
+	return items, errs
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
+	"gopkg.in/yaml.v3"
 )
 
 var TenantTemplate = `- tenant:
@@ -41,16 +42,36 @@ func GenerateZuulTemplateFile(tenant TenantTemplateStruct) (string, error) {
 	return templateConfig, nil
 }
 
+// TenantConnProjects lists the projects of a tenant source, either as project names
+// or as maps setting the project options
 type TenantConnProjects struct {
-	ConfigProjects    []string `yaml:"config-projects"`
-	UntrustedProjects []string `yaml:"untrusted-projects"`
+	ConfigProjects    []any `yaml:"config-projects"`
+	UntrustedProjects []any `yaml:"untrusted-projects"`
 }
 
 type TenantConnectionSource map[string]TenantConnProjects
 
 type TenantBody struct {
-	Name   string                 `yaml:"name"`
-	Source TenantConnectionSource `yaml:"source,omitempty"`
+	Name                       string                 `yaml:"name"`
+	Source                     TenantConnectionSource `yaml:"source,omitempty"`
+	MaxNodesPerJob             int                    `yaml:"max-nodes-per-job,omitempty"`
+	MaxJobTimeout              int                    `yaml:"max-job-timeout,omitempty"`
+	MaxDependencies            int                    `yaml:"max-dependencies,omitempty"`
+	MaxChangesPerPipeline      int                    `yaml:"max-changes-per-pipeline,omitempty"`
+	ExcludeUnprotectedBranches *bool                  `yaml:"exclude-unprotected-branches,omitempty"`
+	ExcludeLockedBranches      *bool                  `yaml:"exclude-locked-branches,omitempty"`
+	DefaultParent              string                 `yaml:"default-parent,omitempty"`
+	DefaultAnsibleVersion      string                 `yaml:"default-ansible-version,omitempty"`
+	AllowedTriggers            List[string]           `yaml:"allowed-triggers,omitempty"`
+	AllowedReporters           List[string]           `yaml:"allowed-reporters,omitempty"`
+	AllowedLabels              List[string]           `yaml:"allowed-labels,omitempty"`
+	DisallowedLabels           List[string]           `yaml:"disallowed-labels,omitempty"`
+	ReportBuildPage            *bool                  `yaml:"report-build-page,omitempty"`
+	WebRoot                    string                 `yaml:"web-root,omitempty"`
+	AdminRules                 List[string]           `yaml:"admin-rules,omitempty"`
+	AccessRules                List[string]           `yaml:"access-rules,omitempty"`
+	AuthenticationRealm        string                 `yaml:"authentication-realm,omitempty"`
+	Semaphores                 List[string]           `yaml:"semaphores,omitempty"`
 }
 
 type Tenant struct {
@@ -79,9 +100,10 @@ func GetZuulProjectMergeMode(mergemode string) ZuulProjectMergeMode {
 }
 
 type ZuulProjectPipeline struct {
-	Jobs     []string `yaml:"jobs,omitempty"`
-	Debug    bool     `yaml:"debug,omitempty"`
-	FailFast bool     `yaml:"fail-fast,omitempty"`
+	Jobs     []ProjectPipelineJob `yaml:"jobs,omitempty"`
+	Debug    bool                 `yaml:"debug,omitempty"`
+	FailFast bool                 `yaml:"fail-fast,omitempty"`
+	Queue    string               `yaml:"queue,omitempty"`
 }
 
 type ZuulProjectPipelineMap map[string]ZuulProjectPipeline
@@ -98,6 +120,7 @@ const (
 type ZuulProjectMergeMode string
 type ZuulProjectBody struct {
 	Name          string                 `yaml:"name,omitempty"`
+	Description   string                 `yaml:"description,omitempty"`
 	Templates     []string               `yaml:"templates,omitempty"`
 	DefaultBranch string                 `yaml:"default-branch,omitempty"`
 	MergeMode     ZuulProjectMergeMode   `yaml:"merge-mode,omitempty"`
@@ -112,6 +135,10 @@ type Project struct {
 
 type ProjectConfig []Project
 
+type ProjectTemplate struct {
+	ProjectTemplate ZuulProjectBody `yaml:"project-template"`
+}
+
 type JobSecrets struct {
 	Name         string `yaml:"name"`
 	Secret       string `yaml:"secret"`
@@ -121,8 +148,9 @@ type JobSecrets struct {
 type JobRoles map[string]string
 
 type JobRunNameAndSemaphore struct {
-	Name      string `yaml:"name"`
-	Semaphore string `yaml:"semaphore,omitempty"`
+	Name           string `yaml:"name"`
+	Semaphore      string `yaml:"semaphore,omitempty"`
+	ResourcesFirst bool   `yaml:"resources-first,omitempty"`
 }
 
 type JobRunName struct {
@@ -130,20 +158,58 @@ type JobRunName struct {
 }
 
 type JobBody struct {
-	Name        string                   `yaml:"name"`
-	Description string                   `yaml:"description,omitempty"`
-	ExtraVars   map[string]interface{}   `yaml:"extra-vars,omitempty"`
-	Parent      *string                  `yaml:"parent"`
-	PostRun     []string                 `yaml:"post-run,omitempty"`
-	PreRun      []string                 `yaml:"pre-run,omitempty"`
-	Roles       []JobRoles               `yaml:"roles,omitempty"`
-	Secrets     []interface{}            `yaml:"secrets,omitempty"`
-	Timeout     uint16                   `yaml:"timeout,omitempty"`
-	Attempts    uint8                    `yaml:"attempts,omitempty"`
-	Final       bool                     `yaml:"final,omitempty"`
-	Run         []interface{}            `yaml:"run,omitempty"`
-	NodeSet     NodeSetBody              `yaml:"nodeset,omitempty"`
-	Semaphores  []JobRunNameAndSemaphore `yaml:"semaphores,omitempty"`
+	Name                 string                       `yaml:"name"`
+	Description          string                       `yaml:"description,omitempty"`
+	VariantDescription   string                       `yaml:"variant-description,omitempty"`
+	ExtraVars            map[string]interface{}       `yaml:"extra-vars,omitempty"`
+	Parent               *string                      `yaml:"parent"`
+	PostRun              List[any]                    `yaml:"post-run,omitempty"`
+	PreRun               List[any]                    `yaml:"pre-run,omitempty"`
+	CleanupRun           List[any]                    `yaml:"cleanup-run,omitempty"`
+	Roles                []JobRoles                   `yaml:"roles,omitempty"`
+	Secrets              List[any]                    `yaml:"secrets,omitempty"`
+	Timeout              int                          `yaml:"timeout,omitempty"`
+	PostTimeout          int                          `yaml:"post-timeout,omitempty"`
+	Attempts             uint8                        `yaml:"attempts,omitempty"`
+	Final                bool                         `yaml:"final,omitempty"`
+	Protected            bool                         `yaml:"protected,omitempty"`
+	Abstract             bool                         `yaml:"abstract,omitempty"`
+	Intermediate         bool                         `yaml:"intermediate,omitempty"`
+	Voting               *bool                        `yaml:"voting,omitempty"`
+	HoldFollowingChanges bool                         `yaml:"hold-following-changes,omitempty"`
+	Run                  List[any]                    `yaml:"run,omitempty"`
+	NodeSet              *JobNodeSet                  `yaml:"nodeset,omitempty"`
+	Semaphore            string                       `yaml:"semaphore,omitempty"`
+	Semaphores           List[JobRunNameAndSemaphore] `yaml:"semaphores,omitempty"`
+	Vars                 map[string]any               `yaml:"vars,omitempty"`
+	HostVars             map[string]any               `yaml:"host-vars,omitempty"`
+	GroupVars            map[string]any               `yaml:"group-vars,omitempty"`
+	IncludeVars          List[any]                    `yaml:"include-vars,omitempty"`
+	RequiredProjects     List[any]                    `yaml:"required-projects,omitempty"`
+	Dependencies         List[any]                    `yaml:"dependencies,omitempty"`
+	AllowedProjects      List[string]                 `yaml:"allowed-projects,omitempty"`
+	PostReview           bool                         `yaml:"post-review,omitempty"`
+	Branches             List[string]                 `yaml:"branches,omitempty"`
+	Files                List[string]                 `yaml:"files,omitempty"`
+	IrrelevantFiles      List[string]                 `yaml:"irrelevant-files,omitempty"`
+	MatchOnConfigUpdates *bool                        `yaml:"match-on-config-updates,omitempty"`
+	Tags                 List[string]                 `yaml:"tags,omitempty"`
+	Provides             List[string]                 `yaml:"provides,omitempty"`
+	Requires             List[string]                 `yaml:"requires,omitempty"`
+	SuccessMessage       string                       `yaml:"success-message,omitempty"`
+	FailureMessage       string                       `yaml:"failure-message,omitempty"`
+	SuccessURL           string                       `yaml:"success-url,omitempty"`
+	FailureURL           string                       `yaml:"failure-url,omitempty"`
+	FailureOutput        List[string]                 `yaml:"failure-output,omitempty"`
+	WorkspaceScheme      string                       `yaml:"workspace-scheme,omitempty"`
+	WorkspaceCheckout    any                          `yaml:"workspace-checkout,omitempty"`
+	OverrideCheckout     string                       `yaml:"override-checkout,omitempty"`
+	OverrideBranch       string                       `yaml:"override-branch,omitempty"`
+	AnsibleVersion       string                       `yaml:"ansible-version,omitempty"`
+	AnsibleSplitStreams  *bool                        `yaml:"ansible-split-streams,omitempty"`
+	Deduplicate          any                          `yaml:"deduplicate,omitempty"`
+	// parentUnset is set when a loaded job does not define its parent, so that it is not rendered as a base job
+	parentUnset bool
 }
 
 type Job struct {
@@ -262,11 +328,10 @@ type PipelineRequireGerritApproval struct {
 }
 
 type PipelineGerritRequirement struct {
-	Username        string                          `yaml:"username,omitempty"`
-	CurrentPatchset bool                            `yaml:"current-patchset,omitempty"`
-	Verified        []GerritVotePoint               `yaml:"Verified,omitempty,flow"`
-	GerritApproval  []PipelineRequireGerritApproval `yaml:"approval,omitempty"`
-	Workflow        GerritWorkflow                  `yaml:"Workflow,omitempty"`
+	Username       string                          `yaml:"username,omitempty"`
+	Verified       []GerritVotePoint               `yaml:"Verified,omitempty,flow"`
+	GerritApproval []PipelineRequireGerritApproval `yaml:"approval,omitempty"`
+	Workflow       GerritWorkflow                  `yaml:"Workflow,omitempty"`
 }
 
 type PipelineGitLabRequirement struct {
@@ -275,32 +340,75 @@ type PipelineGitLabRequirement struct {
 	Labels   []string `yaml:"labels,omitempty"`
 }
 
+type PipelineGitHubReview struct {
+	Type       string `yaml:"type,omitempty"`
+	Permission string `yaml:"permission,omitempty"`
+}
+
+// PipelinePullRequestRequirement defines the requirements of the GitHub and Pagure pull requests
+type PipelinePullRequestRequirement struct {
+	Review []PipelineGitHubReview `yaml:"review,omitempty"`
+	Score  int                    `yaml:"score,omitempty"`
+}
+
+// PipelineRequireApproval holds the requirements of a pipeline on a connection. Loaded requirements are kept
+// as is in Loaded, since they depend on the connection driver.
 type PipelineRequireApproval struct {
-	Open   bool                      `yaml:"open,omitempty"`
-	Gerrit PipelineGerritRequirement `yaml:",inline,omitempty"`
-	Gitlab PipelineGitLabRequirement `yaml:",inline,omitempty"`
+	Open            bool                           `yaml:"open,omitempty"`
+	CurrentPatchset bool                           `yaml:"current-patchset,omitempty"`
+	Status          string                         `yaml:"status,omitempty"`
+	Gerrit          PipelineGerritRequirement      `yaml:",inline,omitempty"`
+	Gitlab          PipelineGitLabRequirement      `yaml:",inline,omitempty"`
+	PullRequest     PipelinePullRequestRequirement `yaml:",inline,omitempty"`
+	Loaded          *yaml.Node                     `yaml:"-"`
+}
+
+type pipelineRequireApproval PipelineRequireApproval
+
+func (r PipelineRequireApproval) MarshalYAML() (interface{}, error) {
+	if r.Loaded != nil {
+		return r.Loaded, nil
+	}
+	return pipelineRequireApproval(r), nil
+}
+
+func (r *PipelineRequireApproval) UnmarshalYAML(node *yaml.Node) error {
+	r.Loaded = node
+	return nil
 }
 
 type PipelineTriggerGitGerrit struct {
 	Approval []PipelineRequireApproval `yaml:"approval,omitempty"`
 }
 
-type GitGLMergeRequests string
+// PipelineTriggerAction is the action of a GitLab merge request, or of a GitHub or Pagure pull request event
+type PipelineTriggerAction string
 
 const (
-	Opened     GitGLMergeRequests = "opened"
-	Changed    GitGLMergeRequests = "changed"
-	Merged     GitGLMergeRequests = "merged"
-	Comment    GitGLMergeRequests = "comment"
-	Approved   GitGLMergeRequests = "approved"
-	Unapproved GitGLMergeRequests = "unapproved"
-	Labeled    GitGLMergeRequests = "labeled"
+	Opened      PipelineTriggerAction = "opened"
+	Changed     PipelineTriggerAction = "changed"
+	Reopened    PipelineTriggerAction = "reopened"
+	Merged      PipelineTriggerAction = "merged"
+	Comment     PipelineTriggerAction = "comment"
+	Approved    PipelineTriggerAction = "approved"
+	Unapproved  PipelineTriggerAction = "unapproved"
+	Labeled     PipelineTriggerAction = "labeled"
+	Submitted   PipelineTriggerAction = "submitted"
+	Rerequested PipelineTriggerAction = "rerequested"
+	StatusSet   PipelineTriggerAction = "status"
+	Thumbsup    PipelineTriggerAction = "thumbsup"
 )
 
 type PipelineTriggerGitLab struct {
-	Action   []GitGLMergeRequests `yaml:"action,omitempty"`
-	Labels   []string             `yaml:"labels,omitempty"`
-	Unlabels []string             `yaml:"unlabels,omitempty"`
+	Labels   []string `yaml:"labels,omitempty"`
+	Unlabels []string `yaml:"unlabels,omitempty"`
+}
+
+// PipelineTriggerPullRequest defines the GitHub and Pagure pull request events filters
+type PipelineTriggerPullRequest struct {
+	State  []string `yaml:"state,omitempty"`
+	Check  string   `yaml:"check,omitempty"`
+	Status string   `yaml:"status,omitempty"`
 }
 
 type PipelineTriggerGit struct {
@@ -309,16 +417,45 @@ type PipelineTriggerGit struct {
 	Username string                          `yaml:"username,omitempty"`
 }
 
+// PipelineTrigger is a trigger of a pipeline on a connection. Loaded triggers are kept as is in Loaded,
+// since their filters depend on the connection driver.
 type PipelineTrigger struct {
-	Event         string                `yaml:"event"`
-	Comment       string                `yaml:"comment,omitempty"`
-	Ref           []string              `yaml:"ref,omitempty"`
-	GitTrigger    PipelineTriggerGit    `yaml:",inline"`
-	GitLabTrigger PipelineTriggerGitLab `yaml:",inline"`
+	Event              string                     `yaml:"event"`
+	Action             []PipelineTriggerAction    `yaml:"action,omitempty"`
+	Comment            string                     `yaml:"comment,omitempty"`
+	Ref                []string                   `yaml:"ref,omitempty"`
+	GitTrigger         PipelineTriggerGit         `yaml:",inline"`
+	GitLabTrigger      PipelineTriggerGitLab      `yaml:",inline"`
+	PullRequestTrigger PipelineTriggerPullRequest `yaml:",inline"`
+	Loaded             *yaml.Node                 `yaml:"-"`
+}
+
+type pipelineTrigger PipelineTrigger
+
+func (t PipelineTrigger) MarshalYAML() (interface{}, error) {
+	if t.Loaded != nil {
+		return t.Loaded, nil
+	}
+	return pipelineTrigger(t), nil
+}
+
+func (t *PipelineTrigger) UnmarshalYAML(node *yaml.Node) error {
+	t.Loaded = node
+	return nil
 }
 
 type PipelineTriggerArray []PipelineTrigger
 
+// UnmarshalYAML accepts a single trigger as well as a list of triggers
+func (a *PipelineTriggerArray) UnmarshalYAML(node *yaml.Node) error {
+	var triggers List[PipelineTrigger]
+	if err := decodeStrict(node, &triggers); err != nil {
+		return err
+	}
+	*a = PipelineTriggerArray(triggers)
+	return nil
+}
+
 type PipelineGenericTrigger map[string]PipelineTriggerArray
 
 type PipelineGitLabReporter struct {
@@ -334,9 +471,41 @@ type PipelineGerritReporter struct {
 	Verified GerritVotePoint `yaml:"Verified"`
 }
 
+// PipelinePullRequestReporter reports on the GitHub and Pagure pull requests
+type PipelinePullRequestReporter struct {
+	Check   string `yaml:"check,omitempty"`
+	Status  string `yaml:"status,omitempty"`
+	Comment bool   `yaml:"comment"`
+	Merge   bool   `yaml:"merge,omitempty"`
+}
+
+// PipelineDriverReporter is the reporter of a pipeline on a connection. A loaded reporter is kept as is
+// in Loaded, since the connection driver is not known from the configuration.
 type PipelineDriverReporter struct {
-	Gitlab *PipelineGitLabReporter `yaml:",inline,omitempty"`
-	Gerrit *PipelineGerritReporter `yaml:",inline,omitempty"`
+	Gitlab      *PipelineGitLabReporter
+	Gerrit      *PipelineGerritReporter
+	PullRequest *PipelinePullRequestReporter
+	Loaded      *yaml.Node `yaml:"-"`
+}
+
+// MarshalYAML renders the reporter of the connection driver, since the reporters of the drivers share some keys
+func (r PipelineDriverReporter) MarshalYAML() (interface{}, error) {
+	switch {
+	case r.Gitlab != nil:
+		return r.Gitlab, nil
+	case r.Gerrit != nil:
+		return r.Gerrit, nil
+	case r.PullRequest != nil:
+		return r.PullRequest, nil
+	case r.Loaded != nil:
+		return r.Loaded, nil
+	}
+	return nil, nil
+}
+
+func (r *PipelineDriverReporter) UnmarshalYAML(node *yaml.Node) error {
+	r.Loaded = node
+	return nil
 }
 
 type ReporterMap map[string]PipelineDriverReporter
@@ -370,21 +539,55 @@ const (
 type PipelineRequire map[string]PipelineRequireApproval
 
 type PipelineBody struct {
-	Name                 string                 `yaml:"name"`
-	Description          string                 `yaml:"description,omitempty"`
-	Manager              PipelineManager        `yaml:"manager"`
-	PostReview           bool                   `yaml:"post-review,omitempty"`
-	Precedence           PipelinePrecedence     `yaml:"precedence,omitempty"`
-	Supercedes           []string               `yaml:"supercedes,omitempty"`
-	SuccessMessage       string                 `yaml:"success-message,omitempty"`
-	FailureMessage       string                 `yaml:"failure-message,omitempty"`
-	Require              PipelineRequire        `yaml:"require,omitempty"`
-	Start                PipelineReporter       `yaml:"start,omitempty"`
-	Success              PipelineReporter       `yaml:"success,omitempty"`
-	Failure              PipelineReporter       `yaml:"failure,omitempty"`
-	Trigger              PipelineGenericTrigger `yaml:"trigger,omitempty"`
-	WindowFloor          uint8                  `yaml:"window-floor,omitempty"`
-	WindowIncreaseFactor uint8                  `yaml:"window-increase-factor,omitempty"`
+	Name                            string                 `yaml:"name"`
+	Description                     string                 `yaml:"description,omitempty"`
+	Manager                         PipelineManager        `yaml:"manager"`
+	PostReview                      bool                   `yaml:"post-review,omitempty"`
+	Precedence                      PipelinePrecedence     `yaml:"precedence,omitempty"`
+	Supercedes                      List[string]           `yaml:"supercedes,omitempty"`
+	AllowOtherConnections           *bool                  `yaml:"allow-other-connections,omitempty"`
+	DequeueOnNewPatchset            *bool                  `yaml:"dequeue-on-new-patchset,omitempty"`
+	IgnoreDependencies              bool                   `yaml:"ignore-dependencies,omitempty"`
+	DisableAfterConsecutiveFailures int                    `yaml:"disable-after-consecutive-failures,omitempty"`
+	SuccessMessage                  string                 `yaml:"success-message,omitempty"`
+	FailureMessage                  string                 `yaml:"failure-message,omitempty"`
+	StartMessage                    string                 `yaml:"start-message,omitempty"`
+	EnqueueMessage                  string                 `yaml:"enqueue-message,omitempty"`
+	DequeueMessage                  string                 `yaml:"dequeue-message,omitempty"`
+	MergeConflictMessage            string                 `yaml:"merge-conflict-message,omitempty"`
+	MergeFailureMessage             string                 `yaml:"merge-failure-message,omitempty"`
+	NoJobsMessage                   string                 `yaml:"no-jobs-message,omitempty"`
+	FooterMessage                   string                 `yaml:"footer-message,omitempty"`
+	Require                         PipelineRequire        `yaml:"require,omitempty"`
+	Reject                          PipelineRequire        `yaml:"reject,omitempty"`
+	Enqueue                         PipelineReporter       `yaml:"enqueue,omitempty"`
+	Start                           PipelineReporter       `yaml:"start,omitempty"`
+	Success                         PipelineReporter       `yaml:"success,omitempty"`
+	Failure                         PipelineReporter       `yaml:"failure,omitempty"`
+	MergeConflict                   PipelineReporter       `yaml:"merge-conflict,omitempty"`
+	MergeFailure                    PipelineReporter       `yaml:"merge-failure,omitempty"`
+	NoJobs                          PipelineReporter       `yaml:"no-jobs,omitempty"`
+	Disabled                        PipelineReporter       `yaml:"disabled,omitempty"`
+	Dequeue                         PipelineReporter       `yaml:"dequeue,omitempty"`
+	ConfigError                     PipelineReporter       `yaml:"config-error,omitempty"`
+	Error                           PipelineReporter       `yaml:"error,omitempty"`
+	Trigger                         PipelineGenericTrigger `yaml:"trigger,omitempty"`
+	Window                          int                    `yaml:"window,omitempty"`
+	WindowCeiling                   int                    `yaml:"window-ceiling,omitempty"`
+	WindowFloor                     uint8                  `yaml:"window-floor,omitempty"`
+	WindowIncreaseType              string                 `yaml:"window-increase-type,omitempty"`
+	WindowIncreaseFactor            uint8                  `yaml:"window-increase-factor,omitempty"`
+	WindowDecreaseType              string                 `yaml:"window-decrease-type,omitempty"`
+	WindowDecreaseFactor            uint8                  `yaml:"window-decrease-factor,omitempty"`
+}
+
+// Reporters returns the reporters of the pipeline by action
+func (p PipelineBody) Reporters() map[string]PipelineReporter {
+	return map[string]PipelineReporter{
+		"enqueue": p.Enqueue, "start": p.Start, "success": p.Success, "failure": p.Failure,
+		"merge-conflict": p.MergeConflict, "merge-failure": p.MergeFailure, "no-jobs": p.NoJobs,
+		"disabled": p.Disabled, "dequeue": p.Dequeue, "config-error": p.ConfigError, "error": p.Error,
+	}
 }
 
 type Pipeline struct {
@@ -403,13 +606,13 @@ type AnsiblePlay struct {
 type AnsiblePlayBook []AnsiblePlay
 
 type NodeSetNodesBody struct {
-	Name  string `yaml:"name"`
-	Label string `yaml:"label"`
+	Name  List[string] `yaml:"name"`
+	Label string       `yaml:"label"`
 }
 
 type NodeSetNodesGroupBody struct {
-	Name  string `yaml:"name"`
-	Label string `yaml:"label"`
+	Name  string       `yaml:"name"`
+	Nodes List[string] `yaml:"nodes"`
 }
 
 type NodesSetAlternatives string
@@ -429,7 +632,7 @@ type NodeSets []NodeSet
 
 type SemaphoreBody struct {
 	Name string `yaml:"name"`
-	Max  int8   `yaml:"max,omitempty"`
+	Max  int    `yaml:"max,omitempty"`
 }
 
 type Semaphore struct {
@@ -438,6 +641,36 @@ type Semaphore struct {
 
 type Semaphores []Semaphore
 
+// SecretBody is a secret, its data is kept as is to preserve the encrypted values
+type SecretBody struct {
+	Name string    `yaml:"name"`
+	Data yaml.Node `yaml:"data"`
+}
+
+type Secret struct {
+	Secret SecretBody `yaml:"secret"`
+}
+
+type QueueBody struct {
+	Name                      string `yaml:"name"`
+	PerBranch                 bool   `yaml:"per-branch,omitempty"`
+	AllowCircularDependencies bool   `yaml:"allow-circular-dependencies,omitempty"`
+	DependenciesByTopic       bool   `yaml:"dependencies-by-topic,omitempty"`
+}
+
+type Queue struct {
+	Queue QueueBody `yaml:"queue"`
+}
+
+type PragmaBody struct {
+	ImpliedBranchMatchers *bool        `yaml:"implied-branch-matchers,omitempty"`
+	ImpliedBranches       List[string] `yaml:"implied-branches,omitempty"`
+}
+
+type Pragma struct {
+	Pragma PragmaBody `yaml:"pragma"`
+}
+
 func GetRequireCheckByDriver(driver string, connection string) (PipelineRequire, error) {
 	require := PipelineRequire{}
 
@@ -445,10 +678,8 @@ func GetRequireCheckByDriver(driver string, connection string) (PipelineRequire,
 	case "gerrit":
 		require = PipelineRequire{
 			connection: PipelineRequireApproval{
-				Open: true,
-				Gerrit: PipelineGerritRequirement{
-					CurrentPatchset: true,
-				},
+				Open:            true,
+				CurrentPatchset: true,
 			},
 		}
 	case "gitlab":
@@ -457,6 +688,12 @@ func GetRequireCheckByDriver(driver string, connection string) (PipelineRequire,
 				Open: true,
 			},
 		}
+	case "github", "pagure":
+		require = PipelineRequire{
+			connection: PipelineRequireApproval{
+				Open: true,
+			},
+		}
 	default:
 		return require, fmt.Errorf("check Pipeline Require: Driver of type \"%s\" is not supported", driver)
 	}
@@ -471,9 +708,9 @@ func GetRequireGateByDriver(driver string, connection string) (PipelineRequire,
 	case "gerrit":
 		require = PipelineRequire{
 			connection: PipelineRequireApproval{
-				Open: true,
+				Open:            true,
+				CurrentPatchset: true,
 				Gerrit: PipelineGerritRequirement{
-					CurrentPatchset: true,
 					GerritApproval: []PipelineRequireGerritApproval{
 						{
 							Workflow: GetGerritWorkflowValue("1"),
@@ -494,6 +731,32 @@ func GetRequireGateByDriver(driver string, connection string) (PipelineRequire,
 				},
 			},
 		}
+	case "github":
+		// The merge is also subject to the branch protection rules of the repository
+		require = PipelineRequire{
+			connection: PipelineRequireApproval{
+				Open:            true,
+				CurrentPatchset: true,
+				Status:          ".*:.*/check:success",
+				PullRequest: PipelinePullRequestRequirement{
+					Review: []PipelineGitHubReview{
+						{
+							Permission: "write",
+						},
+					},
+				},
+			},
+		}
+	case "pagure":
+		require = PipelineRequire{
+			connection: PipelineRequireApproval{
+				Open:   true,
+				Status: "success",
+				PullRequest: PipelinePullRequestRequirement{
+					Score: 1,
+				},
+			},
+		}
 	default:
 		return require, fmt.Errorf("gate Pipeline Require: Driver of type \"%s\" is not supported", driver)
 	}
@@ -524,20 +787,64 @@ func GetTriggerCheckByDriver(driver string, connection string) (PipelineGenericT
 			connection: PipelineTriggerArray{
 				{
 					Event: "gl_merge_request",
-					GitLabTrigger: PipelineTriggerGitLab{
-						Action: []GitGLMergeRequests{
-							GitGLMergeRequests(Comment),
-						},
+					Action: []PipelineTriggerAction{
+						Comment,
 					},
 					Comment: "(?i)^\\s*recheck\\s*$",
 				},
 				{
 					Event: "gl_merge_request",
-					GitLabTrigger: PipelineTriggerGitLab{
-						Action: []GitGLMergeRequests{
-							GitGLMergeRequests(Opened),
-							GitGLMergeRequests(Changed),
-						},
+					Action: []PipelineTriggerAction{
+						Opened,
+						Changed,
+					},
+				},
+			},
+		}
+	case "github":
+		trigger = PipelineGenericTrigger{
+			connection: PipelineTriggerArray{
+				{
+					Event: "pull_request",
+					Action: []PipelineTriggerAction{
+						Opened,
+						Changed,
+						Reopened,
+					},
+				},
+				{
+					Event: "pull_request",
+					Action: []PipelineTriggerAction{
+						Comment,
+					},
+					Comment: "(?i)^\\s*recheck\\s*$",
+				},
+				{
+					Event: "check_run",
+					Action: []PipelineTriggerAction{
+						Rerequested,
+					},
+					PullRequestTrigger: PipelineTriggerPullRequest{
+						Check: ".*/check:.*",
+					},
+				},
+			},
+		}
+	case "pagure":
+		trigger = PipelineGenericTrigger{
+			connection: PipelineTriggerArray{
+				{
+					Event: "pg_pull_request",
+					Action: []PipelineTriggerAction{
+						Comment,
+					},
+					Comment: "(?i)^\\s*recheck\\s*$",
+				},
+				{
+					Event: "pg_pull_request",
+					Action: []PipelineTriggerAction{
+						Opened,
+						Changed,
 					},
 				},
 			},
@@ -583,18 +890,16 @@ func GetTriggerGateByDriver(driver string, connection string) (PipelineGenericTr
 			connection: PipelineTriggerArray{
 				{
 					Event: "gl_merge_request",
-					GitLabTrigger: PipelineTriggerGitLab{
-						Action: []GitGLMergeRequests{
-							GitGLMergeRequests(Approved),
-						},
+					Action: []PipelineTriggerAction{
+						Approved,
 					},
 				},
 				{
 					Event: "gl_merge_request",
+					Action: []PipelineTriggerAction{
+						Labeled,
+					},
 					GitLabTrigger: PipelineTriggerGitLab{
-						Action: []GitGLMergeRequests{
-							GitGLMergeRequests(Labeled),
-						},
 						Labels: []string{
 							"workflow",
 						},
@@ -602,6 +907,60 @@ func GetTriggerGateByDriver(driver string, connection string) (PipelineGenericTr
 				},
 			},
 		}
+	case "github":
+		trigger = PipelineGenericTrigger{
+			connection: PipelineTriggerArray{
+				{
+					Event: "pull_request_review",
+					Action: []PipelineTriggerAction{
+						Submitted,
+					},
+					PullRequestTrigger: PipelineTriggerPullRequest{
+						State: []string{
+							"approved",
+						},
+					},
+				},
+				{
+					Event: "pull_request",
+					Action: []PipelineTriggerAction{
+						StatusSet,
+					},
+					PullRequestTrigger: PipelineTriggerPullRequest{
+						Status: ".*:.*/check:success",
+					},
+				},
+				{
+					Event: "check_run",
+					Action: []PipelineTriggerAction{
+						Rerequested,
+					},
+					PullRequestTrigger: PipelineTriggerPullRequest{
+						Check: ".*/gate:.*",
+					},
+				},
+			},
+		}
+	case "pagure":
+		trigger = PipelineGenericTrigger{
+			connection: PipelineTriggerArray{
+				{
+					Event: "pg_pull_request",
+					Action: []PipelineTriggerAction{
+						StatusSet,
+					},
+					PullRequestTrigger: PipelineTriggerPullRequest{
+						Status: "success",
+					},
+				},
+				{
+					Event: "pg_pull_request_review",
+					Action: []PipelineTriggerAction{
+						Thumbsup,
+					},
+				},
+			},
+		}
 	default:
 		return trigger, fmt.Errorf("gate Pipeline Trigger: Driver of type \"%s\" is not supported", driver)
 	}
@@ -639,6 +998,36 @@ func GetTriggerPostByDriver(driver string, connection string) (PipelineGenericTr
 				},
 			},
 		}
+	case "github":
+		trigger[connection] = PipelineTriggerArray{
+			{
+				Event: "push",
+				Ref: []string{
+					"^refs/heads/master$",
+					"^refs/heads/main$",
+				},
+			},
+		}
+	case "pagure":
+		trigger[connection] = PipelineTriggerArray{
+			{
+				Event: "pg_push",
+				Ref: []string{
+					"^refs/heads/master$",
+					"^refs/heads/main$",
+				},
+			},
+		}
+	case "git":
+		trigger[connection] = PipelineTriggerArray{
+			{
+				Event: "ref-updated",
+				Ref: []string{
+					"^refs/heads/master$",
+					"^refs/heads/main$",
+				},
+			},
+		}
 	default:
 		return trigger, fmt.Errorf("post Pipeline Trigger: Driver of type \"%s\" is not supported", driver)
 	}
@@ -646,6 +1035,22 @@ func GetTriggerPostByDriver(driver string, connection string) (PipelineGenericTr
 	return trigger, nil
 }
 
+// IsPostOnlyDriver tells if the driver only provides the post pipeline, the git driver having no change to review
+func IsPostOnlyDriver(driver string) bool {
+	return driver == "git"
+}
+
+// pullRequestReporter returns a reporter of the GitHub and Pagure pull requests
+func pullRequestReporter(connection string, reporter PipelinePullRequestReporter) PipelineReporter {
+	return PipelineReporter{
+		Reporter: ReporterMap{
+			connection: PipelineDriverReporter{
+				PullRequest: &reporter,
+			},
+		},
+	}
+}
+
 func GetReportersCheckByDriver(driver string, connection string) ([]PipelineReporter, error) {
 	reporters := []PipelineReporter{}
 	switch driver {
@@ -720,6 +1125,22 @@ func GetReportersCheckByDriver(driver string, connection string) ([]PipelineRepo
 					},
 				},
 			})
+	case "github":
+		reporters = append(reporters,
+			// Start
+			pullRequestReporter(connection, PipelinePullRequestReporter{Check: "in_progress"}),
+			// Success
+			pullRequestReporter(connection, PipelinePullRequestReporter{Check: "success"}),
+			// Failure
+			pullRequestReporter(connection, PipelinePullRequestReporter{Check: "failure"}))
+	case "pagure":
+		reporters = append(reporters,
+			// Start
+			pullRequestReporter(connection, PipelinePullRequestReporter{Status: "pending"}),
+			// Success
+			pullRequestReporter(connection, PipelinePullRequestReporter{Status: "success", Comment: true}),
+			// Failure
+			pullRequestReporter(connection, PipelinePullRequestReporter{Status: "failure", Comment: true}))
 	default:
 		reporters = append(reporters, PipelineReporter{},
 			PipelineReporter{},
@@ -807,6 +1228,22 @@ func GetReportersGateByDriver(driver string, connection string) ([]PipelineRepor
 					},
 				},
 			})
+	case "github":
+		reporters = append(reporters,
+			// Start
+			pullRequestReporter(connection, PipelinePullRequestReporter{Check: "in_progress"}),
+			// Success
+			pullRequestReporter(connection, PipelinePullRequestReporter{Check: "success", Merge: true}),
+			// Failure
+			pullRequestReporter(connection, PipelinePullRequestReporter{Check: "failure"}))
+	case "pagure":
+		reporters = append(reporters,
+			// Start
+			pullRequestReporter(connection, PipelinePullRequestReporter{Status: "pending"}),
+			// Success
+			pullRequestReporter(connection, PipelinePullRequestReporter{Status: "success", Comment: true, Merge: true}),
+			// Failure
+			pullRequestReporter(connection, PipelinePullRequestReporter{Status: "failure", Comment: true}))
 	default:
 		reporters = append(reporters, PipelineReporter{},
 			PipelineReporter{},
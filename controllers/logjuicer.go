@@ -4,10 +4,17 @@
 package controllers
 
 import (
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	logJuicerIdent   = "logjuicer"
+	logJuicerPVCName = "logjuicer-pvc"
 )
 
 func (r *SFController) AddCorporateCA(spec *apiv1.PodSpec) string {
@@ -29,16 +36,44 @@ func (r *SFController) AddCorporateCA(spec *apiv1.PodSpec) string {
 	}
 }
 
+func (r *SFController) getLogJuicerStorage() sfv1.StorageSpec {
+	return sfv1.StorageSpec{
+		Size:      r.cr.Spec.Logjuicer.Size,
+		ClassName: r.cr.Spec.Logjuicer.ClassName,
+	}
+}
+
+func (r *SFController) TerminateLogJuicer() {
+	r.DeleteR(&apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      logJuicerIdent,
+			Namespace: r.Ns,
+		},
+	})
+	r.DeleteR(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      logJuicerIdent,
+			Namespace: r.Ns,
+		},
+	})
+	r.DeleteR(&apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      logJuicerPVCName,
+			Namespace: r.Ns,
+		},
+	})
+}
+
 func (r *SFController) EnsureLogJuicer() bool {
 	const (
-		ident         = "logjuicer"
+		ident         = logJuicerIdent
 		port          = 3000
-		pvcName       = "logjuicer-pvc"
+		pvcName       = logJuicerPVCName
 		logJuicerData = "logjuicer-data"
 	)
 
 	// Ensure PVC exists
-	storage := r.getStorageConfOrDefault(r.cr.Spec.Logjuicer)
+	storage := r.getStorageConfOrDefault(r.getLogJuicerStorage())
 
 	pvc := base.MkPVC(pvcName, r.Ns, storage, apiv1.ReadWriteOnce)
 	r.GetOrCreate(&pvc)
@@ -49,7 +84,6 @@ func (r *SFController) EnsureLogJuicer() bool {
 
 	// Create Deployment
 	dep := base.MkDeployment(ident, r.Ns, base.LogJuicerImage(), r.cr.Spec.ExtraLabels, r.IsOpenShift)
-	dep.Spec.Template.Spec.Containers[0].ImagePullPolicy = "Always"
 
 	// Use PVC for logjuicer-data volume
 	dep.Spec.Template.Spec.Volumes = []apiv1.Volume{
@@ -83,11 +117,12 @@ func (r *SFController) EnsureLogJuicer() bool {
 		"config-hash": utils.Checksum([]byte(config)),
 		"serial":      "2",
 		"certs":       r.AddCorporateCA(&dep.Spec.Template.Spec),
+		"limits":      base.UpdateContainerLimit(r.cr.Spec.Logjuicer.Limits, &dep.Spec.Template.Spec.Containers[0]),
 	}
 	dep.Spec.Template.Spec.HostAliases = base.CreateHostAliases(r.cr.Spec.HostAliases)
 
 	// Reconcile deployment
-	pvcReadiness := r.reconcileExpandPVC(pvcName, r.cr.Spec.Logjuicer)
+	pvcReadiness := r.reconcileExpandPVC(pvcName, r.getLogJuicerStorage())
 	current, changed := r.ensureDeployment(dep, nil)
 	return !changed && r.IsDeploymentReady(current) && pvcReadiness
 }
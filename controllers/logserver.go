@@ -8,6 +8,7 @@ package controllers
 import (
 	_ "embed"
 	"encoding/base64"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -45,6 +46,82 @@ const logsDir = "/var/www/html/logs"
 //go:embed static/logserver/logserver.conf
 var logserverConf string
 
+//go:embed static/logserver/purge-logs.py
+var logserverPurgeLogs string
+
+const purgelogsStats = "purgelogs-stats"
+const purgelogsStatsDir = "/var/lib/purgelogs-stats"
+
+// getLogserverQuota returns the quota with its defaults, or nil when the quota is not set
+func getLogserverQuota(spec v1.LogServerSpec) *v1.LogServerQuotaSpec {
+	if spec.Quota == nil {
+		return nil
+	}
+	quota := *spec.Quota
+	if quota.HighWatermark == 0 {
+		quota.HighWatermark = 90
+	}
+	if quota.LowWatermark == 0 {
+		quota.LowWatermark = 80
+	}
+	if quota.CheckInterval == 0 {
+		quota.CheckInterval = 300
+	}
+	return &quota
+}
+
+// getLogserverRetentionDays returns the default logs retention
+func getLogserverRetentionDays(spec v1.LogServerSpec) int {
+	if spec.RetentionDays == 0 {
+		return 60
+	}
+	return spec.RetentionDays
+}
+
+// ValidateLogserver checks the logserver backend settings and that the low watermark is below the high watermark
+func ValidateLogserver(spec v1.LogServerSpec) error {
+	if isLogserverS3(spec) {
+		return validateLogserverS3(spec, getLogserverRetentionDays(spec))
+	}
+	quota := getLogserverQuota(spec)
+	if quota != nil && quota.LowWatermark >= quota.HighWatermark {
+		return fmt.Errorf("the low watermark (%d%%) must be lower than the high watermark (%d%%)",
+			quota.LowWatermark, quota.HighWatermark)
+	}
+	return nil
+}
+
+// mkPurgelogsCommand returns the purgelogs command. The purgelogs binary only supports the day retention, thus the
+// path retentions and the quota are handled by the purge-logs.py script, run with the python3 of the purgelogs image.
+func mkPurgelogsCommand(spec v1.LogServerSpec, retentionDays int, loopDelay int) []string {
+	if len(spec.PathRetentions) == 0 && getLogserverQuota(spec) == nil {
+		return []string{
+			"/usr/local/bin/purgelogs",
+			"--retention-days", strconv.Itoa(retentionDays),
+			"--loop", strconv.Itoa(loopDelay),
+			"--log-path-dir", logsDir,
+			"--debug",
+		}
+	}
+	command := []string{
+		"python3", "/conf/purge-logs.py",
+		"--retention-days", strconv.Itoa(retentionDays),
+		"--loop", strconv.Itoa(loopDelay),
+		"--log-path-dir", logsDir,
+		"--stats-dir", purgelogsStatsDir,
+	}
+	for _, retention := range spec.PathRetentions {
+		command = append(command, "--path-retention", fmt.Sprintf("%s=%d", retention.Path, retention.RetentionDays))
+	}
+	if quota := getLogserverQuota(spec); quota != nil {
+		command = append(command,
+			"--high-watermark", strconv.Itoa(quota.HighWatermark),
+			"--low-watermark", strconv.Itoa(quota.LowWatermark),
+			"--quota-interval", strconv.Itoa(quota.CheckInterval))
+	}
+	return command
+}
+
 type LogServerReconciler struct {
 	Client     client.Client
 	Scheme     *runtime.Scheme
@@ -54,6 +131,10 @@ type LogServerReconciler struct {
 
 func (r *SFController) DeployLogserver() bool {
 
+	if isLogserverS3(r.cr.Spec.Logserver) {
+		return r.DeployLogserverS3(getLogserverRetentionDays(r.cr.Spec.Logserver))
+	}
+
 	// This is the server key
 	r.EnsureSSHKeySecret("logserver-keys")
 	// This is the client key
@@ -63,6 +144,7 @@ func (r *SFController) DeployLogserver() bool {
 	cmData := make(map[string]string)
 	cmData["logserver.conf"] = logserverConf
 	cmData["run.sh"] = logserverRun
+	cmData["purge-logs.py"] = logserverPurgeLogs
 
 	lgEntryScriptName := logserverIdent + "-entrypoint.sh"
 	cmData[lgEntryScriptName] = logserverEntrypoint
@@ -131,6 +213,7 @@ func (r *SFController) DeployLogserver() bool {
 				},
 			},
 		},
+		base.MkEmptyDirVolume(purgelogsStats),
 	}
 
 	sts.Spec.Template.Spec.Containers[0].Ports = []apiv1.ContainerPort{
@@ -199,10 +282,7 @@ func (r *SFController) DeployLogserver() bool {
 
 	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, sshdContainer)
 
-	retentionDays := r.cr.Spec.Logserver.RetentionDays
-	if retentionDays == 0 {
-		retentionDays = 60
-	}
+	retentionDays := getLogserverRetentionDays(r.cr.Spec.Logserver)
 
 	loopDelay := r.cr.Spec.Logserver.LoopDelay
 	if loopDelay == 0 {
@@ -210,19 +290,22 @@ func (r *SFController) DeployLogserver() bool {
 	}
 
 	purgelogsContainer := base.MkContainer(purgelogIdent, base.PurgelogsImage(), r.IsOpenShift)
-	purgelogsContainer.Command = []string{
-		"/usr/local/bin/purgelogs",
-		"--retention-days",
-		strconv.Itoa(retentionDays),
-		"--loop", strconv.Itoa(loopDelay),
-		"--log-path-dir",
-		logsDir,
-		"--debug"}
+	purgelogsCommand := mkPurgelogsCommand(r.cr.Spec.Logserver, retentionDays, loopDelay)
+	purgelogsContainer.Command = purgelogsCommand
 	purgelogsContainer.VolumeMounts = []apiv1.VolumeMount{
 		{
 			Name:      logserverIdent,
 			MountPath: logsDir,
 		},
+		{
+			Name:      logserverIdent + "-config-vol",
+			MountPath: "/conf",
+			ReadOnly:  true,
+		},
+		{
+			Name:      purgelogsStats,
+			MountPath: purgelogsStatsDir,
+		},
 	}
 
 	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, purgelogsContainer)
@@ -236,6 +319,15 @@ func (r *SFController) DeployLogserver() bool {
 	}
 
 	statsExporter := sfmonitoring.MkNodeExporterSideCarContainer(logserverIdent, volumeMountsStatsExporter, r.IsOpenShift)
+	// Expose the purge statistics written by the purge-logs.py script
+	statsExporter.Args = append(statsExporter.Args,
+		"--collector.textfile",
+		"--collector.textfile.directory="+purgelogsStatsDir)
+	statsExporter.VolumeMounts = append(statsExporter.VolumeMounts, apiv1.VolumeMount{
+		Name:      purgelogsStats,
+		MountPath: purgelogsStatsDir,
+		ReadOnly:  true,
+	})
 	sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, statsExporter)
 
 	sts.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
@@ -244,9 +336,8 @@ func (r *SFController) DeployLogserver() bool {
 	}
 	sts.Spec.Template.ObjectMeta.Annotations["fqdn"] = r.cr.Spec.FQDN
 	sts.Spec.Template.ObjectMeta.Annotations["serial"] = "8"
-	sts.Spec.Template.ObjectMeta.Annotations["config-hash"] = utils.Checksum([]byte(logserverConf))
-	sts.Spec.Template.ObjectMeta.Annotations["purgeLogConfig"] = "retentionDays:" + strconv.Itoa(r.cr.Spec.Logserver.RetentionDays) +
-		" loopDelay:" + strconv.Itoa(r.cr.Spec.Logserver.LoopDelay)
+	sts.Spec.Template.ObjectMeta.Annotations["config-hash"] = utils.Checksum([]byte(logserverConf + logserverPurgeLogs))
+	sts.Spec.Template.ObjectMeta.Annotations["purgeLogConfig"] = utils.Checksum([]byte(strings.Join(purgelogsCommand, " ")))
 
 	sts.Spec.Template.Spec.HostAliases = base.CreateHostAliases(r.cr.Spec.HostAliases)
 
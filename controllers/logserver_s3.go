@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the S3 backend of the logserver.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+
+	v1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/conds"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
+)
+
+const logserverS3SetupIdent = "logserver-s3-setup"
+
+func isLogserverS3(spec v1.LogServerSpec) bool {
+	return spec.Backend == "s3"
+}
+
+// getS3Prefix converts a path retention glob to a bucket prefix. Only a trailing wildcard is supported.
+func getS3Prefix(path string) (string, error) {
+	prefix := strings.TrimSuffix(path, "*")
+	if strings.ContainsAny(prefix, "*?[") {
+		return "", fmt.Errorf("the path %s is not a prefix, only a trailing '*' is supported with the s3 backend", path)
+	}
+	return prefix, nil
+}
+
+// getLogsS3URL returns the URL of the logs bucket, served by the gateway
+func getLogsS3URL(spec v1.LogServerSpec) string {
+	if !isLogserverS3(spec) || spec.S3 == nil {
+		return ""
+	}
+	return strings.TrimSuffix(spec.S3.Endpoint, "/") + "/" + spec.S3.Bucket
+}
+
+// validateLogserverS3 checks the settings of the s3 backend. The retention is managed by bucket lifecycle
+// rules: when several rules match an object, the shortest expiration applies.
+func validateLogserverS3(spec v1.LogServerSpec, retentionDays int) error {
+	if spec.S3 == nil || spec.S3.Endpoint == "" || spec.S3.Bucket == "" || spec.S3.SecretName == "" {
+		return errors.New("the s3 backend requires the s3 endpoint, bucket and secretName settings")
+	}
+	if spec.Quota != nil {
+		return errors.New("the quota is not supported with the s3 backend")
+	}
+	for _, retention := range spec.PathRetentions {
+		if _, err := getS3Prefix(retention.Path); err != nil {
+			return err
+		}
+		if retention.RetentionDays > retentionDays {
+			return fmt.Errorf("the retention of %s (%d days) can not exceed the default retention (%d days) with the s3 backend",
+				retention.Path, retention.RetentionDays, retentionDays)
+		}
+	}
+	return nil
+}
+
+// mkLogserverS3SetupScript returns the script creating the bucket, allowing the anonymous downloads
+// for the gateway, and setting the retention as lifecycle rules
+func mkLogserverS3SetupScript(spec v1.LogServerSpec, retentionDays int) string {
+	bucket := "sflogs/" + spec.S3.Bucket
+	mb := "mc mb --ignore-existing " + bucket
+	if spec.S3.Region != "" {
+		mb = "mc mb --ignore-existing --region " + spec.S3.Region + " " + bucket
+	}
+	lines := []string{
+		"set -ex",
+		"mc alias set sflogs \"${S3_ENDPOINT}\" \"${S3_ACCESS_KEY}\" \"${S3_SECRET_KEY}\" --api S3v4 --path on",
+		mb,
+		"mc anonymous set download " + bucket,
+		"mc ilm rule rm --all --force " + bucket + " || true",
+		"mc ilm rule add --expire-days " + strconv.Itoa(retentionDays) + " " + bucket,
+	}
+	for _, retention := range spec.PathRetentions {
+		prefix, _ := getS3Prefix(retention.Path)
+		lines = append(lines, fmt.Sprintf("mc ilm rule add --prefix '%s' --expire-days %d %s", prefix, retention.RetentionDays, bucket))
+	}
+	lines = append(lines, "mc ilm rule ls "+bucket)
+	return strings.Join(lines, "\n")
+}
+
+// terminateLogserverPVC removes the logserver statefulset and service. The volume is kept for the migration of the logs.
+func (r *SFController) terminateLogserverPVC() {
+	var sts appsv1.StatefulSet
+	if r.GetOrDie(logserverIdent, &sts) {
+		logging.LogI("The logserver uses the s3 backend, deleting the logserver statefulset")
+		r.DeleteR(&sts)
+	}
+	var svc apiv1.Service
+	if r.GetOrDie(logserverIdent, &svc) {
+		r.DeleteR(&svc)
+	}
+}
+
+// DeployLogserverS3 ensures the logs bucket is set up
+func (r *SFController) DeployLogserverS3(retentionDays int) bool {
+	spec := r.cr.Spec.Logserver
+	r.terminateLogserverPVC()
+
+	var secret apiv1.Secret
+	if !r.GetOrDie(spec.S3.SecretName, &secret) {
+		logging.LogE(errors.New("missing secret "+spec.S3.SecretName), "Unable to set up the logs bucket")
+		conds.UpdateConditions(&r.cr.Status.Conditions, logserverIdent, false)
+		return false
+	}
+
+	script := mkLogserverS3SetupScript(spec, retentionDays)
+	jobName := logserverS3SetupIdent + "-" + utils.Checksum([]byte(script + spec.S3.Endpoint + secret.ResourceVersion))[0:8]
+
+	// Remove the jobs of the previous settings
+	var jobList batchv1.JobList
+	r.ListOrDie(&jobList)
+	for _, job := range jobList.Items {
+		if strings.HasPrefix(job.Name, logserverS3SetupIdent+"-") && job.Name != jobName {
+			r.DeleteR(&job)
+		}
+	}
+
+	ready := false
+	var job batchv1.Job
+	if !r.GetOrDie(jobName, &job) {
+		logging.LogI("Setting up the logs bucket " + spec.S3.Bucket)
+		container := base.MkContainer(logserverS3SetupIdent, base.MinioClientImage(), r.IsOpenShift)
+		container.Command = []string{"bash", "-c", script}
+		container.Env = []apiv1.EnvVar{
+			base.MkEnvVar("HOME", "/tmp"),
+			base.MkEnvVar("S3_ENDPOINT", spec.S3.Endpoint),
+			base.MkSecretEnvVar("S3_ACCESS_KEY", spec.S3.SecretName, "access_key"),
+			base.MkSecretEnvVar("S3_SECRET_KEY", spec.S3.SecretName, "secret_key"),
+		}
+		job = base.MkJob(jobName, r.Ns, container, r.cr.Spec.ExtraLabels)
+		job.Spec.Template.Spec.HostAliases = base.CreateHostAliases(r.cr.Spec.HostAliases)
+		r.CreateR(&job)
+	} else if job.Status.Succeeded >= 1 {
+		ready = true
+	} else if job.Status.Failed >= 1 {
+		logging.LogE(errors.New("job "+jobName+" failed"), "Unable to set up the logs bucket, check the job logs")
+	} else {
+		logging.LogI("Waiting for the logs bucket setup")
+	}
+
+	conds.UpdateConditions(&r.cr.Status.Conditions, logserverIdent, ready)
+	return ready
+}
+
+// mkLogserverS3Env returns the bucket settings used to create the site_sflogs Zuul secret
+func (r *SFController) mkLogserverS3Env() []apiv1.EnvVar {
+	s3 := r.cr.Spec.Logserver.S3
+	return []apiv1.EnvVar{
+		base.MkEnvVar("LOGSERVER_BACKEND", "s3"),
+		base.MkEnvVar("S3_ENDPOINT", s3.Endpoint),
+		base.MkEnvVar("S3_BUCKET", s3.Bucket),
+		base.MkEnvVar("S3_REGION", s3.Region),
+		base.MkSecretEnvVar("S3_ACCESS_KEY", s3.SecretName, "access_key"),
+		base.MkSecretEnvVar("S3_SECRET_KEY", s3.SecretName, "secret_key"),
+	}
+}
+
+// getLogserverS3Version returns a version of the bucket settings, to update the site_sflogs Zuul secret when they change
+func (r *SFController) getLogserverS3Version() string {
+	s3 := r.cr.Spec.Logserver.S3
+	var secret apiv1.Secret
+	r.GetOrDie(s3.SecretName, &secret)
+	return s3.Endpoint + s3.Bucket + s3.Region + secret.ResourceVersion
+}
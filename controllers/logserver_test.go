@@ -5,6 +5,11 @@ package controllers
 
 import (
 	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
 	"time"
 
 	//nolint:golint
@@ -69,3 +74,166 @@ var _ = Describe("Logserver controller", func() {
 		Expect(sts.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(logserverCRIOAnnotationKey, logserverCRIOAnnotationValue))
 	})
 })
+
+func TestPurgelogsCommand(t *testing.T) {
+	spec := sfv1.LogServerSpec{
+		PathRetentions: []sfv1.LogServerPathRetentionSpec{{Path: "periodic/*", RetentionDays: 120}},
+		Quota:          &sfv1.LogServerQuotaSpec{HighWatermark: 95},
+	}
+	command := strings.Join(mkPurgelogsCommand(spec, 60, 3600), " ")
+	for _, expected := range []string{
+		"--retention-days 60",
+		"--path-retention periodic/*=120",
+		"--high-watermark 95 --low-watermark 80 --quota-interval 300",
+	} {
+		if !strings.Contains(command, expected) {
+			t.Errorf("%s is missing from the purgelogs command: %s", expected, command)
+		}
+	}
+	if !strings.HasPrefix(command, "python3 /conf/purge-logs.py ") {
+		t.Errorf("The path retentions and the quota are not handled by the purge script: %s", command)
+	}
+	command = strings.Join(mkPurgelogsCommand(sfv1.LogServerSpec{}, 60, 3600), " ")
+	if command != "/usr/local/bin/purgelogs --retention-days 60 --loop 3600 --log-path-dir "+logsDir+" --debug" {
+		t.Errorf("The purgelogs binary is not used without path retentions nor quota: %s", command)
+	}
+	spec.Quota.LowWatermark = 95
+	if ValidateLogserver(spec) == nil {
+		t.Errorf("The low watermark must be lower than the high watermark")
+	}
+}
+
+// purgeLogsDriver runs the purge script with a volume sized twice the initial size of the logs,
+// so that the quota does not depend on the filesystem running the test
+const purgeLogsDriver = `
+import os, runpy, sys
+
+root = sys.argv[1]
+
+def used():
+    return sum(os.lstat(os.path.join(d, f)).st_blocks * 512 for d, _, fs in os.walk(root) for f in fs)
+
+capacity = 2 * used()
+
+class Usage:
+    f_frsize = 1
+    f_blocks = capacity
+    @property
+    def f_bavail(self):
+        return capacity - used()
+
+os.statvfs = lambda path: Usage()
+script = sys.argv[2]
+sys.argv = sys.argv[2:]
+runpy.run_path(script, run_name="__main__")
+`
+
+func runPurgeLogs(t *testing.T, dir string, spec sfv1.LogServerSpec, files map[string]int) {
+	logs := filepath.Join(dir, "logs")
+	for path, days := range files {
+		path = filepath.Join(logs, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, 16384), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := filepath.Join(dir, "purge-logs.py")
+	if err := os.WriteFile(script, []byte(logserverPurgeLogs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	command := mkPurgelogsCommand(spec, 60, 0)
+	for i, arg := range command {
+		switch arg {
+		case "/conf/purge-logs.py":
+			command[i] = script
+		case logsDir:
+			command[i] = logs
+		case purgelogsStatsDir:
+			command[i] = dir
+		}
+	}
+	args := append([]string{"-c", purgeLogsDriver, logs}, command[1:]...)
+	if out, err := exec.Command(command[0], args...).CombinedOutput(); err != nil {
+		t.Fatalf("The purge failed: %s\n%s", err, out)
+	}
+}
+
+func checkPurgedLogs(t *testing.T, dir string, files map[string]bool) {
+	for path, kept := range files {
+		_, err := os.Stat(filepath.Join(dir, "logs", path))
+		if kept && err != nil {
+			t.Errorf("%s is deleted", path)
+		}
+		if !kept && err == nil {
+			t.Errorf("%s is not deleted", path)
+		}
+	}
+}
+
+func TestPurgeLogsScript(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not available")
+	}
+
+	dir := t.TempDir()
+	spec := sfv1.LogServerSpec{
+		PathRetentions: []sfv1.LogServerPathRetentionSpec{{Path: "periodic/*", RetentionDays: 120}},
+	}
+	runPurgeLogs(t, dir, spec, map[string]int{
+		"check/old/job-output.txt":    70,
+		"check/recent/job-output.txt": 10,
+		"periodic/old/job-output.txt": 70,
+		"periodic/older/job-output":   130,
+	})
+	checkPurgedLogs(t, dir, map[string]bool{
+		"check/old":                   false,
+		"check/recent/job-output.txt": true,
+		"periodic/old/job-output.txt": true,
+		"periodic/older":              false,
+	})
+	stats, _ := os.ReadFile(filepath.Join(dir, "purgelogs.prom"))
+	if !strings.Contains(string(stats), `logserver_purge_deleted_files_total{reason="retention"} 2`) {
+		t.Errorf("Unexpected purge statistics: %s", stats)
+	}
+
+	// The volume is half full, the quota deletes the oldest logs until the usage is below 30%
+	dir = t.TempDir()
+	spec = sfv1.LogServerSpec{
+		Quota: &sfv1.LogServerQuotaSpec{HighWatermark: 40, LowWatermark: 30},
+	}
+	runPurgeLogs(t, dir, spec, map[string]int{"1": 5, "2": 4, "3": 3, "4": 2, "5": 1})
+	checkPurgedLogs(t, dir, map[string]bool{"1": false, "2": false, "3": true, "4": true, "5": true})
+	stats, _ = os.ReadFile(filepath.Join(dir, "purgelogs.prom"))
+	if !strings.Contains(string(stats), `logserver_purge_deleted_files_total{reason="quota"} 2`) {
+		t.Errorf("Unexpected purge statistics: %s", stats)
+	}
+}
+
+func TestValidateLogserverS3(t *testing.T) {
+	spec := sfv1.LogServerSpec{
+		Backend:        "s3",
+		RetentionDays:  30,
+		S3:             &sfv1.LogServerS3Spec{Endpoint: "http://minio:9000", Bucket: "sf-logs", SecretName: "logserver-s3"},
+		PathRetentions: []sfv1.LogServerPathRetentionSpec{{Path: "periodic/*", RetentionDays: 7}},
+	}
+	if err := ValidateLogserver(spec); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if script := mkLogserverS3SetupScript(spec, 30); !strings.Contains(script, "--prefix 'periodic/' --expire-days 7") {
+		t.Errorf("The path retention rule is missing from the setup script: %s", script)
+	}
+	spec.PathRetentions[0].RetentionDays = 90
+	if ValidateLogserver(spec) == nil {
+		t.Errorf("A path retention longer than the default retention must be rejected")
+	}
+	spec.PathRetentions[0] = sfv1.LogServerPathRetentionSpec{Path: "*/gate/*", RetentionDays: 7}
+	if ValidateLogserver(spec) == nil {
+		t.Errorf("A path retention which is not a prefix must be rejected")
+	}
+}
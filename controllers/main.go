@@ -18,6 +18,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
 )
 
 var setupLog = ctrl.Log.WithName("setup")
@@ -73,13 +74,46 @@ func Standalone(cliNS string, kubeContext string, dryRun bool, crPath string, re
 			os.Exit(1)
 		}
 		env.EnsureStandaloneOwner(sf.Spec)
-		if err := env.setupRemoteExecutorConfig(copyFrom, sf); err != nil {
+		if env.KubeContext == "" {
+			fmt.Printf("%s: Unable to resolve the kube context of the remote executor, set it with --kube-context\n", remotePath)
+			os.Exit(1)
+		}
+		target := MkRemoteExecutorTarget(kubeConfig, env.KubeContext, env.Ns)
+		if err := env.setupRemoteExecutorConfig(copyFrom, sf, target); err != nil {
 			ctrl.Log.Error(err, "unable to setup remote executor config")
 			os.Exit(1)
 		}
 	}
 
-	return env.StandaloneReconcile(sf)
+	if err := env.StandaloneReconcile(sf); err != nil {
+		return err
+	}
+
+	if copyFrom == "" && len(sf.Spec.Zuul.Executor.Pools) > 0 {
+		return env.DeployExecutorPools(sf, kubeConfig)
+	}
+	return nil
+}
+
+// DeployExecutorPools reconciles the executor pools of the control plane and reports their status.
+func (r *SFKubeContext) DeployExecutorPools(sf sfv1.SoftwareFactory, kubeConfig string) error {
+	statuses := r.ReconcileExecutorPools(sf, kubeConfig)
+	notReady := []string{}
+	for _, status := range statuses {
+		if status.Ready {
+			ctrl.Log.Info("Executor pool ready", "pool", status.Name, "zone", status.Zone, "replicas", status.Replicas)
+		} else {
+			ctrl.Log.Info("Executor pool not ready", "pool", status.Name, "zone", status.Zone, "message", status.Message)
+			notReady = append(notReady, status.Name)
+		}
+	}
+	if err := r.recordExecutorPoolsStatus(statuses); err != nil {
+		ctrl.Log.Error(err, "Unable to record the executor pools status")
+	}
+	if len(notReady) > 0 {
+		return fmt.Errorf("executor pools not ready: %v", notReady)
+	}
+	return nil
 }
 
 func RotateSecrets(cliNS string, kubeContext string, dryRun bool, crPath string) error {
@@ -111,5 +145,10 @@ func RotateSecrets(cliNS string, kubeContext string, dryRun bool, crPath string)
 	if err := env.DoRotateSecrets(); err != nil {
 		return err
 	}
-	return env.StandaloneReconcile(sf)
+	if err := env.StandaloneReconcile(sf); err != nil {
+		return err
+	}
+
+	logging.LogI("Pushing the rotated secrets to the remote executors...")
+	return env.SyncRemoteExecutors(sf, kubeConfig)
 }
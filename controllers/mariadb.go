@@ -7,10 +7,15 @@ package controllers
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-sql-driver/mysql"
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/conds"
 	logging "github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
@@ -27,6 +32,8 @@ const (
 	mariaDBPortName    = "mariadb-port"
 	zuulDBConfigSecret = "zuul-db-connection"
 	MariadbAdminPass   = "mariadb-root-password"
+	postgresqlPort     = 5432
+	externalDBCAPath   = "/etc/zuul-db-tls"
 )
 
 //go:embed static/mariadb/fluentbit/fluent-bit.conf.tmpl
@@ -150,15 +157,218 @@ func mkDSN(password string) string {
 	return config.FormatDSN()
 }
 
+func getExternalDBDriver(spec *sfv1.ExternalDatabaseSpec) string {
+	if spec.Driver == "" {
+		return "mysql"
+	}
+	return spec.Driver
+}
+
+func getExternalDBCAKey(spec *sfv1.ExternalDatabaseSpec) string {
+	if spec.TLSCA.Key == "" {
+		return "ca.crt"
+	}
+	return spec.TLSCA.Key
+}
+
+// parseExternalDSN converts the connection URL of an external database into the zuul-db-connection secret data
+func parseExternalDSN(spec *sfv1.ExternalDatabaseSpec, dsn string) (map[string][]byte, error) {
+	dsn = strings.TrimSpace(dsn)
+	if !strings.Contains(dsn, "://") {
+		dsn = "//" + dsn
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	password, _ := u.User.Password()
+	database := strings.TrimPrefix(u.Path, "/")
+	if u.Hostname() == "" || u.User.Username() == "" || database == "" {
+		return nil, errors.New("the dsn must be of the form user:password@host:port/database")
+	}
+	driver := getExternalDBDriver(spec)
+	port := u.Port()
+	if port == "" {
+		port = strconv.Itoa(mariadbPort)
+		if driver == "postgresql" {
+			port = strconv.Itoa(postgresqlPort)
+		}
+	}
+
+	var clientDSN string
+	if driver == "postgresql" {
+		clientURL := url.URL{
+			Scheme: "postgresql",
+			User:   url.UserPassword(u.User.Username(), password),
+			Host:   net.JoinHostPort(u.Hostname(), port),
+			Path:   "/" + database,
+		}
+		clientDSN = clientURL.String()
+	} else {
+		config := mysql.NewConfig()
+		config.Net = "tcp"
+		config.Addr = net.JoinHostPort(u.Hostname(), port)
+		config.User = u.User.Username()
+		config.Passwd = password
+		config.DBName = database
+		clientDSN = config.FormatDSN()
+	}
+	return map[string][]byte{
+		"driver":   []byte(driver),
+		"username": []byte(u.User.Username()),
+		"password": []byte(password),
+		"host":     []byte(u.Hostname()),
+		"port":     []byte(port),
+		"database": []byte(database),
+		"dsn":      []byte(clientDSN),
+	}, nil
+}
+
+// mkZuulDBURI returns the SQLAlchemy URL used by zuul to connect to the database
+func mkZuulDBURI(spec *sfv1.ExternalDatabaseSpec, dbSettings apiv1.Secret) string {
+	if spec == nil {
+		return fmt.Sprintf(
+			"mariadb+pymysql://%s:%s@%s/%s", dbSettings.Data["username"], dbSettings.Data["password"], dbSettings.Data["host"], dbSettings.Data["database"])
+	}
+	dbURI := url.URL{
+		Scheme: "mysql+pymysql",
+		User:   url.UserPassword(string(dbSettings.Data["username"]), string(dbSettings.Data["password"])),
+		Host:   net.JoinHostPort(string(dbSettings.Data["host"]), string(dbSettings.Data["port"])),
+		Path:   "/" + string(dbSettings.Data["database"]),
+	}
+	query := url.Values{}
+	if getExternalDBDriver(spec) == "postgresql" {
+		dbURI.Scheme = "postgresql"
+		if spec.TLSCA != nil {
+			query.Set("sslmode", "verify-full")
+			query.Set("sslrootcert", externalDBCAPath+"/ca.crt")
+		}
+	} else if spec.TLSCA != nil {
+		query.Set("ssl_ca", externalDBCAPath+"/ca.crt")
+	}
+	dbURI.RawQuery = query.Encode()
+	return dbURI.String()
+}
+
+// mkExternalDBCAVolume returns the volume holding the CA certificate of the external database, if any
+func mkExternalDBCAVolume(spec *sfv1.ExternalDatabaseSpec) []apiv1.Volume {
+	if spec == nil || spec.TLSCA == nil {
+		return []apiv1.Volume{}
+	}
+	return []apiv1.Volume{{
+		Name: "zuul-db-tls",
+		VolumeSource: apiv1.VolumeSource{
+			Secret: &apiv1.SecretVolumeSource{
+				SecretName: spec.TLSCA.Name,
+				Items: []apiv1.KeyToPath{{
+					Key:  getExternalDBCAKey(spec),
+					Path: "ca.crt",
+				}},
+			},
+		},
+	}}
+}
+
+func mkExternalDBCAVolumeMount(spec *sfv1.ExternalDatabaseSpec) []apiv1.VolumeMount {
+	if spec == nil || spec.TLSCA == nil {
+		return []apiv1.VolumeMount{}
+	}
+	return []apiv1.VolumeMount{{
+		Name:      "zuul-db-tls",
+		MountPath: externalDBCAPath,
+		ReadOnly:  true,
+	}}
+}
+
+// DeployExternalDatabase configures the zuul-db-connection secret from the external database settings.
+// The MariaDB service is not deployed in that case.
+func (r *SFController) DeployExternalDatabase() bool {
+	spec := r.cr.Spec.ExternalDatabase
+	var dsnSecret apiv1.Secret
+	if !r.GetOrDie(spec.SecretName, &dsnSecret) {
+		logging.LogI("Waiting for the external database secret " + spec.SecretName)
+		conds.UpdateConditions(&r.cr.Status.Conditions, MariaDBIdent, false)
+		return false
+	}
+	data, err := parseExternalDSN(spec, string(dsnSecret.Data["dsn"]))
+	if err != nil {
+		logging.LogE(err, "Invalid dsn in the external database secret "+spec.SecretName)
+		conds.UpdateConditions(&r.cr.Status.Conditions, MariaDBIdent, false)
+		return false
+	}
+	if spec.TLSCA != nil {
+		var caSecret apiv1.Secret
+		if !r.GetOrDie(spec.TLSCA.Name, &caSecret) {
+			logging.LogI("Waiting for the external database CA secret " + spec.TLSCA.Name)
+			conds.UpdateConditions(&r.cr.Status.Conditions, MariaDBIdent, false)
+			return false
+		}
+		if _, ok := caSecret.Data[getExternalDBCAKey(spec)]; !ok {
+			logging.LogI("Missing key " + getExternalDBCAKey(spec) + " in the external database CA secret " + spec.TLSCA.Name)
+			conds.UpdateConditions(&r.cr.Status.Conditions, MariaDBIdent, false)
+			return false
+		}
+	}
+
+	r.EnsureSecret(&apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      zuulDBConfigSecret,
+			Namespace: r.Ns,
+		},
+		Data: data,
+	})
+
+	conds.UpdateConditions(&r.cr.Status.Conditions, MariaDBIdent, true)
+	return true
+}
+
+// getBinlogRetentionDays returns the binlog retention, or 0 when the binary logs are disabled
+func getBinlogRetentionDays(binlog *sfv1.MariaDBBinlogSpec) int32 {
+	if binlog == nil {
+		return 0
+	}
+	if binlog.RetentionDays < 1 {
+		return 7
+	}
+	return binlog.RetentionDays
+}
+
 func (r *SFController) DeployMariadb() bool {
 	adminPassSecret := r.EnsureSecretUUID(MariadbAdminPass)
 
+	// The replication requires the binary logs
+	replication := r.cr.Spec.MariaDB.Replicas > 0
+	binlogRetentionDays := getBinlogRetentionDays(r.cr.Spec.MariaDB.Binlog)
+	if replication && binlogRetentionDays == 0 {
+		binlogRetentionDays = 7
+	}
 	myCNF, _ := utils.ParseString(mariadbMyCNF,
 		struct {
-			MYSQLRootPassword string
-		}{MYSQLRootPassword: string(adminPassSecret.Data["mariadb-root-password"])})
+			MYSQLRootPassword   string
+			BinlogRetentionDays int32
+			BinlogExpireSeconds int32
+			Replication         bool
+		}{
+			MYSQLRootPassword:   string(adminPassSecret.Data["mariadb-root-password"]),
+			BinlogRetentionDays: binlogRetentionDays,
+			BinlogExpireSeconds: binlogRetentionDays * 24 * 3600,
+			Replication:         replication,
+		})
+
+	// The pods count includes the primary, which can be any pod after a promotion
+	primary := r.GetMariaDBPrimary()
+	podCount := 1 + r.cr.Spec.MariaDB.Replicas
+	if primaryOrdinal := getMariaDBOrdinal(primary); primaryOrdinal >= podCount {
+		logging.LogI(fmt.Sprintf("Keeping %d mariadb pods, the primary %s must be moved before scaling down", primaryOrdinal+1, primary))
+		podCount = primaryOrdinal + 1
+	}
 
-	initfileSQL := fmt.Sprintf(
+	initfileSQL := ""
+	if binlogRetentionDays > 0 {
+		// Do not replicate the credentials setup
+		initfileSQL = "SET SESSION sql_log_bin=0;\n"
+	}
+	initfileSQL += fmt.Sprintf(
 		`CREATE USER IF NOT EXISTS root@localhost IDENTIFIED BY '%s';
 SET PASSWORD FOR root@localhost = PASSWORD('%s');
 GRANT ALL ON *.* TO root@localhost WITH GRANT OPTION;
@@ -254,6 +464,24 @@ GRANT ALL ON *.* TO root@'%%' WITH GRANT OPTION;`,
 
 		"limits": limitstr,
 	}
+	if binlogRetentionDays > 0 {
+		annotations["binlog-retention-days"] = strconv.Itoa(int(binlogRetentionDays))
+	}
+	if replication {
+		// Each server of the replication needs a distinct server id, derived from the ordinal of the pod hostname.
+		// The id is written in an option file included by my.cnf, thus the image command is kept.
+		initContainer := base.MkContainer("init-server-id", base.MariaDBImage(), r.IsOpenShift)
+		initContainer.Command = []string{"/bin/sh", "-c",
+			`printf '[mysqld]\nserver_id=1%s\n' "${HOSTNAME##*-}" > /run/mariadb/server-id.cnf`}
+		initContainer.VolumeMounts = []apiv1.VolumeMount{
+			{
+				Name:      "mariadb-run",
+				MountPath: "/run/mariadb",
+			},
+		}
+		sts.Spec.Template.Spec.InitContainers = []apiv1.Container{initContainer}
+		annotations["replication"] = "enabled"
+	}
 	if r.cr.Spec.FluentBitLogForwarding != nil {
 		fbVolumes, fbSidecar := createLogForwarderSidecar(r, annotations)
 		sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, fbSidecar)
@@ -267,18 +495,29 @@ GRANT ALL ON *.* TO root@'%%' WITH GRANT OPTION;`,
 
 	sts.Spec.Template.Spec.HostAliases = base.CreateHostAliases(r.cr.Spec.HostAliases)
 
-	current, changed := r.ensureStatefulset(sts, nil)
+	current, changed := r.ensureStatefulset(sts, &podCount)
 	if changed {
 		return false
 	}
 
 	servicePorts := []int32{mariadbPort}
-	srv := base.MkServicePod(MariaDBIdent, r.Ns, "mariadb-0", servicePorts, mariaDBPortName, r.cr.Spec.ExtraLabels)
+	srv := base.MkServicePod(MariaDBIdent, r.Ns, primary, servicePorts, mariaDBPortName, r.cr.Spec.ExtraLabels)
 	r.EnsureService(&srv)
 
 	var zuulDBSecret apiv1.Secret
 
-	stsReady := r.waitStatefulset(current)
+	var stsReady bool
+	if podCount > 1 {
+		// The replicas do not gate the readiness, only the primary does
+		r.ensureMariaDBROService()
+		stsReady = r.isPodReady(primary)
+	} else {
+		var roSrv apiv1.Service
+		if r.GetOrDie(mariadbROService, &roSrv) {
+			r.DeleteR(&roSrv)
+		}
+		stsReady = r.waitStatefulset(current)
+	}
 
 	postReady := false
 	if stsReady {
@@ -304,12 +543,31 @@ GRANT ALL ON *.* TO root@'%%' WITH GRANT OPTION;`,
 		postReady = r.EnsureProvisionDBJob("zuul", string(zuulDBSecret.Data["password"]))
 	}
 
-	pvcDataReadiness := r.reconcileExpandPVC(MariaDBIdent+"-"+MariaDBIdent+"-0", r.cr.Spec.MariaDB.DBStorage)
-	pvcLogsReadiness := r.reconcileExpandPVC(MariaDBIdent+"-logs-"+MariaDBIdent+"-0", r.cr.Spec.MariaDB.LogStorage)
+	pvcReadiness := true
+	for ordinal := range podCount {
+		pvcDataReadiness := r.reconcileExpandPVC(fmt.Sprintf("%s-%s-%d", MariaDBIdent, MariaDBIdent, ordinal), r.cr.Spec.MariaDB.DBStorage)
+		pvcLogsReadiness := r.reconcileExpandPVC(fmt.Sprintf("%s-logs-%s-%d", MariaDBIdent, MariaDBIdent, ordinal), r.cr.Spec.MariaDB.LogStorage)
+		pvcReadiness = pvcReadiness && pvcDataReadiness && pvcLogsReadiness
+	}
+
+	isReady := stsReady && pvcReadiness && postReady
 
-	isReady := stsReady && pvcDataReadiness && pvcLogsReadiness && postReady
+	if isReady {
+		r.EnsureBinlogArchive()
+	}
 
-	conds.UpdateConditions(&r.cr.Status.Conditions, MariaDBIdent, isReady)
+	if isReady && podCount > 1 {
+		// The replication health is reported in the condition message, without blocking the deployment
+		message, degraded := r.reconcileMariaDBReplicas(primary, podCount)
+		reason := "Complete"
+		if degraded {
+			reason = "ReplicationDegraded"
+			logging.LogI("MariaDB replication degraded: " + message)
+		}
+		conds.RefreshCondition(&r.cr.Status.Conditions, MariaDBIdent, metav1.ConditionTrue, reason, message)
+	} else {
+		conds.UpdateConditions(&r.cr.Status.Conditions, MariaDBIdent, isReady)
+	}
 
 	return isReady
 }
@@ -0,0 +1,197 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the scheduled archive of the MariaDB binary logs.
+
+package controllers
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
+)
+
+//go:embed static/mariadb/archive-binlogs.sh
+var mariadbArchiveBinlogs string
+
+const (
+	binlogArchiveIdent     = "mariadb-binlog-archive"
+	binlogArchiveMountPath = "/var/lib/mariadb-binlog-archive"
+	binlogArchiveReader    = "mariadb-binlog-archive-reader"
+)
+
+func (r *SFController) mkBinlogArchiveCronJob(binlog *sfv1.MariaDBBinlogSpec) batchv1.CronJob {
+	container := base.MkContainer(binlogArchiveIdent, base.MariaDBImage(), r.IsOpenShift)
+	container.Command = []string{"/bin/sh", "-c", mariadbArchiveBinlogs}
+	container.Env = []apiv1.EnvVar{
+		base.MkSecretEnvVar("MARIADB_ROOT_PASSWORD", MariadbAdminPass, MariadbAdminPass),
+	}
+	container.VolumeMounts = []apiv1.VolumeMount{
+		{
+			Name:      binlogArchiveIdent,
+			MountPath: binlogArchiveMountPath,
+		},
+	}
+	base.SetContainerLimitsLowProfile(&container)
+
+	cj := batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      binlogArchiveIdent,
+			Namespace: r.Ns,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   binlog.ArchiveSchedule,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: ptr.To[int32](3),
+			FailedJobsHistoryLimit:     ptr.To[int32](3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To[int32](0),
+					Template: apiv1.PodTemplateSpec{
+						Spec: apiv1.PodSpec{
+							Containers:    []apiv1.Container{container},
+							RestartPolicy: apiv1.RestartPolicyNever,
+							Volumes: []apiv1.Volume{
+								base.MkVolumePVC(binlogArchiveIdent, binlogArchiveIdent),
+							},
+							HostAliases: base.CreateHostAliases(r.cr.Spec.HostAliases),
+						},
+					},
+				},
+			},
+		},
+	}
+	specJSON, _ := json.Marshal(cj.Spec)
+	cj.Annotations = map[string]string{"spec-hash": utils.Checksum(specJSON)}
+	return cj
+}
+
+// EnsureBinlogArchive manages the cronjob archiving the binary logs and its archive volume
+func (r *SFController) EnsureBinlogArchive() {
+	binlog := r.cr.Spec.MariaDB.Binlog
+	var current batchv1.CronJob
+	exists := r.GetOrDie(binlogArchiveIdent, &current)
+	if r.cr.Spec.ExternalDatabase != nil || binlog == nil || binlog.ArchiveSchedule == "" {
+		if exists {
+			r.DeleteR(&current)
+		}
+		return
+	}
+
+	pvc := base.MkPVC(binlogArchiveIdent, r.Ns, r.getStorageConfOrDefault(binlog.ArchiveStorage), apiv1.ReadWriteOnce)
+	r.GetOrCreate(&pvc)
+
+	cj := r.mkBinlogArchiveCronJob(binlog)
+	if !exists {
+		logging.LogI("Creating the binary logs archive cronjob")
+		r.CreateR(&cj)
+	} else if current.Annotations["spec-hash"] != cj.Annotations["spec-hash"] {
+		logging.LogI("Updating the binary logs archive cronjob")
+		current.Annotations = cj.Annotations
+		current.Spec = cj.Spec
+		r.UpdateR(&current)
+	}
+}
+
+// mkBinlogArchiveReaderJob returns a Job running an idle pod mounting the binary logs archive volume
+func (r *SFKubeContext) mkBinlogArchiveReaderJob() *batchv1.Job {
+	container := base.MkContainer(binlogArchiveReader, base.MariaDBImage(), r.IsOpenShift)
+	base.SetContainerLimitsLowProfile(&container)
+	container.Command = []string{"sleep", "3600"}
+	container.VolumeMounts = []apiv1.VolumeMount{
+		{
+			Name:      binlogArchiveIdent,
+			MountPath: binlogArchiveMountPath,
+			ReadOnly:  true,
+		},
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      binlogArchiveReader,
+			Namespace: r.Ns,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          ptr.To[int32](0),
+			ActiveDeadlineSeconds: ptr.To[int64](3600),
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					RestartPolicy: apiv1.RestartPolicyNever,
+					Containers:    []apiv1.Container{container},
+					Volumes: []apiv1.Volume{
+						base.MkVolumePVC(binlogArchiveIdent, binlogArchiveIdent),
+					},
+				},
+			},
+		},
+	}
+}
+
+// fetchArchivedBinlogs copies the binary logs of the archive volume to the backup directory, so that they are replayed
+// with the binary logs archived by the backup command. The binary logs already present with the same size are skipped.
+func (r *SFKubeContext) fetchArchivedBinlogs(backupDir string) error {
+	var pvc apiv1.PersistentVolumeClaim
+	if !r.GetOrDie(binlogArchiveIdent, &pvc) {
+		ctrl.Log.Info("No binary logs archive volume, only replaying the binary logs of the backup")
+		return nil
+	}
+	binlogBackupDir := filepath.Join(backupDir, BinlogBackupPath)
+	if err := os.MkdirAll(binlogBackupDir, 0750); err != nil {
+		ctrl.Log.Error(err, "Couldn't create backup dir:"+binlogBackupDir)
+		return err
+	}
+
+	podName, err := r.ensureIdleJob(r.mkBinlogArchiveReaderJob())
+	defer r.deleteIdleJob(binlogArchiveReader)
+	if err != nil {
+		return err
+	}
+	listCMD := "cd " + binlogArchiveMountPath + ` && for f in *; do if [ -f "$f" ]; then stat -c "%n %s" "$f"; fi; done`
+	output, err := r.PodExecBytes(podName, binlogArchiveReader, []string{"sh", "-c", listCMD})
+	if err != nil {
+		ctrl.Log.Error(err, "Couldn't list the binary logs archive")
+		return err
+	}
+	fetched := 0
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		name := fields[0]
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		dest := filepath.Join(binlogBackupDir, name)
+		if stat, err := os.Stat(dest); err == nil && stat.Size() == size {
+			continue
+		}
+		content, err := r.PodExecBytes(podName, binlogArchiveReader, []string{"cat", binlogArchiveMountPath + "/" + name})
+		if err != nil {
+			ctrl.Log.Error(err, "Couldn't read the archived binary log "+name)
+			return err
+		}
+		if err := os.WriteFile(dest, content.Bytes(), 0640); err != nil {
+			ctrl.Log.Error(err, "Couldn't write:"+dest)
+			return err
+		}
+		fetched += 1
+	}
+	ctrl.Log.Info(fmt.Sprintf("Fetched %d binary logs from the %s volume", fetched, binlogArchiveIdent))
+	return nil
+}
@@ -0,0 +1,322 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the mariadb primary/replica topology.
+
+package controllers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	logging "github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
+)
+
+const (
+	mariadbTopologyCM = "mariadb-topology"
+	mariadbROService  = "mariadb-ro"
+	mariadbRoleLabel  = "mariadb-role"
+	// The label selecting the pods serving the reads: the primary and the healthy replicas
+	mariadbReadableLabel  = "mariadb-readable"
+	mariadbDefaultPrimary = "mariadb-0"
+	// The replication lag, in seconds, above which a replica is reported as lagging
+	mariadbMaxReplicationLag = 300
+)
+
+type mariadbReplicaStatus struct {
+	Configured bool
+	IORunning  bool
+	SQLRunning bool
+	// The replication lag in seconds, -1 when unknown
+	Lag   int
+	Error string
+}
+
+func (s mariadbReplicaStatus) String() string {
+	if !s.IORunning || !s.SQLRunning {
+		msg := "replication stopped"
+		if s.Error != "" {
+			msg += ": " + s.Error
+		}
+		return msg
+	}
+	if s.Lag < 0 {
+		return "lag unknown"
+	}
+	return fmt.Sprintf("lag %ds", s.Lag)
+}
+
+func (r *SFController) isMariaDBReplicated() bool {
+	return r.cr.Spec.ExternalDatabase == nil && r.cr.Spec.MariaDB.Replicas > 0
+}
+
+// GetMariaDBPrimary returns the name of the pod running the MariaDB primary
+func (r *SFKubeContext) GetMariaDBPrimary() string {
+	var cm apiv1.ConfigMap
+	if r.GetOrDie(mariadbTopologyCM, &cm) && cm.Data["primary"] != "" {
+		return cm.Data["primary"]
+	}
+	return mariadbDefaultPrimary
+}
+
+func (r *SFKubeContext) setMariaDBPrimary(primary string) {
+	var cm apiv1.ConfigMap
+	if r.GetOrDie(mariadbTopologyCM, &cm) {
+		cm.Data = map[string]string{"primary": primary}
+		r.UpdateR(&cm)
+	} else {
+		cm = apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: mariadbTopologyCM, Namespace: r.Ns},
+			Data:       map[string]string{"primary": primary},
+		}
+		r.CreateR(&cm)
+	}
+}
+
+func getMariaDBOrdinal(pod string) int32 {
+	ordinal, _ := strconv.Atoi(strings.TrimPrefix(pod, MariaDBIdent+"-"))
+	return int32(ordinal)
+}
+
+// mariadbQuery runs SQL statements as root in a mariadb pod and returns the raw output
+func (r *SFKubeContext) mariadbQuery(pod string, query string) (string, error) {
+	out, err := r.PodExecBytes(pod, MariaDBIdent, []string{"mysql", "--skip-column-names", "-e", query})
+	return strings.TrimSpace(out.String()), err
+}
+
+// mariadbExecSecret runs SQL statements holding credentials through the standard input, so that they are not logged
+func (r *SFKubeContext) mariadbExecSecret(pod string, statements string) error {
+	return r.PodExecIn(pod, MariaDBIdent, []string{"mysql"}, strings.NewReader(statements))
+}
+
+func (r *SFKubeContext) mkChangeMasterSQL() string {
+	return fmt.Sprintf(
+		"CHANGE MASTER TO MASTER_HOST='%s', MASTER_PORT=%d, MASTER_USER='root', MASTER_PASSWORD='%s', MASTER_USE_GTID=slave_pos;\nSTART SLAVE;\n",
+		MariaDBIdent, mariadbPort, r.ReadSecretValue(MariadbAdminPass, MariadbAdminPass))
+}
+
+func (r *SFKubeContext) getMariaDBReplicaStatus(pod string) (mariadbReplicaStatus, error) {
+	status := mariadbReplicaStatus{Lag: -1}
+	out, err := r.PodExecBytes(pod, MariaDBIdent, []string{"mysql", "--vertical", "-e", "SHOW SLAVE STATUS"})
+	if err != nil {
+		return status, err
+	}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Master_Host":
+			status.Configured = value != ""
+		case "Slave_IO_Running":
+			status.IORunning = value == "Yes"
+		case "Slave_SQL_Running":
+			status.SQLRunning = value == "Yes"
+		case "Seconds_Behind_Master":
+			if lag, err := strconv.Atoi(value); err == nil {
+				status.Lag = lag
+			}
+		case "Last_IO_Error", "Last_SQL_Error":
+			if value != "" {
+				status.Error = value
+			}
+		}
+	}
+	return status, nil
+}
+
+// setupMariaDBReplica seeds a replica with a dump of the primary, then starts the GTID replication.
+// The replica connects to the primary through the mariadb service, so that it follows a promotion.
+func (r *SFKubeContext) setupMariaDBReplica(pod string) error {
+	logging.LogI("Setting up the MariaDB replica " + pod)
+	zuulPassword := r.ReadSecretValue(zuulDBConfigSecret, "password")
+	if zuulPassword == "" {
+		return errors.New("missing zuul database password")
+	}
+	if _, err := r.mariadbQuery(pod, "STOP SLAVE; SET GLOBAL read_only=1"); err != nil {
+		return err
+	}
+	// The dump sets gtid_slave_pos. The local changes are not written in the binlog to keep the GTID history of the primary.
+	seedCMD := `(echo "SET SESSION sql_log_bin=0;"; ` +
+		`mysqldump --host=` + MariaDBIdent + ` --user=root --password="$MARIADB_ROOT_PASSWORD" ` +
+		`--single-transaction --gtid --master-data=1 --databases zuul) | mysql`
+	if _, err := r.PodExecBytes(pod, MariaDBIdent, []string{"sh", "-c", "set -o pipefail; " + seedCMD}); err != nil {
+		return fmt.Errorf("unable to seed %s: %w", pod, err)
+	}
+	setupSQL := fmt.Sprintf(`SET SESSION sql_log_bin=0;
+CREATE USER IF NOT EXISTS zuul@'%%' IDENTIFIED BY '%s';
+GRANT ALL PRIVILEGES ON zuul.* TO zuul@'%%';
+`, zuulPassword) + r.mkChangeMasterSQL()
+	return r.mariadbExecSecret(pod, setupSQL)
+}
+
+// setMariaDBRole labels a mariadb pod with its role. The primary and the healthy replicas are selected
+// by the mariadb-ro service, so that the reads fall back to the primary when no replica is available.
+func (r *SFKubeContext) setMariaDBRole(pod string, role string) {
+	readable := strconv.FormatBool(role == "primary" || role == "replica")
+	var current apiv1.Pod
+	if !r.GetOrDie(pod, &current) ||
+		(current.Labels[mariadbRoleLabel] == role && current.Labels[mariadbReadableLabel] == readable) {
+		return
+	}
+	current.Labels[mariadbRoleLabel] = role
+	current.Labels[mariadbReadableLabel] = readable
+	if err := r.Client.Update(r.Ctx, &current); err != nil {
+		logging.LogE(err, "Unable to label the mariadb pod "+pod)
+	}
+}
+
+// pointMariaDBService updates the mariadb service to target the primary pod
+func (r *SFKubeContext) pointMariaDBService(primary string) {
+	var srv apiv1.Service
+	if r.GetOrDie(MariaDBIdent, &srv) && srv.Spec.Selector["statefulset.kubernetes.io/pod-name"] != primary {
+		srv.Spec.Selector = map[string]string{"statefulset.kubernetes.io/pod-name": primary}
+		r.UpdateR(&srv)
+	}
+}
+
+func (r *SFController) ensureMariaDBROService() {
+	srv := base.MkService(mariadbROService, r.Ns, MariaDBIdent, []int32{mariadbPort}, mariaDBPortName, r.cr.Spec.ExtraLabels)
+	srv.Spec.Selector[mariadbReadableLabel] = "true"
+	r.EnsureService(&srv)
+}
+
+func (r *SFKubeContext) isPodReady(name string) bool {
+	var pod apiv1.Pod
+	if !r.GetOrDie(name, &pod) || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == apiv1.PodReady {
+			return cond.Status == apiv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// reconcileMariaDBReplicas configures the replicas and returns their health.
+// The degraded flag is set when a replica is not running or lags behind.
+func (r *SFKubeContext) reconcileMariaDBReplicas(primary string, podCount int32) (string, bool) {
+	r.setMariaDBRole(primary, "primary")
+	if _, err := r.mariadbQuery(primary, "SET GLOBAL read_only=0"); err != nil {
+		logging.LogE(err, "Unable to set the MariaDB primary writable")
+	}
+
+	degraded := false
+	messages := []string{}
+	for ordinal := range podCount {
+		pod := fmt.Sprintf("%s-%d", MariaDBIdent, ordinal)
+		if pod == primary {
+			continue
+		}
+		if !r.isPodReady(pod) {
+			r.setMariaDBRole(pod, "unavailable")
+			messages = append(messages, pod+": not ready")
+			degraded = true
+			continue
+		}
+		status, err := r.getMariaDBReplicaStatus(pod)
+		if err == nil && !status.Configured {
+			if err = r.setupMariaDBReplica(pod); err == nil {
+				status, err = r.getMariaDBReplicaStatus(pod)
+			}
+		}
+		if err != nil {
+			logging.LogE(err, "Unable to reconcile the MariaDB replica "+pod)
+			r.setMariaDBRole(pod, "unavailable")
+			messages = append(messages, pod+": "+err.Error())
+			degraded = true
+			continue
+		}
+		// A restarted replica is writable until it is reconciled
+		if _, err := r.mariadbQuery(pod, "SET GLOBAL read_only=1"); err != nil {
+			logging.LogE(err, "Unable to set the MariaDB replica read-only "+pod)
+		}
+		healthy := status.IORunning && status.SQLRunning && status.Lag >= 0 && status.Lag <= mariadbMaxReplicationLag
+		if healthy {
+			r.setMariaDBRole(pod, "replica")
+		} else {
+			r.setMariaDBRole(pod, "unavailable")
+			degraded = true
+		}
+		messages = append(messages, pod+": "+status.String())
+	}
+	return fmt.Sprintf("Primary %s, replicas: %s", primary, strings.Join(messages, ", ")), degraded
+}
+
+// PromoteMariaDB promotes a replica as the new MariaDB primary. When the current primary is reachable,
+// the writes are stopped and the replica catches up before the promotion, then the former primary
+// becomes a replica of the new primary. Otherwise, the former primary is re-seeded at the next reconcile.
+func (r *SFKubeContext) PromoteMariaDB(target string) error {
+	primary := r.GetMariaDBPrimary()
+	if target == primary {
+		return errors.New(target + " is already the primary")
+	}
+	status, err := r.getMariaDBReplicaStatus(target)
+	if err != nil {
+		return fmt.Errorf("unable to read the replication status of %s: %w", target, err)
+	}
+	if !status.Configured {
+		return errors.New(target + " is not a replica")
+	}
+
+	primaryReachable := false
+	if r.isPodReady(primary) {
+		gtidPos, err := r.mariadbQuery(primary, "SET GLOBAL read_only=1; SELECT @@gtid_binlog_pos")
+		if err == nil {
+			logging.LogI(fmt.Sprintf("Waiting for %s to reach the GTID position %s", target, gtidPos))
+			res, err := r.mariadbQuery(target, fmt.Sprintf("SELECT MASTER_GTID_WAIT('%s', 60)", gtidPos))
+			if err != nil || res != "0" {
+				r.mariadbQuery(primary, "SET GLOBAL read_only=0")
+				return fmt.Errorf("%s did not catch up with the primary %s, aborting", target, primary)
+			}
+			primaryReachable = true
+		}
+	}
+	if !primaryReachable {
+		logging.LogI("The primary " + primary + " is unreachable, promoting " + target + " without waiting for it")
+	}
+
+	if _, err := r.mariadbQuery(target, "STOP SLAVE; RESET SLAVE ALL; SET GLOBAL read_only=0"); err != nil {
+		return fmt.Errorf("unable to promote %s: %w", target, err)
+	}
+	r.setMariaDBPrimary(target)
+	r.pointMariaDBService(target)
+	r.setMariaDBRole(target, "primary")
+	logging.LogI(target + " is the new MariaDB primary")
+
+	// Reconnect the other replicas to the mariadb service, now targeting the new primary
+	var pods apiv1.PodList
+	r.ListOrDie(&pods)
+	for _, pod := range pods.Items {
+		if pod.Labels["run"] != MariaDBIdent || pod.Name == target || pod.Name == primary {
+			continue
+		}
+		if _, err := r.mariadbQuery(pod.Name, "STOP SLAVE; START SLAVE"); err != nil {
+			logging.LogE(err, "Unable to restart the replication of "+pod.Name)
+		}
+	}
+	if primaryReachable {
+		// The former primary has the same GTID position as the new primary
+		err := r.mariadbExecSecret(primary, "SET GLOBAL gtid_slave_pos=@@gtid_binlog_pos;\n"+r.mkChangeMasterSQL())
+		if err != nil {
+			logging.LogE(err, "Unable to set up "+primary+" as a replica, it will be re-seeded at the next reconcile")
+			r.mariadbQuery(primary, "RESET SLAVE ALL")
+		} else {
+			r.setMariaDBRole(primary, "replica")
+		}
+	} else {
+		r.setMariaDBRole(primary, "unavailable")
+	}
+	return nil
+}
@@ -7,7 +7,10 @@ package controllers
 import (
 	_ "embed"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
 
 	v1 "github.com/softwarefactory-project/sf-operator/api/v1"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
@@ -16,8 +19,13 @@ import (
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/monitoring"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
 
+	"gopkg.in/ini.v1"
+	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 //go:embed static/nodepool/init-container.sh
@@ -32,6 +40,9 @@ var loggingConfigTemplate string
 //go:embed static/nodepool/dib-ansible.py
 var dibAnsibleWrapper string
 
+//go:embed static/nodepool/nodepool-lint.py
+var nodepoolLintScript string
+
 //go:embed static/nodepool/ssh_config
 var builderSSHConfig string
 
@@ -65,6 +76,8 @@ const (
 	BuildLogsHttpdPortName       = "buildlogs-http"
 	NodepoolProvidersSecretsName = "nodepool-providers-secrets"
 	BuilderIdent                 = nodepoolIdent + "-builder"
+	// launcherPodLabel is set on the pods of every launcher deployment, the webapp being served by any of them
+	launcherPodLabel = "nodepool-launcher"
 )
 
 var NodepoolStatsdExporterPortName = monitoring.GetStatsdExporterPort(shortIdent)
@@ -82,6 +95,12 @@ var configScriptsVolumeMounts = []apiv1.VolumeMount{
 		MountPath: "/usr/local/bin/fetch-config-repo.sh",
 		ReadOnly:  true,
 	},
+	{
+		Name:      "nodepool-tooling-vol",
+		SubPath:   "nodepool-lint.py",
+		MountPath: "/usr/local/bin/nodepool-lint.py",
+		ReadOnly:  true,
+	},
 }
 
 var nodepoolFluentBitLabels = []logging.FluentBitLabel{
@@ -148,6 +167,7 @@ func (r *SFController) setNodepoolTooling() {
 	toolingData["init-container.sh"] = initContainerScript
 	toolingData["generate-config.sh"] = r.generateConfigScript()
 	toolingData["fetch-config-repo.sh"] = fetchConfigRepoScript
+	toolingData["nodepool-lint.py"] = nodepoolLintScript
 	toolingData["dib-ansible.py"] = dibAnsibleWrapper
 	toolingData["ssh_config"] = builderSSHConfig
 	toolingData["timestamp.py"] = timestampOutputCallback
@@ -273,6 +293,60 @@ func getSecretsVersion(secret apiv1.Secret, secretExists bool) string {
 	return secretVersion
 }
 
+// NodepoolProvidersNames lists the clouds, kube contexts and aws profiles defined in the providers secret,
+// without their credentials. The list is published in the system-config repository for the config-check job.
+type NodepoolProvidersNames struct {
+	Clouds   []string `json:"clouds"`
+	Contexts []string `json:"contexts"`
+	Profiles []string `json:"profiles"`
+}
+
+// GetNodepoolProvidersNames reads the names defined in the providers secret data
+func GetNodepoolProvidersNames(data map[string][]byte) NodepoolProvidersNames {
+	names := NodepoolProvidersNames{Clouds: []string{}, Contexts: []string{}, Profiles: []string{}}
+
+	var clouds struct {
+		Clouds map[string]interface{} `json:"clouds"`
+	}
+	if err := yaml.Unmarshal(data["clouds.yaml"], &clouds); err == nil {
+		for name := range clouds.Clouds {
+			names.Clouds = append(names.Clouds, name)
+		}
+	}
+
+	var kubeConfig struct {
+		Contexts []struct {
+			Name string `json:"name"`
+		} `json:"contexts"`
+	}
+	if err := yaml.Unmarshal(data["kube.config"], &kubeConfig); err == nil {
+		for _, context := range kubeConfig.Contexts {
+			names.Contexts = append(names.Contexts, context.Name)
+		}
+	}
+
+	if awsConfig, err := ini.Load(data["aws.config"]); err == nil {
+		for _, section := range awsConfig.SectionStrings() {
+			if section != ini.DefaultSection {
+				names.Profiles = append(names.Profiles, strings.TrimSpace(strings.TrimPrefix(section, "profile ")))
+			}
+		}
+	}
+
+	slices.Sort(names.Clouds)
+	slices.Sort(names.Contexts)
+	slices.Sort(names.Profiles)
+	return names
+}
+
+// getNodepoolProvidersNamesYAML returns the names defined in the providers secret, as expected by nodepool-lint.py
+func (r *SFController) getNodepoolProvidersNamesYAML() string {
+	var secret apiv1.Secret
+	r.GetOrDie(NodepoolProvidersSecretsName, &secret)
+	output, _ := sigsyaml.Marshal(GetNodepoolProvidersNames(secret.Data))
+	return string(output)
+}
+
 func getCMVersion(cm apiv1.ConfigMap, cmExists bool) string {
 	cmVersion := "0"
 	if cmExists {
@@ -284,8 +358,6 @@ func getCMVersion(cm apiv1.ConfigMap, cmExists bool) string {
 func (r *SFController) DeployNodepoolBuilder(statsdExporterVolume apiv1.Volume, nodepoolStatsdMappingConfig string,
 	initialVolumeMounts []apiv1.VolumeMount, providersSecrets apiv1.Secret, providerSecretsExists bool) bool {
 
-	r.EnsureSSHKeySecret("nodepool-builder-ssh-key")
-
 	r.setNodepoolTooling()
 
 	loggingConfig, _ := r.mkLoggingTemplate("builder")
@@ -561,11 +633,120 @@ func hasProviderSecret(volumeMounts []apiv1.VolumeMount) bool {
 	return false
 }
 
-func (r *SFController) DeployNodepoolLauncher(statsdExporterVolume apiv1.Volume, nodepoolStatsdMappingConfig string,
+// nodepoolLauncherGroup describes a nodepool-launcher deployment
+type nodepoolLauncherGroup struct {
+	// Name is the name of the deployment
+	Name string
+	// Env selects the providers run by the deployment
+	Env []apiv1.EnvVar
+	// StatsdIdent is the base name of the statsd mapping ConfigMap
+	StatsdIdent   string
+	StatsdMapping string
+	Limits        *v1.LimitsSpec
+	// Main is set for the nodepool-launcher deployment, which runs the providers that are not part of a group
+	Main bool
+}
+
+// ValidateNodepoolLauncherGroups checks that the launcher groups have distinct names and providers
+func ValidateNodepoolLauncherGroups(spec v1.NodepoolLauncherSpec) error {
+	names := []string{}
+	providers := map[string]string{}
+	for _, group := range spec.Groups {
+		if slices.Contains(names, group.Name) {
+			return fmt.Errorf("duplicate launcher group %s", group.Name)
+		}
+		names = append(names, group.Name)
+		for _, provider := range group.Providers {
+			if other, ok := providers[provider]; ok {
+				return fmt.Errorf("the provider %s is part of the launcher groups %s and %s", provider, other, group.Name)
+			}
+			providers[provider] = group.Name
+		}
+	}
+	return nil
+}
+
+// mkNodepoolLauncherGroups returns the nodepool-launcher deployment followed by the deployments of the launcher groups.
+// The statsd mapping of a group only holds the openstacksdk metrics of the clouds named after its providers.
+func mkNodepoolLauncherGroups(spec v1.NodepoolLauncherSpec, cloudsYaml map[string]interface{}, statsdMapping string) []nodepoolLauncherGroup {
+	grouped := []string{}
+	groups := []nodepoolLauncherGroup{}
+	for _, group := range spec.Groups {
+		grouped = append(grouped, group.Providers...)
+		groupClouds := map[string]interface{}{}
+		if clouds, ok := cloudsYaml["clouds"].(map[string]interface{}); ok {
+			for _, provider := range group.Providers {
+				if cloud, ok := clouds[provider]; ok {
+					groupClouds[provider] = cloud
+				}
+			}
+		}
+		groupCloudsYaml := map[string]interface{}{"clouds": groupClouds}
+		if metrics, ok := cloudsYaml["metrics"]; ok {
+			groupCloudsYaml["metrics"] = metrics
+		}
+		groupStatsdMapping, _ := mkStatsdMappingConfig(groupCloudsYaml)
+		limits := group.Limits
+		if limits == nil {
+			limits = spec.Limits
+		}
+		groups = append(groups, nodepoolLauncherGroup{
+			Name:          LauncherIdent + "-" + group.Name,
+			Env:           []apiv1.EnvVar{base.MkEnvVar("NODEPOOL_PROVIDERS", strings.Join(group.Providers, ","))},
+			StatsdIdent:   shortIdent + "-statsd-" + group.Name,
+			StatsdMapping: groupStatsdMapping,
+			Limits:        limits,
+		})
+	}
+
+	main := nodepoolLauncherGroup{
+		Name:          LauncherIdent,
+		StatsdIdent:   shortIdent + "-statsd",
+		StatsdMapping: statsdMapping,
+		Limits:        spec.Limits,
+		Main:          true,
+	}
+	if len(grouped) > 0 {
+		slices.Sort(grouped)
+		main.Env = []apiv1.EnvVar{base.MkEnvVar("NODEPOOL_EXCLUDED_PROVIDERS", strings.Join(grouped, ","))}
+	}
+	return append([]nodepoolLauncherGroup{main}, groups...)
+}
+
+// terminateNodepoolLauncherGroups removes the deployments of the launcher groups that are no longer defined
+func (r *SFController) terminateNodepoolLauncherGroups(groups []nodepoolLauncherGroup) {
+	var deployments appsv1.DeploymentList
+	if err := r.Client.List(r.Ctx, &deployments, client.InNamespace(r.Ns)); err != nil {
+		logging.LogE(err, "Unable to list the deployments")
+		return
+	}
+	for _, dep := range deployments.Items {
+		if dep.Spec.Template.Labels[launcherPodLabel] != "true" || slices.ContainsFunc(groups, func(group nodepoolLauncherGroup) bool {
+			return group.Name == dep.Name
+		}) {
+			continue
+		}
+		logging.LogI("Removing the launcher group " + dep.Name)
+		r.DeleteR(&dep)
+		r.DeleteR(&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      shortIdent + "-statsd-" + strings.TrimPrefix(dep.Name, LauncherIdent+"-") + "-config-map",
+				Namespace: r.Ns,
+			},
+		})
+	}
+}
+
+func (r *SFController) DeployNodepoolLauncher(group nodepoolLauncherGroup,
 	initialVolumeMounts []apiv1.VolumeMount, providersSecrets apiv1.Secret, providerSecretsExists bool) bool {
 
 	r.setNodepoolTooling()
 
+	r.EnsureConfigMap(group.StatsdIdent, map[string]string{
+		monitoring.StatsdExporterConfigFile: group.StatsdMapping,
+	})
+	statsdExporterVolume := base.MkVolumeCM("statsd-config", group.StatsdIdent+"-config-map")
+
 	loggingConfig, _ := r.mkLoggingTemplate("launcher")
 
 	// get statsd relay if defined
@@ -626,8 +807,9 @@ func (r *SFController) DeployNodepoolLauncher(statsdExporterVolume apiv1.Volume,
 	annotations := map[string]string{
 		"nodepool.yaml":         utils.Checksum([]byte(r.generateConfigScript())),
 		"nodepool-logging.yaml": utils.Checksum([]byte(loggingConfig)),
-		"statsd_mapping":        utils.Checksum([]byte(nodepoolStatsdMappingConfig)),
-		"serial":                "12",
+		"nodepool-lint.py":      utils.Checksum([]byte(nodepoolLintScript)),
+		"statsd_mapping":        utils.Checksum([]byte(group.StatsdMapping)),
+		"serial":                "13",
 		// When the Secret ResourceVersion field change (when edited) we force a nodepool-launcher restart
 		"nodepool-providers-secrets": getSecretsVersion(providersSecrets, providerSecretsExists),
 		"corporate-ca-certs-version": getCMVersion(corporateCM, corporateCMExists),
@@ -636,12 +818,15 @@ func (r *SFController) DeployNodepoolLauncher(statsdExporterVolume apiv1.Volume,
 	if r.isConfigRepoSet() {
 		annotations["config-repo-info-hash"] = r.configBaseURL + r.cr.Spec.ConfigRepositoryLocation.Name
 	}
+	for _, env := range group.Env {
+		annotations[env.Name] = env.Value
+	}
 
 	initContainer := base.MkContainer("nodepool-launcher-init", base.NodepoolLauncherImage(), r.IsOpenShift)
 	base.SetContainerLimitsLowProfile(&initContainer)
 
 	initContainer.Command = []string{"/usr/local/bin/init-container.sh"}
-	initContainer.Env = r.getNodepoolConfigEnvs()
+	initContainer.Env = append(r.getNodepoolConfigEnvs(), group.Env...)
 	initContainer.VolumeMounts = []apiv1.VolumeMount{
 		{
 			Name:      "nodepool-tooling-vol",
@@ -669,7 +854,10 @@ func (r *SFController) DeployNodepoolLauncher(statsdExporterVolume apiv1.Volume,
 		initContainer.VolumeMounts = AppendCorporateCACertsVolumeMount(initContainer.VolumeMounts, "nodepool-launcher-corporate-ca-certs")
 	}
 
-	nl := base.MkDeployment("nodepool-launcher", r.Ns, "", r.cr.Spec.ExtraLabels, r.IsOpenShift)
+	nl := base.MkDeployment(group.Name, r.Ns, "", r.cr.Spec.ExtraLabels, r.IsOpenShift)
+	// The template labels are cloned to keep the deployment selector unchanged
+	nl.Spec.Template.ObjectMeta.Labels = maps.Clone(nl.Spec.Template.ObjectMeta.Labels)
+	nl.Spec.Template.ObjectMeta.Labels[launcherPodLabel] = "true"
 
 	container := base.MkContainer("launcher", base.NodepoolLauncherImage(), r.IsOpenShift)
 	container.VolumeMounts = volumeMounts
@@ -677,13 +865,13 @@ func (r *SFController) DeployNodepoolLauncher(statsdExporterVolume apiv1.Volume,
 		"/usr/local/bin/dumb-init", "-c", "--",
 		"/usr/local/bin/nodepool-launcher", "-f", "-l", "/etc/nodepool-logging/logging.yaml",
 	}
-	container.Env = r.getNodepoolConfigEnvs()
+	container.Env = append(r.getNodepoolConfigEnvs(), group.Env...)
 	base.SetContainerLimitsHighProfile(&container)
-	limitstr := base.UpdateContainerLimit(r.cr.Spec.Nodepool.Launcher.Limits, &container)
+	limitstr := base.UpdateContainerLimit(group.Limits, &container)
 	annotations["limits"] = limitstr
 
-	launcherFluentBitLabels := append(nodepoolFluentBitLabels, logging.FluentBitLabel{Key: "CONTAINER", Value: LauncherIdent})
-	extraLoggingEnvVars := logging.SetupLogForwarding("nodepool-launcher", r.cr.Spec.FluentBitLogForwarding, launcherFluentBitLabels, annotations)
+	launcherFluentBitLabels := append(nodepoolFluentBitLabels, logging.FluentBitLabel{Key: "CONTAINER", Value: group.Name})
+	extraLoggingEnvVars := logging.SetupLogForwarding(group.Name, r.cr.Spec.FluentBitLogForwarding, launcherFluentBitLabels, annotations)
 	container.Env = append(container.Env, extraLoggingEnvVars...)
 
 	nl.Spec.Template.Spec.Volumes = volumes
@@ -699,14 +887,19 @@ func (r *SFController) DeployNodepoolLauncher(statsdExporterVolume apiv1.Volume,
 		base.MkContainerPort(launcherPort, launcherPortName),
 	}
 
-	if hasProviderSecret(initialVolumeMounts) {
-		// Append zuul-capacity sidecar
-		nl.Spec.Template.Spec.Containers = append(nl.Spec.Template.Spec.Containers,
-			MkZuulCapacityContainer(r.IsOpenShift, corporateCMExists),
-		)
-		// Setup zuul-capacity service
-		zcSrv := base.MkService("zuul-capacity", r.Ns, "nodepool-launcher", []int32{9100}, "zuul-capacity", r.cr.Spec.ExtraLabels)
-		r.GetOrCreate(&zcSrv)
+	// Only the nodepool-launcher deployment runs the zuul-capacity sidecar, which reports the usage of every provider
+	if group.Main {
+		if r.IsZuulCapacityEnabled() && hasProviderSecret(initialVolumeMounts) {
+			// Append zuul-capacity sidecar
+			capacityContainer := MkZuulCapacityContainer(r.IsOpenShift, corporateCMExists)
+			annotations["zuul-capacity"] = "enabled-" + base.UpdateContainerLimit(r.cr.Spec.ZuulCapacity.Limits, &capacityContainer)
+			nl.Spec.Template.Spec.Containers = append(nl.Spec.Template.Spec.Containers, capacityContainer)
+			// Setup zuul-capacity service
+			zcSrv := base.MkService(zuulCapacityIdent, r.Ns, LauncherIdent, []int32{9100}, zuulCapacityIdent, r.cr.Spec.ExtraLabels)
+			r.GetOrCreate(&zcSrv)
+		} else {
+			r.TerminateZuulCapacity()
+		}
 	}
 	nl.Spec.Template.Spec.HostAliases = base.CreateHostAliases(r.cr.Spec.HostAliases)
 
@@ -715,11 +908,8 @@ func (r *SFController) DeployNodepoolLauncher(statsdExporterVolume apiv1.Volume,
 		return false
 	}
 
-	srv := base.MkService(LauncherIdent, r.Ns, LauncherIdent, []int32{launcherPort}, LauncherIdent, r.cr.Spec.ExtraLabels)
-	r.GetOrCreate(&srv)
-
 	isDeploymentReady := r.IsDeploymentReady(current)
-	conds.UpdateConditions(&r.cr.Status.Conditions, LauncherIdent, isDeploymentReady)
+	conds.UpdateConditions(&r.cr.Status.Conditions, group.Name, isDeploymentReady)
 
 	return isDeploymentReady
 }
@@ -731,6 +921,9 @@ func (r *SFController) DeployNodepool() map[string]bool {
 	// Create a spare ssh key to be added to the nodepool image for zuul
 	r.EnsureSSHKeySecret("zuul-spare-ssh-key")
 
+	// The builder key is part of the backup, so it is kept even when the builder is disabled
+	r.EnsureSSHKeySecret("nodepool-builder-ssh-key")
+
 	// We need to initialize the providers secrets early
 	var volumeMounts, nodepoolProvidersSecrets, providerSecretsResourceExists = r.setProviderSecretsVolumeMounts()
 
@@ -747,9 +940,50 @@ func (r *SFController) DeployNodepool() map[string]bool {
 	})
 	statsdVolume := base.MkVolumeCM("statsd-config", "np-statsd-config-map")
 
-	deployments[LauncherIdent] = r.DeployNodepoolLauncher(
-		statsdVolume, nodepoolStatsdMappingConfig, volumeMounts, nodepoolProvidersSecrets, providerSecretsResourceExists)
-	deployments[BuilderIdent] = r.DeployNodepoolBuilder(statsdVolume, nodepoolStatsdMappingConfig,
-		volumeMounts, nodepoolProvidersSecrets, providerSecretsResourceExists)
+	launcherGroups := mkNodepoolLauncherGroups(r.cr.Spec.Nodepool.Launcher, cloudsYaml, nodepoolStatsdMappingConfig)
+	launchersReady := true
+	for _, group := range launcherGroups {
+		launchersReady = r.DeployNodepoolLauncher(
+			group, volumeMounts, nodepoolProvidersSecrets, providerSecretsResourceExists) && launchersReady
+	}
+	r.terminateNodepoolLauncherGroups(launcherGroups)
+	deployments[LauncherIdent] = launchersReady
+
+	// The webapp of every launcher serves the state of all the providers, as it is read from ZooKeeper
+	srv := base.MkService(LauncherIdent, r.Ns, LauncherIdent, []int32{launcherPort}, LauncherIdent, r.cr.Spec.ExtraLabels)
+	srv.Spec.Selector = map[string]string{
+		"app":            "sf",
+		launcherPodLabel: "true",
+	}
+	r.EnsureService(&srv)
+
+	if r.IsNodepoolBuilderEnabled() {
+		deployments[BuilderIdent] = r.DeployNodepoolBuilder(statsdVolume, nodepoolStatsdMappingConfig,
+			volumeMounts, nodepoolProvidersSecrets, providerSecretsResourceExists)
+	} else {
+		r.TerminateNodepoolBuilder()
+	}
 	return deployments
 }
+
+// TerminateNodepoolBuilder removes the nodepool-builder resources, including the images storage
+func (r *SFController) TerminateNodepoolBuilder() {
+	r.DeleteR(&apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BuilderIdent,
+			Namespace: r.Ns,
+		},
+	})
+	r.DeleteR(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BuilderIdent,
+			Namespace: r.Ns,
+		},
+	})
+	r.DeleteR(&apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BuilderIdent + "-" + BuilderIdent + "-0",
+			Namespace: r.Ns,
+		},
+	})
+}
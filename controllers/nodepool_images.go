@@ -0,0 +1,142 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the helpers to inspect and manage the nodepool diskimage builds.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// nodepoolBuildLogDir is the build-log-dir of the nodepool-builder configuration
+const nodepoolBuildLogDir = "/var/lib/nodepool/builds/logs"
+
+// NodepoolDibImage is a diskimage build, as returned by the dib-image-list endpoint of the launcher webapp
+type NodepoolDibImage struct {
+	// ID is the image name followed by the build id
+	ID      string   `json:"id"`
+	Image   string   `json:"image"`
+	Builder string   `json:"builder"`
+	Formats []string `json:"formats"`
+	State   string   `json:"state"`
+	// Age is the timestamp of the last state change
+	Age float64 `json:"age"`
+}
+
+// NodepoolImageUpload is an image upload, as returned by the image-list endpoint of the launcher webapp
+type NodepoolImageUpload struct {
+	ID       string  `json:"id"`
+	BuildID  string  `json:"build_id"`
+	UploadID string  `json:"upload_id"`
+	Image    string  `json:"image"`
+	Provider string  `json:"provider"`
+	State    string  `json:"state"`
+	Age      float64 `json:"age"`
+}
+
+// BuildName returns the name of the diskimage build of the upload, as used by the dib-image-list IDs
+func (u NodepoolImageUpload) BuildName() string {
+	return u.Image + "-" + u.BuildID
+}
+
+// getNodepoolLauncherAPI returns the JSON document of an endpoint of the launcher webapp
+func (r *SFKubeContext) getNodepoolLauncherAPI(endpoint string, result any) error {
+	pod, err := r.getNodepoolLauncherPod()
+	if err != nil {
+		return err
+	}
+	script := "import sys, urllib.request\n" +
+		"req = urllib.request.Request(sys.argv[1], headers={'Accept': 'application/json'})\n" +
+		"sys.stdout.write(urllib.request.urlopen(req).read().decode())\n"
+	url := "http://localhost:" + strconv.Itoa(launcherPort) + "/" + endpoint
+	out, err := r.PodExecBytes(pod, "launcher", []string{"python3", "-c", script, url})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(out.Bytes(), result)
+}
+
+// GetNodepoolDibImages returns the diskimage builds known by nodepool
+func (r *SFKubeContext) GetNodepoolDibImages() ([]NodepoolDibImage, error) {
+	images := []NodepoolDibImage{}
+	err := r.getNodepoolLauncherAPI("dib-image-list", &images)
+	return images, err
+}
+
+// GetNodepoolImageUploads returns the uploads of the diskimage builds to the providers
+func (r *SFKubeContext) GetNodepoolImageUploads() ([]NodepoolImageUpload, error) {
+	uploads := []NodepoolImageUpload{}
+	err := r.getNodepoolLauncherAPI("image-list", &uploads)
+	return uploads, err
+}
+
+// getNodepoolBuilderPod returns the name of the nodepool-builder pod, when the builder is deployed
+func (r *SFKubeContext) getNodepoolBuilderPod() (string, error) {
+	name := BuilderIdent + "-0"
+	var pod apiv1.Pod
+	if !r.GetOrDie(name, &pod) || pod.Status.Phase != apiv1.PodRunning {
+		return "", errors.New("no running " + BuilderIdent + " pod, is the builder enabled?")
+	}
+	return name, nil
+}
+
+// GetNodepoolBuildLogs returns the builds of an image having a log on the builder, the most recent first.
+// The logs are kept after the failed builds are cleaned up by nodepool.
+func (r *SFKubeContext) GetNodepoolBuildLogs(image string) ([]string, error) {
+	pod, err := r.getNodepoolBuilderPod()
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.PodExecBytes(pod, BuilderIdent, []string{"ls", "-t", nodepoolBuildLogDir})
+	if err != nil {
+		return nil, err
+	}
+	// The build ids do not hold a dash, which tells apart the images sharing a prefix
+	logRe := regexp.MustCompile("^" + regexp.QuoteMeta(image) + `-[0-9a-zA-Z]+\.log$`)
+	builds := []string{}
+	for _, name := range strings.Fields(out.String()) {
+		if logRe.MatchString(name) {
+			builds = append(builds, strings.TrimSuffix(name, ".log"))
+		}
+	}
+	return builds, nil
+}
+
+// StreamNodepoolBuildLog writes the diskimage-builder log of a build, and keeps on following it when follow is set
+func (r *SFKubeContext) StreamNodepoolBuildLog(build string, follow bool, out io.Writer) error {
+	pod, err := r.getNodepoolBuilderPod()
+	if err != nil {
+		return err
+	}
+	command := []string{"cat", nodepoolBuildLogDir + "/" + build + ".log"}
+	if follow {
+		command = []string{"tail", "-n", "+1", "-f", nodepoolBuildLogDir + "/" + build + ".log"}
+	}
+	return r.PodExecOut(pod, BuilderIdent, command, out)
+}
+
+// NodepoolImageBuild requests a new build of a diskimage
+func (r *SFKubeContext) NodepoolImageBuild(image string) error {
+	pod, err := r.getNodepoolBuilderPod()
+	if err != nil {
+		return err
+	}
+	return r.PodExec(pod, BuilderIdent, []string{"nodepool", "image-build", image})
+}
+
+// NodepoolDibImageDelete deletes a diskimage build along with its uploads
+func (r *SFKubeContext) NodepoolDibImageDelete(build string) error {
+	pod, err := r.getNodepoolBuilderPod()
+	if err != nil {
+		return err
+	}
+	return r.PodExec(pod, BuilderIdent, []string{"nodepool", "dib-image-delete", build})
+}
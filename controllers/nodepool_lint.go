@@ -0,0 +1,137 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+//
+// This package contains the lint of the nodepool configuration of a local config repository.
+
+package controllers
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	apiv1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodepoolLintFiles are the config repository files used to render the nodepool configuration
+var nodepoolLintFiles = []string{"nodepool/nodepool.yaml", "nodepool/nodepool-builder.yaml", "zuul.yaml", ".zuul.yaml"}
+
+// nodepoolLintDirs are the config repository directories holding the Zuul nodesets
+var nodepoolLintDirs = []string{"zuul.d", ".zuul.d"}
+
+// mkNodepoolLintArchive returns a tar archive of the config repository files used by the lint
+func mkNodepoolLintArchive(configRepo string) (*bytes.Buffer, error) {
+	paths := []string{}
+	for _, file := range nodepoolLintFiles {
+		if _, err := os.Stat(filepath.Join(configRepo, file)); err == nil {
+			paths = append(paths, file)
+		}
+	}
+	for _, dir := range nodepoolLintDirs {
+		root := filepath.Join(configRepo, dir)
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(configRepo, path)
+			paths = append(paths, rel)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Join(configRepo, path))
+		if err != nil {
+			return nil, err
+		}
+		header := tar.Header{Name: filepath.ToSlash(path), Mode: 0644, Size: int64(len(data))}
+		if err := tw.WriteHeader(&header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	return &buf, tw.Close()
+}
+
+// mkNodepoolLintScript returns the script rendering the nodepool configuration of the config repository copy
+// with the pod's generate-config.sh, then validating and linting the result
+func mkNodepoolLintScript(preview bool, withLauncherConfig bool) string {
+	script := `set -e
+DIR=$(mktemp -d)
+trap "rm -rf $DIR" EXIT
+mkdir $DIR/config $DIR/home
+tar -x -C $DIR/config
+# generate-config.sh renders in HOME, which must not be the output directory
+render() {
+  if ! env -u NODEPOOL_PROVIDERS -u NODEPOOL_EXCLUDED_PROVIDERS HOME=$DIR/home NODEPOOL_CONFIG_FILE=$1 NODEPOOL_LOCAL_CONFIG=$DIR/config NODEPOOL_CONFIG_OUTPUT=$DIR/$1 \
+      /usr/local/bin/generate-config.sh > $DIR/render.log 2>&1; then
+    cat $DIR/render.log
+    exit 1
+  fi
+}
+render nodepool.yaml
+render nodepool-builder.yaml
+`
+	if preview {
+		script += `echo "# Rendered nodepool.yaml"
+cat $DIR/nodepool.yaml
+echo "# Rendered nodepool-builder.yaml"
+cat $DIR/nodepool-builder.yaml
+`
+	}
+	script += `ERR=0
+nodepool -c $DIR/nodepool.yaml config-validate || ERR=1
+nodepool -c $DIR/nodepool-builder.yaml config-validate || ERR=1
+`
+	if withLauncherConfig {
+		script += "/usr/local/bin/nodepool-lint.py --zuul-config $DIR/config $DIR/nodepool.yaml || ERR=1\n"
+	} else {
+		script += "/usr/local/bin/nodepool-lint.py $DIR/nodepool.yaml || ERR=1\n"
+	}
+	return script + "exit $ERR\n"
+}
+
+// getNodepoolLauncherPod returns the name of a running nodepool-launcher pod
+func (r *SFKubeContext) getNodepoolLauncherPod() (string, error) {
+	var podList apiv1.PodList
+	if err := r.Client.List(r.Ctx, &podList, client.InNamespace(r.Ns), client.MatchingLabels{"run": LauncherIdent}); err != nil {
+		return "", err
+	}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == apiv1.PodRunning {
+			return pod.Name, nil
+		}
+	}
+	return "", errors.New("no running " + LauncherIdent + " pod")
+}
+
+// LintNodepoolConfig renders the nodepool configuration of a local config repository in a nodepool-launcher pod,
+// exactly as a config-update would, then validates it and checks it against the providers secret and the Zuul nodesets.
+// When preview is set, the rendered configuration is displayed.
+func (r *SFKubeContext) LintNodepoolConfig(configRepo string, preview bool) error {
+	archive, err := mkNodepoolLintArchive(configRepo)
+	if err != nil {
+		return err
+	}
+	pod, err := r.getNodepoolLauncherPod()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(filepath.Join(configRepo, "nodepool", "nodepool.yaml"))
+	script := mkNodepoolLintScript(preview, err == nil)
+	return r.PodExecIn(pod, "launcher", []string{"bash", "-c", script}, archive)
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2026 Red Hat
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/client"
+)
+
+func TestNodepoolProvidersNames(t *testing.T) {
+	names := GetNodepoolProvidersNames(map[string][]byte{
+		"clouds.yaml": []byte("clouds:\n  vexxhost:\n    auth:\n      password: secret\n  rdo: {}\n"),
+		"kube.config": []byte("contexts:\n- name: openshiftpods\n  context:\n    cluster: c\n"),
+		"aws.config":  []byte("[default]\nregion = us-east-1\n[profile prod]\nregion = eu-west-1\n"),
+	})
+	if !slices.Equal(names.Clouds, []string{"rdo", "vexxhost"}) {
+		t.Errorf("Unexpected clouds: %v", names.Clouds)
+	}
+	if !slices.Equal(names.Contexts, []string{"openshiftpods"}) {
+		t.Errorf("Unexpected contexts: %v", names.Contexts)
+	}
+	if !slices.Equal(names.Profiles, []string{"default", "prod"}) {
+		t.Errorf("Unexpected profiles: %v", names.Profiles)
+	}
+
+	empty := GetNodepoolProvidersNames(nil)
+	if len(empty.Clouds)+len(empty.Contexts)+len(empty.Profiles) != 0 {
+		t.Errorf("An empty secret must not define any provider: %v", empty)
+	}
+}
+
+func TestNodepoolLauncherGroups(t *testing.T) {
+	spec := sfv1.NodepoolLauncherSpec{Groups: []sfv1.NodepoolLauncherGroupSpec{
+		{Name: "vexxhost", Providers: []string{"vexxhost-nodepool-sf", "vexxhost"}},
+		{Name: "kube", Providers: []string{"openshiftpods"}},
+	}}
+	if err := ValidateNodepoolLauncherGroups(spec); err != nil {
+		t.Fatalf("Unexpected validation error: %s", err)
+	}
+	cloudsYaml := map[string]interface{}{"clouds": map[string]interface{}{
+		"vexxhost": map[string]interface{}{"metrics": map[string]interface{}{"statsd": map[string]interface{}{"prefix": "openstack.api.vexxhost"}}},
+		"rdo":      map[string]interface{}{"metrics": map[string]interface{}{"statsd": map[string]interface{}{"prefix": "openstack.api.rdo"}}},
+	}}
+	groups := mkNodepoolLauncherGroups(spec, cloudsYaml, "all clouds mapping")
+	if len(groups) != 3 || !groups[0].Main || groups[0].Name != "nodepool-launcher" || groups[0].StatsdMapping != "all clouds mapping" {
+		t.Fatalf("The nodepool-launcher deployment must come first: %v", groups)
+	}
+	if groups[0].Env[0].Value != "openshiftpods,vexxhost,vexxhost-nodepool-sf" {
+		t.Errorf("Unexpected excluded providers: %v", groups[0].Env)
+	}
+	vexxhost := groups[1]
+	if vexxhost.Name != "nodepool-launcher-vexxhost" || vexxhost.StatsdIdent != "np-statsd-vexxhost" || vexxhost.Env[0].Value != "vexxhost-nodepool-sf,vexxhost" {
+		t.Errorf("Unexpected launcher group: %v", vexxhost)
+	}
+	if !strings.Contains(vexxhost.StatsdMapping, "openstack.api.vexxhost") || strings.Contains(vexxhost.StatsdMapping, "openstack.api.rdo") {
+		t.Errorf("The group statsd mapping must only hold the clouds of its providers:\n%s", vexxhost.StatsdMapping)
+	}
+
+	if groups := mkNodepoolLauncherGroups(sfv1.NodepoolLauncherSpec{}, cloudsYaml, ""); len(groups) != 1 || len(groups[0].Env) != 0 {
+		t.Errorf("Without groups, the nodepool-launcher deployment must run every provider: %v", groups)
+	}
+
+	spec.Groups[1].Providers = []string{"vexxhost"}
+	if ValidateNodepoolLauncherGroups(spec) == nil {
+		t.Errorf("A provider part of two groups must be rejected")
+	}
+	spec.Groups[1] = spec.Groups[0]
+	if ValidateNodepoolLauncherGroups(spec) == nil {
+		t.Errorf("Duplicate group names must be rejected")
+	}
+}
+
+// runNodepoolScript runs a script with bash, the nodepool and nodepool-lint.py commands being stubs
+func runNodepoolScript(t *testing.T, dir string, script string, stdin io.Reader, env ...string) string {
+	bin := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stubs := map[string]string{
+		"nodepool": "#!/bin/sh\nexit 0\n",
+		// The lint stub checks that the launcher configuration is linted, not the builder one
+		"nodepool-lint.py":   "#!/bin/bash\ngrep -q launcher-label \"${@: -1}\" && ! grep -q builder-label \"${@: -1}\"\n",
+		"generate-config.sh": (&SFController{SFKubeContext: SFKubeContext{KubeClient: client.KubeClient{Ns: "sf"}}}).generateConfigScript(),
+	}
+	for name, content := range stubs {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script = strings.ReplaceAll(script, "/usr/local/bin/", bin+"/")
+	cmd := exec.Command("bash", "-c", script)
+	cmd.Env = append(os.Environ(), append(env, "PATH="+bin+":"+os.Getenv("PATH"))...)
+	cmd.Stdin = stdin
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("The script failed: %s\n%s", err, out)
+	}
+	return string(out)
+}
+
+func TestGenerateNodepoolConfig(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not available")
+	}
+	configRepo := map[string]string{
+		"nodepool/nodepool.yaml":         "labels:\n  - name: launcher-label\n",
+		"nodepool/nodepool-builder.yaml": "labels:\n  - name: builder-label\n",
+	}
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	for path, content := range configRepo {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(config, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(config, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The config-check job used to render with the output directory as HOME
+	out := filepath.Join(dir, "out")
+	if err := os.MkdirAll(out, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runNodepoolScript(t, dir, "bash /usr/local/bin/generate-config.sh", nil,
+		"HOME="+out, "NODEPOOL_LOCAL_CONFIG="+config, "NODEPOOL_CONFIG_OUTPUT="+out+"/nodepool.yaml")
+	rendered, err := os.ReadFile(filepath.Join(out, "nodepool.yaml"))
+	if err != nil || !strings.Contains(string(rendered), "launcher-label") || !strings.Contains(string(rendered), "zookeeper-0") {
+		t.Errorf("Unexpected rendered configuration: %s %v", rendered, err)
+	}
+
+	// The lint renders both configurations, then lints the launcher one
+	archive, err := mkNodepoolLintArchive(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runNodepoolScript(t, dir, mkNodepoolLintScript(true, true), archive)
+}
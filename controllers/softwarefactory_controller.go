@@ -16,7 +16,6 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"gopkg.in/yaml.v3"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -24,6 +23,7 @@ import (
 	"k8s.io/utils/strings/slices"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 
 	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/conds"
@@ -250,20 +250,51 @@ func GetUserDefinedConnections(zuul *sfv1.ZuulSpec) ([]string, error) {
 	return conns, nil
 }
 
+// GetZuulConnections returns the names of the connections of the Zuul configuration, including the default ones
+func GetZuulConnections(zuul *sfv1.ZuulSpec) ([]string, error) {
+	conns, err := GetUserDefinedConnections(zuul)
+	if err != nil {
+		return conns, err
+	}
+	conns = append(conns, "git-server")
+	if !slices.Contains(conns, "opendev.org") {
+		conns = append(conns, "opendev.org")
+	}
+	return conns, nil
+}
+
 func (r *SFController) IsCodesearchEnabled() bool {
 	return r.cr.Spec.Codesearch.Enabled == nil || *r.cr.Spec.Codesearch.Enabled
 }
 
+func (r *SFController) IsLogJuicerEnabled() bool {
+	return r.cr.Spec.Logjuicer.Enabled == nil || *r.cr.Spec.Logjuicer.Enabled
+}
+
+func (r *SFController) IsWeederEnabled() bool {
+	return r.cr.Spec.Weeder.Enabled == nil || *r.cr.Spec.Weeder.Enabled
+}
+
+func (r *SFController) IsNodepoolBuilderEnabled() bool {
+	return r.cr.Spec.Nodepool.Builder.Enabled == nil || *r.cr.Spec.Nodepool.Builder.Enabled
+}
+
+func (r *SFController) IsZuulCapacityEnabled() bool {
+	return r.cr.Spec.ZuulCapacity.Enabled == nil || *r.cr.Spec.ZuulCapacity.Enabled
+}
+
 func (r *SFController) EnsureToolingVolume() {
 	schedulerToolingData := make(map[string]string)
 	schedulerToolingData["init-container.sh"] = zuulSchedulerInitContainerScript
 	schedulerToolingData["generate-zuul-tenant-yaml.sh"] = zuulGenerateTenantConfig
 	schedulerToolingData["reconnect-zk.py"] = zuulReconnectZK
 	schedulerToolingData["rotate-keystore.py"] = zuulRotateKeystore
+	schedulerToolingData["prune-builds.py"] = zuulPruneBuilds
 	schedulerToolingData["fetch-config-repo.sh"] = fetchConfigRepoScript
 	schedulerToolingData["hound-search-init.sh"] = houndSearchInit
 	schedulerToolingData["hound-search-config.sh"] = houndSearchConfig
 	schedulerToolingData["hound-search-render.py"] = houndSearchRender
+	schedulerToolingData["hound-search-credentials.py"] = houndSearchCredentials
 	schedulerToolingData["zuul-change-dump.py"], _ = utils.ParseString(zuulChangeDump, struct {
 		ZuulWebURL string
 	}{ZuulWebURL: "https://" + r.cr.Spec.FQDN + "/zuul"})
@@ -321,7 +352,9 @@ func (r *SFController) deployZKAndZuulAndNodepool(services map[string]bool) map[
 	logging.LogI("Deploying Zuul and Nodepool ...")
 	nodepool := r.DeployNodepool()
 	services["NodePoolLauncher"] = nodepool[LauncherIdent]
-	services["NodePoolBuilder"] = nodepool[BuilderIdent]
+	if r.IsNodepoolBuilderEnabled() {
+		services["NodePoolBuilder"] = nodepool[BuilderIdent]
+	}
 	zuulComponentsStatus := r.DeployZuul()
 	services["Zuul"] = zuulComponentsStatus["Zuul"]
 	if !services["Zuul"] {
@@ -345,7 +378,12 @@ func (r *SFController) deploySFStep(services map[string]bool) map[string]bool {
 	// The git server service is needed to store system jobs
 	services["GitServer"] = r.DeployGitServer()
 	// The MariaDB service is needed by Zuul to store build results metadata
-	services["MariaDB"] = r.DeployMariadb()
+	// unless an external database is configured
+	if r.cr.Spec.ExternalDatabase != nil {
+		services["MariaDB"] = r.DeployExternalDatabase()
+	} else {
+		services["MariaDB"] = r.DeployMariadb()
+	}
 	// The Logserver service is needed by Zuul to store build artifacts
 	services["Logserver"] = r.DeployLogserver()
 	// The gateway is on redirect incoming HTTP request to backing services
@@ -357,8 +395,11 @@ func (r *SFController) deploySFStep(services map[string]bool) map[string]bool {
 		r.TerminateHoundSearch()
 	}
 	// The Logjuicer is a log analysis service suitable for Zuul
-	// TODO: make this configurable
-	services["LogJuicer"] = r.EnsureLogJuicer()
+	if r.IsLogJuicerEnabled() {
+		services["LogJuicer"] = r.EnsureLogJuicer()
+	} else {
+		r.TerminateLogJuicer()
+	}
 
 	// 3. Deploy Zuul, Nodepool and Zookeeper
 	// --------------------------------------
@@ -405,6 +446,9 @@ func (r *SFController) Step() sfv1.SoftwareFactoryStatus {
 	logging.LogI(messageInfo(services))
 
 	isReady := isOperatorReady(services)
+	if r.cr.Spec.Zuul.Executor.Standalone == nil {
+		r.EnsureGatewayMaintenance(services)
+	}
 
 	return sfv1.SoftwareFactoryStatus{
 		Ready:              isReady,
@@ -432,20 +476,52 @@ func HasDuplicate(conns []string) string {
 	return ""
 }
 
-func MkSFController(r SFKubeContext, cr sfv1.SoftwareFactory) SFController {
+// ValidateSpec runs the checks of a SoftwareFactory resource that do not need the cluster.
+// The returned warnings report the settings that are valid but likely unexpected.
+func ValidateSpec(cr sfv1.SoftwareFactory) ([]string, error) {
 	conns, err := GetUserDefinedConnections(&cr.Spec.Zuul)
 	if err != nil {
-		ctrl.Log.Error(err, "Invalid Zuul connections")
-		os.Exit(1)
+		return nil, fmt.Errorf("invalid Zuul connections: %w", err)
 	}
 	if dup := HasDuplicate(conns); dup != "" {
-		fmt.Fprintf(os.Stderr, "Duplicate zuul connection: %s", dup)
-		os.Exit(1)
+		return nil, errors.New("duplicate zuul connection: " + dup)
 	}
 	if slices.Contains(conns, "git-server") {
-		fmt.Fprintf(os.Stderr, "The git-server connection name is reserved, please rename it")
+		return nil, errors.New("the git-server connection name is reserved, please rename it")
+	}
+	configConn := cr.Spec.ConfigRepositoryLocation.ZuulConnectionName
+	if configConn != "" && configConn != "git-server" && !slices.Contains(conns, configConn) {
+		return nil, errors.New("the config repository connection is not defined: " + configConn)
+	}
+	if err := ValidateLogserver(cr.Spec.Logserver); err != nil {
+		return nil, fmt.Errorf("invalid logserver settings: %w", err)
+	}
+	if err := ValidateGateway(cr.Spec); err != nil {
+		return nil, fmt.Errorf("invalid gateway settings: %w", err)
+	}
+	if err := ValidateNodepoolLauncherGroups(cr.Spec.Nodepool.Launcher); err != nil {
+		return nil, fmt.Errorf("invalid nodepool launcher groups: %w", err)
+	}
+	warnings, err := ValidateBuildRetention(cr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Zuul build retention: %w", err)
+	}
+	for i, warning := range warnings {
+		warnings[i] = "Build retention mismatch: " + warning
+	}
+	return warnings, nil
+}
+
+func MkSFController(r SFKubeContext, cr sfv1.SoftwareFactory) SFController {
+	warnings, err := ValidateSpec(cr)
+	if err != nil {
+		ctrl.Log.Error(err, "Invalid SoftwareFactory resource")
 		os.Exit(1)
 	}
+	for _, warning := range warnings {
+		ctrl.Log.Info(warning)
+	}
+	conns, _ := GetUserDefinedConnections(&cr.Spec.Zuul)
 	return SFController{
 		SFKubeContext: r,
 		cr:            cr,
@@ -497,6 +573,8 @@ func (r *SFKubeContext) StandaloneReconcile(sf sfv1.SoftwareFactory) error {
 	controllerAnnotations := map[string]string{
 		"sf-operator-version": utils.GetVersion(),
 		"last-reconcile":      strconv.FormatInt(time.Now().Unix(), 10),
+		"sf-name":             sf.Name,
+		"sf-fqdn":             sf.Spec.FQDN,
 	}
 	r.EnsureStandaloneOwner(sf.Spec)
 	sfCtrl := MkSFController(*r, sf)
@@ -64,7 +64,39 @@ cat << EOF > playbooks/base/pre.yaml
       when: ansible_user_dir is defined
 EOF
 
-cat << EOF > playbooks/base/post.yaml
+if [ "${LOGSERVER_BACKEND}" == "s3" ]; then
+  cat << EOF > playbooks/base/post.yaml
+- hosts: localhost
+  roles:
+    -  role: generate-zuul-manifest
+  tasks:
+    - block:
+        - import_role:
+            name: upload-logs-s3
+        - import_role:
+            name: buildset-artifacts-location
+      vars:
+        zuul_log_bucket: "{{ site_sflogs.bucket }}"
+        upload_logs_s3_endpoint: "{{ site_sflogs.endpoint }}"
+        zuul_log_aws_access_key: "{{ site_sflogs.access_key }}"
+        zuul_log_aws_secret_key: "{{ site_sflogs.secret_key }}"
+        zuul_log_create_indexes: true
+        zuul_log_url: "https://${FQDN}/logs"
+    # The logs are served by the gateway, not by the bucket endpoint
+    - name: Return the log URL
+      zuul_return:
+        data:
+          zuul:
+            log_url: "https://${FQDN}/logs/{{ zuul_log_path }}/"
+    - when: not zuul_success | bool
+      include_role:
+        name: report-logjuicer
+      vars:
+        logjuicer_web_url: https://${FQDN}/logjuicer
+        zuul_web_url: https://${FQDN}/zuul/t/{{ zuul.tenant }}
+EOF
+else
+  cat << EOF > playbooks/base/post.yaml
 - hosts: localhost
   roles:
     -  role: add-fileserver
@@ -92,11 +124,25 @@ cat << EOF > playbooks/base/post.yaml
         zuul_logserver_root: "{{ site_sflogs.path }}"
         zuul_log_verbose: true
 EOF
+fi
 
 cat << EOF > playbooks/config/zuul-connections.txt
 # ZUUL_CONNECTIONS
 EOF
 
+# The nodepool configuration is rendered and linted by the config-check job with the pods tooling
+cat << 'NODEPOOL_EOF' > playbooks/config/generate-nodepool-config.sh
+# NODEPOOL_GENERATE_CONFIG
+NODEPOOL_EOF
+
+cat << 'NODEPOOL_EOF' > playbooks/config/nodepool-lint.py
+# NODEPOOL_LINT
+NODEPOOL_EOF
+
+cat << 'NODEPOOL_EOF' > playbooks/config/nodepool-providers.yaml
+# NODEPOOL_PROVIDERS
+NODEPOOL_EOF
+
 cat << EOF > playbooks/config/check.yaml
 - hosts: localhost
   vars:
@@ -105,6 +151,8 @@ cat << EOF > playbooks/config/check.yaml
     zuul_connections: "{{ lookup('file', 'zuul-connections.txt') }}"
     nodepool_launcher_config: "{{ config_root }}/nodepool/nodepool.yaml"
     nodepool_builder_config: "{{ config_root }}/nodepool/nodepool-builder.yaml"
+    nodepool_rendered_dir: "{{ zuul.executor.work_root }}/nodepool-rendered"
+    nodepool_render_home: "{{ zuul.executor.work_root }}/nodepool-render-home"
   tasks:
     - name: Ensure Zuul tenant config exists
       shell: |
@@ -131,25 +179,40 @@ cat << EOF > playbooks/config/check.yaml
       # clearing the env fixes that issue.
       command: env - PATH=/usr/local/bin:/bin zuul-admin -c "{{ zuul_config }}.conf" tenant-conf-check
 
-    - name: Ensure Nodepool launcher config exists
-      shell: |
-        if ! test -f "{{ nodepool_launcher_config }}"; then
-          mkdir -p "{{ config_root }}/nodepool/"
-          echo {} > "{{ nodepool_launcher_config }}"
-        fi
+    - name: Ensure Nodepool rendered config directories exist
+      ansible.builtin.file:
+        path: "{{ item }}"
+        state: directory
+      loop:
+        - "{{ nodepool_rendered_dir }}"
+        - "{{ nodepool_render_home }}"
+
+    - name: Render Nodepool launcher config
+      command: env - PATH=/usr/local/bin:/bin HOME={{ nodepool_render_home }} NODEPOOL_LOCAL_CONFIG={{ config_root }} NODEPOOL_CONFIG_OUTPUT={{ nodepool_rendered_dir }}/nodepool.yaml bash generate-nodepool-config.sh
+      args:
+        chdir: "{{ playbook_dir }}"
 
     - name: Validate Nodepool launcher config
-      command: env - PATH=/usr/local/bin:/bin nodepool -c "{{ nodepool_launcher_config }}" config-validate
+      command: env - PATH=/usr/local/bin:/bin nodepool -c "{{ nodepool_rendered_dir }}/nodepool.yaml" config-validate
 
-    - name: Ensure Nodepool builder config exists
-      shell: |
-        if ! test -f "{{ nodepool_builder_config }}"; then
-          mkdir -p "{{ config_root }}/nodepool/"
-          echo {} > "{{ nodepool_builder_config }}"
-        fi
+    - name: Check if the config repository holds a Nodepool launcher config
+      ansible.builtin.stat:
+        path: "{{ nodepool_launcher_config }}"
+      register: nodepool_launcher_config_stat
+
+    - name: Lint Nodepool launcher config against the providers secret and the Zuul nodesets
+      command: env - PATH=/usr/local/bin:/bin python3 nodepool-lint.py --providers-names nodepool-providers.yaml --zuul-config {{ config_root }} {{ nodepool_rendered_dir }}/nodepool.yaml
+      args:
+        chdir: "{{ playbook_dir }}"
+      when: nodepool_launcher_config_stat.stat.exists
+
+    - name: Render Nodepool builder config
+      command: env - PATH=/usr/local/bin:/bin HOME={{ nodepool_render_home }} NODEPOOL_CONFIG_FILE=nodepool-builder.yaml NODEPOOL_LOCAL_CONFIG={{ config_root }} NODEPOOL_CONFIG_OUTPUT={{ nodepool_rendered_dir }}/nodepool-builder.yaml bash generate-nodepool-config.sh
+      args:
+        chdir: "{{ playbook_dir }}"
 
     - name: Validate Nodepool builder config
-      command: env - PATH=/usr/local/bin:/bin nodepool -c "{{ nodepool_builder_config }}" config-validate
+      command: env - PATH=/usr/local/bin:/bin nodepool -c "{{ nodepool_rendered_dir }}/nodepool-builder.yaml" config-validate
 EOF
 
 cat << EOF > playbooks/config/update.yaml
@@ -176,6 +239,10 @@ cat << EOF > playbooks/config/update.yaml
     - name: "Update nodepool-launcher config"
       command: /usr/local/bin/generate-config.sh "{{ config_ref }}"
 
+EOF
+
+if [ "${NODEPOOL_BUILDER}" != "false" ]; then
+  cat << EOF >> playbooks/config/update.yaml
 - hosts: nodepool-builder
   vars:
     config_ref: "{{ zuul.newrev | default('origin/master') }}"
@@ -186,6 +253,10 @@ cat << EOF > playbooks/config/update.yaml
       environment:
         NODEPOOL_CONFIG_FILE: nodepool-builder.yaml
 
+EOF
+fi
+
+cat << EOF >> playbooks/config/update.yaml
 # Handle code-search config-update tasks
 # The StatefulSet controller will restart the pod after the deletion
 # Ideally we should run a rollout restart but kubectl does not have that capability, or better, run the hound config update script in-place but hound does not support hot reload.
@@ -211,18 +282,25 @@ cat << EOF > roles/add-k8s-hosts/tasks/main.yaml
     ansible_kubectl_pod: "zuul-scheduler-0"
     ansible_kubectl_kubeconfig: "{{ ansible_env.HOME }}/.kube/config"
 
-- name: Fetch nodepool-launcher Pod info
-  command: "kubectl get pod -o=custom-columns=NAME:.metadata.name --no-headers --selector=run=nodepool-launcher"
+# The pods of the nodepool-launcher deployment and of the launcher groups
+- name: Fetch nodepool-launcher Pods info
+  command: "kubectl get pod -o=custom-columns=NAME:.metadata.name --no-headers --selector=nodepool-launcher=true"
   register: nodepool_launcher_info
   environment:
     KUBECONFIG: "{{ ansible_env.HOME }}/.kube/config"
 
 - ansible.builtin.add_host:
-    name: "nodepool-launcher"
+    name: "{{ item }}"
+    groups: nodepool-launcher
     ansible_connection: kubectl
-    ansible_kubectl_pod: "{{ nodepool_launcher_info.stdout }}"
+    ansible_kubectl_pod: "{{ item }}"
     ansible_kubectl_container: launcher
     ansible_kubectl_kubeconfig: "{{ ansible_env.HOME }}/.kube/config"
+  loop: "{{ nodepool_launcher_info.stdout_lines }}"
+EOF
+
+if [ "${NODEPOOL_BUILDER}" != "false" ]; then
+  cat << EOF >> roles/add-k8s-hosts/tasks/main.yaml
 
 - ansible.builtin.add_host:
     name: "nodepool-builder"
@@ -231,6 +309,7 @@ cat << EOF > roles/add-k8s-hosts/tasks/main.yaml
     ansible_kubectl_pod: "nodepool-builder-0"
     ansible_kubectl_kubeconfig: "{{ ansible_env.HOME }}/.kube/config"
 EOF
+fi
 
 mkdir -p roles/setup-k8s-config/tasks
 cat << EOF > roles/setup-k8s-config/tasks/main.yaml
@@ -0,0 +1,115 @@
+#!/bin/env python3
+# Copyright (C) 2026 Red Hat
+# SPDX-License-Identifier: Apache-2.0
+#
+# A git credential helper providing the credentials of the Zuul connections,
+# so that hound can index the private repositories.
+# See https://git-scm.com/docs/gitcredentials#_custom_helpers
+
+import base64
+import configparser
+import json
+import subprocess
+import sys
+import time
+import urllib.parse
+import urllib.request
+
+
+def b64url(data):
+    return base64.urlsafe_b64encode(data).rstrip(b"=").decode()
+
+
+def mk_app_jwt(app_id, app_key):
+    """Create the JWT authenticating the GitHub app, signed with openssl"""
+    now = int(time.time())
+    header = b64url(json.dumps({"alg": "RS256", "typ": "JWT"}).encode())
+    payload = b64url(json.dumps(
+        {"iat": now - 60, "exp": now + 540, "iss": app_id}).encode())
+    signing_input = f"{header}.{payload}".encode()
+    signature = subprocess.run(
+        ["openssl", "dgst", "-sha256", "-sign", app_key],
+        input=signing_input, capture_output=True, check=True).stdout
+    return f"{header}.{payload}.{b64url(signature)}"
+
+
+def github_request(api, path, jwt, method="GET"):
+    req = urllib.request.Request(api + path, method=method, headers={
+        "Authorization": f"Bearer {jwt}",
+        "Accept": "application/vnd.github+json",
+    })
+    with urllib.request.urlopen(req, timeout=30) as resp:
+        return json.load(resp)
+
+
+def github_app_token(server, app_id, app_key, repo):
+    """Get an installation token of the GitHub app for the repository"""
+    if server == "github.com":
+        api = "https://api.github.com"
+    else:
+        api = f"https://{server}/api/v3"
+    jwt = mk_app_jwt(app_id, app_key)
+    installation = github_request(api, f"/repos/{repo}/installation", jwt)
+    token = github_request(
+        api, f"/app/installations/{installation['id']}/access_tokens", jwt,
+        method="POST")
+    return token["token"]
+
+
+def connection_host(parser, section):
+    driver = parser.get(section, "driver")
+    if parser.has_option(section, "baseurl"):
+        return urllib.parse.urlparse(parser.get(section, "baseurl")).hostname
+    server = parser.get(section, "server", fallback="localhost")
+    if driver == "github" and server == "localhost":
+        # The operator sets the server to localhost when it is not defined
+        return "github.com"
+    return server
+
+
+def get_credentials(parser, host, path):
+    repo = path.strip("/").removesuffix(".git")
+    for section in parser.sections():
+        if not section.startswith("connection "):
+            continue
+        if connection_host(parser, section) != host:
+            continue
+        driver = parser.get(section, "driver")
+        option = lambda name: parser.get(section, name, fallback="")
+        if driver == "github":
+            if option("app_id") and option("app_key"):
+                owner_repo = "/".join(repo.split("/")[:2])
+                return "x-access-token", github_app_token(
+                    host, option("app_id"), option("app_key"), owner_repo)
+            if option("api_token"):
+                return "x-access-token", option("api_token")
+        elif driver == "gitlab" and option("api_token"):
+            return "oauth2", option("api_token")
+        elif driver == "gerrit" and option("password"):
+            return option("user") or "zuul", option("password")
+    return None
+
+
+def main():
+    if len(sys.argv) < 2 or sys.argv[1] != "get":
+        return
+    request = dict(
+        line.split("=", 1) for line in sys.stdin.read().splitlines()
+        if "=" in line)
+    parser = configparser.ConfigParser(interpolation=None)
+    parser.read("/etc/zuul/zuul.conf")
+    try:
+        credentials = get_credentials(
+            parser, request.get("host", "").split(":")[0],
+            request.get("path", ""))
+    except Exception as e:
+        print(f"Unable to get the credentials of {request.get('host')}: {e}",
+              file=sys.stderr)
+        return
+    if credentials:
+        print(f"username={credentials[0]}")
+        print(f"password={credentials[1]}")
+
+
+if __name__ == "__main__":
+    main()
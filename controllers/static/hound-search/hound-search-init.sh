@@ -14,6 +14,12 @@ fi
 export HOME=/var/lib/hound
 mkdir -p ${HOME}/data
 cd $HOME
+if [ "${CODESEARCH_CREDENTIALS}" = "true" ]; then
+    git config --global credential.helper "!python3 /sf-tooling/hound-search-credentials.py"
+    git config --global credential.useHttpPath true
+else
+    git config --global --unset-all credential.helper || true
+fi
 if [ ! -z "${CONFIG_REPO_BASE_URL}" ]; then
     bash /sf-tooling/hound-search-config.sh
 fi
@@ -4,6 +4,8 @@
 
 import configparser
 import json
+import os
+import re
 import sys
 import yaml
 
@@ -24,10 +26,20 @@ def read_connections(zuul_conf):
                 url = ""
             # Get the connection name, driver and baseurl
             connections[kv[1]] = dict(
-                driver=parser.get(section, "driver"), baseurl=url)
+                driver=parser.get(section, "driver"), baseurl=url,
+                server=github_server(parser, section),
+                password=parser.has_option(section, "password"))
     return connections
 
 
+def github_server(parser, section):
+    """Return the GitHub server of the connection.
+
+    The operator sets the server to localhost when it is not defined"""
+    server = parser.get(section, "server", fallback="localhost")
+    return "github.com" if server == "localhost" else server
+
+
 test_zuul_conf = """
 [merger]
 [connection opendev.org]
@@ -45,12 +57,13 @@ driver              = github
 
 
 def read_repos(zuul_yaml):
-    """Read repositories from zuul main.yaml"""
+    """Read the (tenant, connection, repository) from zuul main.yaml"""
     tenants = yaml.safe_load(zuul_yaml)
     projs = []
     for tenant in tenants:
         if not tenant.get("tenant"):
             continue
+        tenant_name = tenant["tenant"].get("name")
         for conn, conf in tenant["tenant"].get("source", {}).items():
             for proj in conf.get("config-projects", []) + conf.get(
                 "untrusted-projects", []
@@ -58,11 +71,11 @@ def read_repos(zuul_yaml):
                 # TODO: add support for project group
                 if isinstance(proj, str):
                     # This is a literal project, assume default branch name
-                    projs.append((conn, proj))
+                    projs.append((tenant_name, conn, proj))
                 else:
                     # This is a project object, it's name is the first key
                     name = list(proj.keys())[0]
-                    projs.append((conn, name))
+                    projs.append((tenant_name, conn, name))
 
     return projs
 
@@ -88,13 +101,40 @@ test_zuul_yaml = """
 """
 
 
-def get_git_urls(conn, repo):
+def is_selected(selections, tenant, conn, repo):
+    """Check if the repository matches the codesearch repositories selection"""
+    scoped = [
+        sel for sel in selections
+        if sel.get("tenant", tenant) == tenant
+        and sel.get("connection", conn) == conn
+    ]
+    for sel in scoped:
+        if any(re.fullmatch(pat, repo) for pat in sel.get("exclude", [])):
+            return False
+    includes = [pat for sel in scoped for pat in sel.get("include", [])]
+    if includes:
+        return any(re.fullmatch(pat, repo) for pat in includes)
+    return True
+
+
+def get_branches(branches, repo):
+    """Return the branches to index, None being the default branch"""
+    for sel in branches:
+        if re.fullmatch(sel["project"], repo):
+            return sel["branches"]
+    return [None]
+
+
+def get_git_urls(conn, repo, credentials=False):
     """Create the hound URLs from the zuul connection and repo config."""
     base_url = conn["baseurl"]
     if conn["driver"] == "gerrit":
         uri = f"{base_url}/{repo}"
         if base_url.rstrip('/') == "https://gerrit.sfop.me":
             uri = f"http://gerrit-httpd:8080/{repo}"
+        elif credentials and conn["password"]:
+            # Gerrit serves the authenticated requests on the /a/ prefix
+            uri = f"{base_url}/a/{repo}"
         gitweb = (
             base_url
             + f"/plugins/gitiles/{repo}/+/{{rev}}/"
@@ -106,8 +146,9 @@ def get_git_urls(conn, repo):
                 "{path}{anchor}"
             anchor = "#L{line}"
     elif conn["driver"] == "github":
-        uri = f"http://github.com/{repo}"
-        gitweb = f"http://github.com/{repo}/blob/{{rev}}/" + "{path}{anchor}"
+        server = conn["server"]
+        uri = f"https://{server}/{repo}"
+        gitweb = f"https://{server}/{repo}/blob/{{rev}}/" + "{path}{anchor}"
         anchor = "#L{line}"
     elif conn["driver"] == "pagure":
         uri = base_url + f"/{repo}"
@@ -127,21 +168,32 @@ def get_git_urls(conn, repo):
     return uri, gitweb, anchor
 
 
-def render_hound(connections, projs):
+def render_hound(connections, projs, settings=None):
     """Create the hound-search config"""
+    settings = settings or {}
     repos = {}
-    for conn, repo in projs:
-        url, base_url, anchor = get_git_urls(connections[conn], repo)
+    credentials = settings.get("connectionCredentials", False)
+    poll = settings.get("reindexInterval", 12 * 60) * 60 * 1000
+    for tenant, conn, repo in projs:
+        if conn not in connections:
+            continue
+        if not is_selected(settings.get("repositories", []), tenant, conn, repo):
+            continue
+        url, base_url, anchor = get_git_urls(connections[conn], repo, credentials)
         if not url:
             continue
-        repos[repo] = {
-            "url": url,
-            "ms-between-poll": int(12 * 60 * 60 * 1000),
-            "url-pattern": {
-                "base-url": base_url,
-                "anchor": anchor,
-            },
-        }
+        for branch in get_branches(settings.get("branches", []), repo):
+            name = repo if branch is None else f"{repo}@{branch}"
+            repos[name] = {
+                "url": url,
+                "ms-between-poll": int(poll),
+                "url-pattern": {
+                    "base-url": base_url,
+                    "anchor": anchor,
+                },
+            }
+            if branch is not None:
+                repos[name]["vcs-config"] = {"ref": branch}
     return {
         "max-concurrent-indexers": 4,
         "dbpath": "/var/lib/hound/data",
@@ -163,6 +215,7 @@ def do_main():
         render_hound(
             read_connections(open("/etc/zuul/zuul.conf").read()),
             read_repos(zuul_yaml),
+            json.loads(os.environ.get("CODESEARCH_SETTINGS") or "{}"),
         )
     )
     open("/var/lib/hound/config.json", "w").write(conf)
@@ -234,6 +287,27 @@ def do_test():
         print("Bad config:")
         print(conf)
 
+    settings = {
+        "repositories": [
+            {"connection": "opendev.org", "include": ["zuul/.*"],
+             "exclude": ["zuul/zuul-jobs"]},
+            {"tenant": "demo-tenant", "exclude": ["demo-project-.*"]},
+        ],
+        "branches": [{"project": "demo-project", "branches": ["main", "v1"]}],
+        "reindexInterval": 60,
+    }
+    conf = render_hound(read_connections(test_zuul_conf),
+                        read_repos(test_zuul_yaml), settings)
+    if sorted(conf["repos"]) != [
+            "demo-project@main", "demo-project@v1",
+            "demo-tenant-config", "zuul/sandbox-config"]:
+        print("Bad repositories selection:")
+        print(sorted(conf["repos"]))
+    if conf["repos"]["demo-project@v1"]["vcs-config"] != {"ref": "v1"} or \
+            conf["repos"]["demo-tenant-config"]["ms-between-poll"] != 3600000:
+        print("Bad repository settings:")
+        print(conf["repos"])
+
 
 if __name__ == "__main__":
     if "test" in sys.argv:
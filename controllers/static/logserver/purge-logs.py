@@ -0,0 +1,177 @@
+#!/bin/env python3
+# Copyright (C) 2026 Red Hat
+# SPDX-License-Identifier: Apache-2.0
+#
+# Purge the logserver logs:
+# - the files older than the retention of their path are deleted,
+# - when the volume usage exceeds the high watermark, the oldest files are deleted
+#   until the usage is below the low watermark.
+# The purge statistics are written for the node-exporter textfile collector.
+
+import argparse
+import fnmatch
+import os
+import time
+
+
+class Stats:
+    def __init__(self):
+        self.files = {"retention": 0, "quota": 0}
+        self.bytes = {"retention": 0, "quota": 0}
+        self.runs = 0
+        self.duration = 0.0
+        self.last_run = 0.0
+        self.usage = 0.0
+
+    def write(self, directory):
+        lines = [
+            "# HELP logserver_purge_runs_total The number of purge runs.",
+            "# TYPE logserver_purge_runs_total counter",
+            f"logserver_purge_runs_total {self.runs}",
+            "# HELP logserver_purge_last_run_timestamp_seconds The end time of the last purge run.",
+            "# TYPE logserver_purge_last_run_timestamp_seconds gauge",
+            f"logserver_purge_last_run_timestamp_seconds {self.last_run:.0f}",
+            "# HELP logserver_purge_duration_seconds The duration of the last purge run.",
+            "# TYPE logserver_purge_duration_seconds gauge",
+            f"logserver_purge_duration_seconds {self.duration:.3f}",
+            "# HELP logserver_purge_volume_usage_ratio The volume usage at the end of the last purge run.",
+            "# TYPE logserver_purge_volume_usage_ratio gauge",
+            f"logserver_purge_volume_usage_ratio {self.usage:.4f}",
+            "# HELP logserver_purge_deleted_files_total The number of deleted files.",
+            "# TYPE logserver_purge_deleted_files_total counter",
+        ]
+        for reason, count in self.files.items():
+            lines.append(f'logserver_purge_deleted_files_total{{reason="{reason}"}} {count}')
+        lines += [
+            "# HELP logserver_purge_deleted_bytes_total The size of the deleted files.",
+            "# TYPE logserver_purge_deleted_bytes_total counter",
+        ]
+        for reason, count in self.bytes.items():
+            lines.append(f'logserver_purge_deleted_bytes_total{{reason="{reason}"}} {count}')
+        # Write atomically, the collector must not read a partial file
+        path = os.path.join(directory, "purgelogs.prom")
+        with open(path + ".tmp", "w") as f:
+            f.write("\n".join(lines) + "\n")
+        os.rename(path + ".tmp", path)
+
+
+def parse_retentions(values):
+    retentions = []
+    for value in values:
+        pattern, days = value.rsplit("=", 1)
+        retentions.append((pattern, int(days)))
+    return retentions
+
+
+def get_retention(relpath, retentions, default):
+    for pattern, days in retentions:
+        if fnmatch.fnmatch(relpath, pattern):
+            return days
+    return default
+
+
+def walk_files(root):
+    """Yield the (path, relative path, stat) of the log files"""
+    for dirpath, _, filenames in os.walk(root):
+        for filename in filenames:
+            path = os.path.join(dirpath, filename)
+            try:
+                st = os.lstat(path)
+            except FileNotFoundError:
+                continue
+            yield path, os.path.relpath(path, root), st
+
+
+def delete(path, size, reason, stats, dry_run):
+    print(f"Deleting ({reason}) {path}")
+    if not dry_run:
+        try:
+            os.unlink(path)
+        except FileNotFoundError:
+            return
+    stats.files[reason] += 1
+    stats.bytes[reason] += size
+
+
+def remove_empty_dirs(root, dry_run):
+    for dirpath, dirnames, filenames in os.walk(root, topdown=False):
+        if dirpath != root and not dirnames and not filenames and not dry_run:
+            try:
+                os.rmdir(dirpath)
+            except OSError:
+                pass
+
+
+def volume_usage(root):
+    st = os.statvfs(root)
+    if st.f_blocks == 0:
+        return 0.0
+    return 1.0 - st.f_bavail / st.f_blocks
+
+
+def purge(args, retentions, stats, retention):
+    now = time.time()
+    deleted = sum(stats.files.values())
+    if retention:
+        for path, relpath, st in walk_files(args.log_path_dir):
+            days = get_retention(relpath, retentions, args.retention_days)
+            if st.st_mtime < now - days * 86400:
+                delete(path, st.st_size, "retention", stats, args.dry_run)
+
+    usage = volume_usage(args.log_path_dir)
+    if args.high_watermark and usage * 100 > args.high_watermark:
+        print(f"Volume usage {usage * 100:.1f}% exceeds the high watermark {args.high_watermark}%")
+        total = os.statvfs(args.log_path_dir)
+        target = (usage - args.low_watermark / 100) * total.f_blocks * total.f_frsize
+        files = sorted(walk_files(args.log_path_dir), key=lambda f: f[2].st_mtime)
+        for path, _, st in files:
+            if target <= 0:
+                break
+            delete(path, st.st_size, "quota", stats, args.dry_run)
+            target -= st.st_blocks * 512
+        usage = volume_usage(args.log_path_dir)
+        print(f"Volume usage is now {usage * 100:.1f}%")
+    if sum(stats.files.values()) > deleted:
+        remove_empty_dirs(args.log_path_dir, args.dry_run)
+    stats.usage = usage
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--log-path-dir", required=True)
+    parser.add_argument("--retention-days", type=int, required=True)
+    parser.add_argument("--path-retention", action="append", default=[],
+                        help="A retention override, as GLOB=DAYS")
+    parser.add_argument("--high-watermark", type=int, default=0)
+    parser.add_argument("--low-watermark", type=int, default=0)
+    parser.add_argument("--quota-interval", type=int, default=300,
+                        help="The delay, in seconds, between two volume usage checks")
+    parser.add_argument("--stats-dir")
+    parser.add_argument("--loop", type=int, default=0)
+    parser.add_argument("--dry-run", action="store_true")
+    args = parser.parse_args()
+    retentions = parse_retentions(args.path_retention)
+
+    stats = Stats()
+    next_retention = 0.0
+    while True:
+        start = time.time()
+        # The volume usage is checked more often than the retention, to handle sudden floods of logs
+        retention = start >= next_retention
+        if retention:
+            next_retention = start + args.loop
+        if retention or args.high_watermark:
+            purge(args, retentions, stats, retention)
+            stats.runs += 1
+            stats.last_run = time.time()
+            stats.duration = stats.last_run - start
+            print(f"Purge done in {stats.duration:.1f}s: {stats.files} files deleted", flush=True)
+            if args.stats_dir:
+                stats.write(args.stats_dir)
+        if not args.loop:
+            break
+        time.sleep(min(args.loop, args.quota_interval) if args.high_watermark else args.loop)
+
+
+if __name__ == "__main__":
+    main()
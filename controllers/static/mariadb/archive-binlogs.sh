@@ -0,0 +1,19 @@
+#!/bin/sh
+# Archive the binary logs of the MariaDB primary on the archive volume.
+# The binary logs already archived with the same size are skipped.
+
+set -e
+
+ARCHIVE_DIR=/var/lib/mariadb-binlog-archive
+DB_ARGS="--host=mariadb --user=root --password=${MARIADB_ROOT_PASSWORD}"
+
+# Close the current binary log so that the latest changes are archived
+mariadb ${DB_ARGS} -e "FLUSH BINARY LOGS"
+
+mariadb ${DB_ARGS} --skip-column-names -e "SHOW BINARY LOGS" | while read name size _; do
+  if [ -f "${ARCHIVE_DIR}/${name}" ] && [ "$(stat -c %s "${ARCHIVE_DIR}/${name}")" = "${size}" ]; then
+    continue
+  fi
+  echo "Archiving ${name}"
+  mariadb-binlog ${DB_ARGS} --read-from-remote-server --raw --result-file="${ARCHIVE_DIR}/" "${name}"
+done
@@ -0,0 +1,137 @@
+#!/bin/env python3
+# Copyright (C) 2026 Red Hat
+# SPDX-License-Identifier: Apache-2.0
+#
+# Lint the rendered nodepool configuration:
+# - the cloud, context and profile of the providers must be defined in the providers secret,
+# - the labels of the Zuul nodesets of the config repository must be defined in nodepool.
+# The providers secret is either read from the files mounted in the nodepool pods,
+# or from a file listing the names only, as published in the system-config repository.
+
+import argparse
+import configparser
+import os
+import sys
+
+import yaml
+
+KUBE_DRIVERS = ("kubernetes", "openshift", "openshiftpods")
+
+
+def load_yaml(path):
+    with open(path) as f:
+        return yaml.safe_load(f) or {}
+
+
+def load_providers_names(args):
+    """Return the names of the clouds, kube contexts and aws profiles defined in the providers secret"""
+    if args.providers_names:
+        names = load_yaml(args.providers_names)
+        return {key: set(names.get(key) or []) for key in ("clouds", "contexts", "profiles")}
+    names = {"clouds": set(), "contexts": set(), "profiles": set()}
+    if args.clouds_yaml and os.path.exists(args.clouds_yaml):
+        names["clouds"] = set((load_yaml(args.clouds_yaml).get("clouds") or {}).keys())
+    if args.kube_config and os.path.exists(args.kube_config):
+        names["contexts"] = set(
+            ctx["name"] for ctx in load_yaml(args.kube_config).get("contexts") or [])
+    if args.aws_config and os.path.exists(args.aws_config):
+        parser = configparser.ConfigParser(interpolation=None)
+        parser.read(args.aws_config)
+        names["profiles"] = set(
+            section.removeprefix("profile ").strip() for section in parser.sections())
+    return names
+
+
+def check_providers(config, names, errors):
+    for provider in config.get("providers") or []:
+        name = provider.get("name")
+        driver = provider.get("driver", "openstack")
+        if driver == "openstack":
+            if provider.get("cloud") not in names["clouds"]:
+                errors.append(f"provider {name}: the cloud {provider.get('cloud')} "
+                              "is not defined in the clouds.yaml of the providers secret")
+        elif driver in KUBE_DRIVERS:
+            if provider.get("context") not in names["contexts"]:
+                errors.append(f"provider {name}: the context {provider.get('context')} "
+                              "is not defined in the kube.config of the providers secret")
+        elif driver == "aws":
+            profile = provider.get("profile-name")
+            if profile and profile not in names["profiles"]:
+                errors.append(f"provider {name}: the profile {profile} "
+                              "is not defined in the aws.config of the providers secret")
+
+
+def zuul_config_files(root):
+    for name in ("zuul.yaml", ".zuul.yaml"):
+        if os.path.isfile(os.path.join(root, name)):
+            yield os.path.join(root, name)
+    for name in ("zuul.d", ".zuul.d"):
+        for dirpath, _, filenames in os.walk(os.path.join(root, name)):
+            for filename in sorted(filenames):
+                if filename.endswith((".yaml", ".yml")):
+                    yield os.path.join(dirpath, filename)
+
+
+def nodeset_labels(nodeset):
+    """Yield the labels of a nodeset definition, a nodeset name is skipped"""
+    if isinstance(nodeset, list):
+        for alternative in nodeset:
+            yield from nodeset_labels(alternative)
+    elif isinstance(nodeset, dict):
+        nodes = nodeset.get("nodes") or []
+        if isinstance(nodes, dict):
+            nodes = [nodes]
+        for node in nodes:
+            if node.get("label"):
+                yield node["label"]
+        for alternative in nodeset.get("alternatives") or []:
+            yield from nodeset_labels(alternative)
+
+
+def check_zuul_labels(root, labels, errors):
+    for path in zuul_config_files(root):
+        try:
+            items = load_yaml(path) or []
+        except yaml.YAMLError as e:
+            errors.append(f"{os.path.relpath(path, root)}: unable to parse: {e}")
+            continue
+        for item in items:
+            if not isinstance(item, dict):
+                continue
+            for kind in ("nodeset", "job"):
+                body = item.get(kind)
+                if not isinstance(body, dict):
+                    continue
+                nodeset = body if kind == "nodeset" else body.get("nodeset")
+                for label in nodeset_labels(nodeset):
+                    if label not in labels:
+                        errors.append(f"{os.path.relpath(path, root)}: {kind} {body.get('name')}: "
+                                      f"the label {label} is not defined in nodepool")
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("config", help="The rendered nodepool launcher configuration")
+    parser.add_argument("--zuul-config", help="The config repository, to check the labels of the Zuul nodesets")
+    parser.add_argument("--providers-names", help="A YAML file listing the clouds, contexts and profiles names")
+    parser.add_argument("--clouds-yaml", default="/var/lib/nodepool/.config/openstack/clouds.yaml")
+    parser.add_argument("--kube-config", default="/var/lib/nodepool/.kube/config")
+    parser.add_argument("--aws-config", default="/var/lib/nodepool/.aws/config")
+    args = parser.parse_args()
+
+    config = load_yaml(args.config)
+    errors = []
+    check_providers(config, load_providers_names(args), errors)
+    if args.zuul_config:
+        labels = set(label.get("name") for label in config.get("labels") or [])
+        check_zuul_labels(args.zuul_config, labels, errors)
+
+    for error in errors:
+        print(f"ERROR: {error}")
+    if errors:
+        sys.exit(1)
+    print("The nodepool configuration is valid")
+
+
+if __name__ == "__main__":
+    main()
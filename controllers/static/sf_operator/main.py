@@ -57,6 +57,22 @@ def mk_incluster_k8s_config():
         ("server", api)]
 
 
+def mk_logserver_s3_secret():
+    # The bucket credentials used by the upload-logs-s3 role
+    return sf_operator.secret.mk_secret(
+        "site_sflogs",
+        items=[
+            ("access_key", os.environ["S3_ACCESS_KEY"]),
+            ("secret_key", os.environ["S3_SECRET_KEY"]),
+        ],
+        unencrypted_items=[
+            ("endpoint", "\"" + os.environ["S3_ENDPOINT"] + "\""),
+            ("bucket", "\"" + os.environ["S3_BUCKET"] + "\""),
+            ("region", "\"" + os.environ.get("S3_REGION", "") + "\""),
+        ]
+    )
+
+
 def create_zuul_secrets():
     clone = pynotedb.mk_clone("git://git-server-rw:9419/system-config")
     # FQDN to access the logserver
@@ -69,19 +85,22 @@ def create_zuul_secrets():
     k8s_secret.write_text(secret)
     # log server secret
     logserver_secret = clone / "zuul.d" / "sf-logserver-secret.yaml"
-    secret = sf_operator.secret.mk_secret(
-        "site_sflogs",
-        items=[
-            ("ssh_private_key", os.environ["ZUUL_LOGSERVER_PRIVATE_KEY"])
-        ],
-        unencrypted_items=[
-            ("fqdn", "\"" + logserver_fqdn + "\""),
-            ("path", "rsync"),
-            ("ssh_known_hosts", "\"%s %s\"" %
-             (logserver_fqdn, get_logserver_fingerprint())),
-            ("ssh_username", "zuul")
-        ]
-    )
+    if os.environ.get("LOGSERVER_BACKEND") == "s3":
+        secret = mk_logserver_s3_secret()
+    else:
+        secret = sf_operator.secret.mk_secret(
+            "site_sflogs",
+            items=[
+                ("ssh_private_key", os.environ["ZUUL_LOGSERVER_PRIVATE_KEY"])
+            ],
+            unencrypted_items=[
+                ("fqdn", "\"" + logserver_fqdn + "\""),
+                ("path", "rsync"),
+                ("ssh_known_hosts", "\"%s %s\"" %
+                 (logserver_fqdn, get_logserver_fingerprint())),
+                ("ssh_username", "zuul")
+            ]
+        )
     logserver_secret.write_text(secret)
     pynotedb.git(
         clone,
@@ -0,0 +1,127 @@
+#!/bin/env python3
+# Copyright (C) 2026 Red Hat
+# SPDX-License-Identifier: Apache-2.0
+#
+# Maintenance of the git repositories cached by zuul-merger and zuul-executor.
+#
+# warmup: clone the projects of every tenant that are missing from the cache
+# gc: run git gc and prune on the cached repositories and report the reclaimed space
+
+import configparser
+import json
+import os
+import shutil
+import subprocess
+import sys
+import urllib.request
+
+
+def disk_usage(path):
+    out = subprocess.run(["du", "-sk", path], capture_output=True, text=True)
+    try:
+        return int(out.stdout.split()[0])
+    except (IndexError, ValueError):
+        return 0
+
+
+def get_json(url):
+    req = urllib.request.Request(url, headers={"Accept": "application/json"})
+    with urllib.request.urlopen(req, timeout=60) as resp:
+        return json.loads(resp.read())
+
+
+def get_clone_url(conn, project):
+    driver = conn.get("driver")
+    if driver == "gerrit":
+        user = conn.get("user", "zuul")
+        port = conn.get("port", "29418")
+        return "ssh://%s@%s:%s/%s" % (user, conn["server"], port, project)
+    if driver == "github":
+        return "https://%s/%s" % (conn.get("server", "github.com"), project)
+    if driver in ("git", "gitlab", "pagure"):
+        return "%s/%s" % (conn["baseurl"].rstrip("/"), project)
+    return None
+
+
+def list_repositories(git_dir):
+    # Zuul stores the repositories as <git_dir>/<canonical_hostname>/<project name>
+    for root, dirs, _ in os.walk(git_dir):
+        if ".git" in dirs:
+            dirs.clear()
+            yield root
+
+
+def warmup(config, git_dir):
+    zuul_web_url = os.environ["ZUUL_WEB_URL"].rstrip("/")
+    connections = {
+        section.split(" ", 1)[1]: config[section]
+        for section in config.sections() if section.startswith("connection ")
+    }
+    cloned, failed = 0, 0
+    projects = {}
+    for tenant in get_json(zuul_web_url + "/api/tenants"):
+        for project in get_json("%s/api/tenant/%s/projects" % (zuul_web_url, tenant["name"])):
+            projects[project["canonical_name"]] = project
+    for canonical_name, project in sorted(projects.items()):
+        dest = os.path.join(git_dir, canonical_name)
+        if os.path.exists(os.path.join(dest, ".git")):
+            continue
+        conn = connections.get(project.get("connection_name"))
+        url = get_clone_url(conn, project["name"]) if conn else None
+        if not url:
+            print("Skipping %s: unsupported connection" % canonical_name)
+            continue
+        env = dict(os.environ)
+        if conn.get("sshkey"):
+            env["GIT_SSH_COMMAND"] = "ssh -i %s -o StrictHostKeyChecking=no" % conn["sshkey"]
+        # Clone aside then move in place, in case the service clones the project meanwhile
+        tmp = dest + ".warmup"
+        shutil.rmtree(tmp, ignore_errors=True)
+        os.makedirs(os.path.dirname(dest), exist_ok=True)
+        print("Cloning %s" % canonical_name, flush=True)
+        if subprocess.run(["git", "clone", "--quiet", url, tmp], env=env).returncode != 0:
+            failed += 1
+            shutil.rmtree(tmp, ignore_errors=True)
+            continue
+        if os.path.exists(dest):
+            shutil.rmtree(tmp, ignore_errors=True)
+        else:
+            os.rename(tmp, dest)
+            cloned += 1
+    print("Warm-up done: %d cloned, %d failed, %d already cached" % (
+        cloned, failed, len(projects) - cloned - failed))
+
+
+def gc(git_dir):
+    before = disk_usage(git_dir)
+    repos = 0
+    for repo in list_repositories(git_dir):
+        repos += 1
+        subprocess.run(["git", "-C", repo, "remote", "prune", "origin"], capture_output=True)
+        if subprocess.run(["git", "-C", repo, "gc", "--quiet"]).returncode != 0:
+            print("git gc failed for %s" % repo)
+    after = disk_usage(git_dir)
+    print("Git gc done on %d repositories: %d KiB reclaimed (%d KiB -> %d KiB)" % (
+        repos, before - after, before, after))
+
+
+def main():
+    action, service = sys.argv[1], sys.argv[2]
+    config = configparser.ConfigParser(interpolation=None)
+    config.read("/etc/zuul/zuul.conf")
+    if service == "zuul-merger":
+        git_dir = config.get("merger", "git_dir", fallback="/var/lib/zuul/git")
+    else:
+        git_dir = config.get("executor", "git_dir", fallback="/var/lib/zuul/executor-git")
+    os.makedirs(git_dir, exist_ok=True)
+    if action == "warmup":
+        warmup(config, git_dir)
+    elif action == "gc":
+        gc(git_dir)
+    else:
+        print("Unknown action %s" % action)
+        sys.exit(1)
+
+
+if __name__ == "__main__":
+    main()
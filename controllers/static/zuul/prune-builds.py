@@ -0,0 +1,144 @@
+#!/bin/env python3
+# Copyright (C) 2026 Red Hat
+# SPDX-License-Identifier: Apache-2.0
+#
+# Prune the Zuul database according to the build retention policy.
+#
+# The buildsets older than the retention of their pipeline are optionally exported
+# to a compressed archive, then deleted. Finally, zuul-admin prune-database runs
+# with the longest retention to let Zuul clean up the remaining rows.
+
+import argparse
+import configparser
+import datetime
+import gzip
+import json
+import os
+import subprocess
+import sys
+
+import sqlalchemy as sa
+
+
+def read_database_config():
+    # Zuul interpolates the ZUUL_ environment variables in zuul.conf
+    safe_env = {k: v for k, v in os.environ.items() if k.startswith("ZUUL_")}
+    config = configparser.ConfigParser(safe_env)
+    config.read("/etc/zuul/zuul.conf")
+    return (
+        config.get("database", "dburi"),
+        config.get("database", "table_prefix", fallback=""),
+    )
+
+
+def dependents(metadata, table):
+    """Return the tables with a foreign key to the table, and the column of the key"""
+    for other in metadata.sorted_tables:
+        for fk in other.foreign_keys:
+            if fk.column.table is table:
+                yield other, fk.parent
+
+
+def to_json(row):
+    return {
+        k: v.isoformat() if isinstance(v, datetime.datetime) else v
+        for k, v in row._mapping.items()
+    }
+
+
+def parse_pipelines(values):
+    pipelines = {}
+    for value in values:
+        name, days = value.rsplit("=", 1)
+        pipelines[name] = int(days)
+    return pipelines
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--days", type=int, required=True)
+    parser.add_argument("--pipeline", action="append", default=[],
+                        help="A pipeline retention override, as NAME=DAYS")
+    parser.add_argument("--archive-dir")
+    parser.add_argument("--batch-size", type=int, default=1000)
+    args = parser.parse_args()
+    pipelines = parse_pipelines(args.pipeline)
+
+    dburi, prefix = read_database_config()
+    engine = sa.create_engine(dburi)
+    metadata = sa.MetaData()
+    metadata.reflect(bind=engine)
+    buildset_table = metadata.tables[prefix + "zuul_buildset"]
+    build_table = metadata.tables[prefix + "zuul_build"]
+
+    now = datetime.datetime.utcnow()
+    cutoff = now - datetime.timedelta(days=args.days)
+    updated = buildset_table.c.updated
+    pipeline = buildset_table.c.pipeline
+    expired = [sa.and_(pipeline.not_in(list(pipelines)), updated < cutoff)]
+    for name, days in pipelines.items():
+        expired.append(sa.and_(pipeline == name, updated < now - datetime.timedelta(days=days)))
+    expired = sa.or_(*expired)
+
+    archive = None
+    if args.archive_dir:
+        os.makedirs(args.archive_dir, exist_ok=True)
+        path = os.path.join(args.archive_dir, "zuul-builds-%s.jsonl.gz" % now.strftime("%Y%m%d%H%M%S"))
+        archive = gzip.open(path, "wt")
+
+    build_children = [(t, c) for t, c in dependents(metadata, build_table)]
+    buildset_children = [(t, c) for t, c in dependents(metadata, buildset_table) if t is not build_table]
+    pruned_buildsets, pruned_builds = 0, 0
+    while True:
+        with engine.begin() as conn:
+            buildsets = conn.execute(
+                sa.select(buildset_table).where(expired).order_by(buildset_table.c.id).limit(args.batch_size)
+            ).fetchall()
+            if not buildsets:
+                break
+            buildset_ids = [b.id for b in buildsets]
+            builds = conn.execute(
+                sa.select(build_table).where(build_table.c.buildset_id.in_(buildset_ids))).fetchall()
+            build_ids = [b.id for b in builds]
+
+            if archive:
+                for buildset in buildsets:
+                    record = to_json(buildset)
+                    for table, column in buildset_children:
+                        record[table.name] = [to_json(r) for r in conn.execute(
+                            sa.select(table).where(column == buildset.id))]
+                    record["builds"] = []
+                    for build in builds:
+                        if build.buildset_id != buildset.id:
+                            continue
+                        build_record = to_json(build)
+                        for table, column in build_children:
+                            build_record[table.name] = [to_json(r) for r in conn.execute(
+                                sa.select(table).where(column == build.id))]
+                        record["builds"].append(build_record)
+                    archive.write(json.dumps(record) + "\n")
+
+            for table, column in build_children:
+                conn.execute(sa.delete(table).where(column.in_(build_ids)))
+            conn.execute(sa.delete(build_table).where(build_table.c.id.in_(build_ids)))
+            for table, column in buildset_children:
+                conn.execute(sa.delete(table).where(column.in_(buildset_ids)))
+            conn.execute(sa.delete(buildset_table).where(buildset_table.c.id.in_(buildset_ids)))
+            pruned_buildsets += len(buildset_ids)
+            pruned_builds += len(build_ids)
+
+    if archive:
+        archive.close()
+        if pruned_buildsets:
+            print("Archived the pruned buildsets in %s" % path)
+        else:
+            os.unlink(path)
+    print("Pruned %d buildsets and %d builds" % (pruned_buildsets, pruned_builds), flush=True)
+
+    older_than = max([args.days] + list(pipelines.values()))
+    sys.exit(subprocess.run(
+        ["zuul-admin", "prune-database", "--older-than", "%dd" % older_than]).returncode)
+
+
+if __name__ == "__main__":
+    main()
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"golang.org/x/term"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -203,6 +204,64 @@ func (r *SFKubeContext) PodExecIn(pod string, container string, command []string
 	return nil
 }
 
+// PodExecTTY connects to a container's Pod and execute an interactive command
+// The caller's terminal is attached to the command and set in raw mode for the session
+func (r *SFKubeContext) PodExecTTY(pod string, container string, command []string) error {
+	logging.LogI(fmt.Sprintf("Running interactive pod execution pod: %s, command: %s", pod, command))
+	fd := int(os.Stdin.Fd())
+	isTerm := term.IsTerminal(fd)
+	execReq := r.RESTClient.
+		Post().
+		Namespace(r.Ns).
+		Resource("pods").
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&apiv1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    !isTerm,
+			TTY:       isTerm,
+		}, runtime.NewParameterCodec(r.Scheme))
+
+	exec, err := remotecommand.NewSPDYExecutor(r.RESTConfig, "POST", execReq.URL())
+	if err != nil {
+		return err
+	}
+
+	opts := remotecommand.StreamOptions{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Tty:    isTerm,
+	}
+	if isTerm {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+		if width, height, err := term.GetSize(fd); err == nil {
+			opts.TerminalSizeQueue = &fixedTerminalSize{size: &remotecommand.TerminalSize{
+				Width: uint16(width), Height: uint16(height)}}
+		}
+	} else {
+		opts.Stderr = os.Stderr
+	}
+	return exec.StreamWithContext(context.Background(), opts)
+}
+
+// fixedTerminalSize sends the caller's terminal size once, at the beginning of the session
+type fixedTerminalSize struct {
+	size *remotecommand.TerminalSize
+}
+
+func (t *fixedTerminalSize) Next() *remotecommand.TerminalSize {
+	size := t.size
+	t.size = nil
+	return size
+}
+
 // PodExecOut connects to a container's Pod and execute a command
 // Stderr is output on the caller's Stdout
 // The function returns an Error for any issue
@@ -5,12 +5,40 @@ package controllers
 
 import (
 	"github.com/softwarefactory-project/sf-operator/controllers/libs/base"
+	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	weederIdent   = "zuul-weeder"
+	weederPVCName = "zuul-weeder-data"
+)
+
+func (r *SFController) TerminateZuulWeeder() {
+	r.DeleteR(&apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      weederIdent,
+			Namespace: r.Ns,
+		},
+	})
+	r.DeleteR(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      weederIdent,
+			Namespace: r.Ns,
+		},
+	})
+	r.DeleteR(&apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      weederPVCName,
+			Namespace: r.Ns,
+		},
+	})
+}
+
 func (r *SFController) EnsureZuulWeeder(checksum string) bool {
 	const (
-		ident = "zuul-weeder"
+		ident = weederIdent
 		port  = 9001
 	)
 
@@ -23,10 +51,23 @@ func (r *SFController) EnsureZuulWeeder(checksum string) bool {
 	}
 
 	dep := base.MkDeployment(ident, r.Ns, base.ZuulWeederImage(), r.cr.Spec.ExtraLabels, r.IsOpenShift)
-	dep.Spec.Template.Spec.Containers[0].ImagePullPolicy = "Always"
 	dep.Spec.Template.ObjectMeta.Annotations = annotations
+	// The weeder cache is kept on a volume when a storage is set
+	cacheVolume := base.MkEmptyDirVolume("weeder-tmp")
+	pvcReadiness := true
+	storage := r.cr.Spec.Weeder.Storage
+	if storage != nil {
+		pvc := base.MkPVC(weederPVCName, r.Ns, r.getStorageConfOrDefault(*storage), apiv1.ReadWriteOnce)
+		r.GetOrCreate(&pvc)
+		cacheVolume = base.MkVolumePVC("weeder-tmp", weederPVCName)
+		// The volume can only be attached to a single pod
+		dep.Spec.Strategy.Type = appsv1.RecreateDeploymentStrategyType
+		annotations["storage"] = weederPVCName
+		pvcReadiness = r.reconcileExpandPVC(weederPVCName, *storage)
+	}
+	annotations["limits"] = base.UpdateContainerLimit(r.cr.Spec.Weeder.Limits, &dep.Spec.Template.Spec.Containers[0])
 	dep.Spec.Template.Spec.Volumes = []apiv1.Volume{
-		base.MkEmptyDirVolume("weeder-tmp"),
+		cacheVolume,
 		base.MkVolumeSecret("zuul-config"),
 		base.MkVolumeSecret("zookeeper-client-tls"),
 	}
@@ -55,5 +96,5 @@ func (r *SFController) EnsureZuulWeeder(checksum string) bool {
 	dep.Spec.Template.Spec.Containers[0].ReadinessProbe = base.MkReadinessHTTPProbe("/health", port)
 	dep.Spec.Template.Spec.HostAliases = base.CreateHostAliases(r.cr.Spec.HostAliases)
 	current, changed := r.ensureDeployment(dep, nil)
-	return !changed && r.IsDeploymentReady(current)
+	return !changed && r.IsDeploymentReady(current) && pvcReadiness
 }
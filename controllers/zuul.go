@@ -75,6 +75,9 @@ var (
 	//go:embed static/hound-search/hound-search-render.py
 	houndSearchRender string
 
+	//go:embed static/hound-search/hound-search-credentials.py
+	houndSearchCredentials string
+
 	// Common config sections for all Zuul components
 	commonIniConfigSections = []string{"zookeeper", "keystore", "database"}
 
@@ -174,6 +177,7 @@ func (r *SFKubeContext) mkKazooPod() *apiv1.Pod {
 					},
 					Env: []apiv1.EnvVar{
 						base.MkEnvVar("HOME", "/var/lib/zuul"),
+						base.MkEnvVar("ZUUL_DB_HOST", MariaDBIdent),
 					},
 					SecurityContext: base.MkSecurityContext(false, r.IsOpenShift),
 				},
@@ -267,6 +271,14 @@ func (r *SFController) mkZuulContainer(service string, corporateCMExists bool) a
 		base.MkEnvVar("REQUESTS_CA_BUNDLE", "/etc/ssl/certs/ca-bundle.crt"),
 		base.MkEnvVar("HOME", "/var/lib/zuul"),
 	}
+	if r.isMariaDBReplicated() {
+		// zuul-web reads from the replicas, or from the primary while no replica is healthy
+		if service == "zuul-web" {
+			envs = append(envs, base.MkEnvVar("ZUUL_DB_HOST", mariadbROService))
+		} else {
+			envs = append(envs, base.MkEnvVar("ZUUL_DB_HOST", MariaDBIdent))
+		}
+	}
 	if service == "zuul-scheduler" {
 		volumeMounts = append(volumeMounts,
 			apiv1.VolumeMount{
@@ -297,6 +309,10 @@ func (r *SFController) mkZuulContainer(service string, corporateCMExists bool) a
 		envs = append(envs, r.getTenantsEnvs()...)
 	}
 
+	if service == "zuul-scheduler" || service == "zuul-web" {
+		volumeMounts = append(volumeMounts, mkExternalDBCAVolumeMount(r.cr.Spec.ExternalDatabase)...)
+	}
+
 	volumeMounts = append(volumeMounts, mkZuulLoggingMount(service))
 	volumeMounts = append(volumeMounts, mkZuulConnectionsSecretsMount(r)...)
 
@@ -401,6 +417,10 @@ func mkZuulVolumes(service string, r *SFController, corporateCMExists bool) []ap
 		volumes = AppendToolingVolume(volumes)
 	}
 
+	if service == "zuul-scheduler" || service == "zuul-web" {
+		volumes = append(volumes, mkExternalDBCAVolume(r.cr.Spec.ExternalDatabase)...)
+	}
+
 	volumes = append(volumes, mkZuulConnectionSecretsVolumes(r)...)
 
 	if corporateCMExists {
@@ -624,8 +644,9 @@ func (r *SFController) EnsureZuulScheduler(cfg *ini.File) bool {
 	ready := r.waitStatefulset(current)
 	conds.UpdateConditions(&r.cr.Status.Conditions, "zuul-scheduler", ready)
 
-	if ready {
-		// TODO: make this configurable
+	if !r.IsWeederEnabled() {
+		r.TerminateZuulWeeder()
+	} else if ready {
 		return r.EnsureZuulWeeder(annotations["zuul-connections"])
 	}
 
@@ -720,7 +741,12 @@ func (r *SFController) EnsureZuulExecutor(cfg *ini.File) bool {
 		enableZuulLocalSource(&ze.Spec.Template, path, false, r.IsOpenShift)
 	}
 
-	current, changed := r.ensureStatefulset(ze, nil)
+	replicas := r.cr.Spec.Zuul.Executor.Replicas
+	if replicas != nil {
+		ze.Spec.Replicas = replicas
+	}
+
+	current, changed := r.ensureStatefulset(ze, replicas)
 	if changed {
 		return false
 	}
@@ -729,6 +755,9 @@ func (r *SFController) EnsureZuulExecutor(cfg *ini.File) bool {
 
 	ready := r.waitStatefulset(current) && pvcReadiness
 	conds.UpdateConditions(&r.cr.Status.Conditions, "zuul-executor", ready)
+	if ready {
+		r.EnsureGitCache(current, r.cr.Spec.Zuul.Executor.GitCache)
+	}
 
 	return ready
 }
@@ -799,6 +828,9 @@ func (r *SFController) EnsureZuulMerger(cfg *ini.File) bool {
 
 	ready := pvcReadiness && r.waitStatefulset(current)
 	conds.UpdateConditions(&r.cr.Status.Conditions, service, ready)
+	if ready {
+		r.EnsureGitCache(current, r.cr.Spec.Zuul.Merger.GitCache)
+	}
 
 	return ready
 }
@@ -925,6 +957,10 @@ func (r *SFController) EnsureZuulComponents() map[string]bool {
 	zuulServices["Web"] = r.EnsureZuulWeb(cfg)
 	zuulServices["Merger"] = r.EnsureZuulMerger(cfg)
 
+	if zuulServices["Scheduler"] {
+		r.EnsureBuildRetention()
+	}
+
 	componentStatus["Zuul"] = true
 	for ready := range zuulServices {
 		componentStatus["|_ "+ready] = zuulServices[ready]
@@ -1051,8 +1087,11 @@ func (r *SFController) EnsureZuulConfigSecret(remoteExecutor bool) *ini.File {
 			logging.LogI("Waiting for db connection secret")
 			return nil
 		}
-		cfgINI.Section("database").NewKey("dburi", fmt.Sprintf(
-			"mariadb+pymysql://%s:%s@%s/%s", dbSettings.Data["username"], dbSettings.Data["password"], dbSettings.Data["host"], dbSettings.Data["database"]))
+		if r.isMariaDBReplicated() {
+			// zuul-web reads from the replicas, the host is interpolated from the ZUUL_DB_HOST environment variable
+			dbSettings.Data["host"] = []byte("%(ZUUL_DB_HOST)s")
+		}
+		cfgINI.Section("database").NewKey("dburi", mkZuulDBURI(r.cr.Spec.ExternalDatabase, dbSettings))
 	}
 
 	// Set Zookeeper hosts
@@ -8,10 +8,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
+	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/logging"
+	"github.com/softwarefactory-project/sf-operator/controllers/libs/utils"
 
 	sfv1 "github.com/softwarefactory-project/sf-operator/api/v1"
 )
@@ -78,7 +86,7 @@ func (r *SFKubeContext) setupFingerLB() {
 	r.EnsureService(&svc)
 }
 
-func (r *SFKubeContext) setupRemoteExecutorConfig(crPath string, eCR sfv1.SoftwareFactory) error {
+func (r *SFKubeContext) setupRemoteExecutorConfig(crPath string, eCR sfv1.SoftwareFactory, target RemoteExecutorTarget) error {
 	if _, err := os.Stat(crPath); err != nil {
 		return fmt.Errorf("missing control plane resource %s", crPath)
 	}
@@ -101,9 +109,261 @@ func (r *SFKubeContext) setupRemoteExecutorConfig(crPath string, eCR sfv1.Softwa
 		return err
 	}
 
-	if err := r.copySecrets(eCR, &controlEnv); err != nil {
+	if err := r.setupRemoteExecutor(eCR, &controlEnv); err != nil {
+		return err
+	}
+	// Remember the remote executor so that secrets rotation can update it
+	return controlEnv.registerRemoteExecutor(target)
+}
+
+func (r *SFKubeContext) setupRemoteExecutor(eCR sfv1.SoftwareFactory, controlEnv *SFKubeContext) error {
+	if err := r.copySecrets(eCR, controlEnv); err != nil {
 		return err
 	}
 	r.setupFingerLB()
 	return nil
 }
+
+// mkExecutorPoolCR returns the standalone executor resource of a pool, derived from the control plane resource.
+func mkExecutorPoolCR(controlCR sfv1.SoftwareFactory, pool sfv1.ZuulExecutorPoolSpec) sfv1.SoftwareFactory {
+	poolCR := *controlCR.DeepCopy()
+	poolCR.Status = sfv1.SoftwareFactoryStatus{}
+	standalone := pool.StandaloneZuulExecutorSpec
+	poolCR.Spec.Zuul.Executor.Standalone = &standalone
+	poolCR.Spec.Zuul.Executor.Pools = nil
+	poolCR.Spec.Zuul.Executor.Replicas = ptr.To(getExecutorPoolReplicas(pool))
+	if pool.Limits != nil {
+		poolCR.Spec.Zuul.Executor.Limits = pool.Limits
+	}
+	return poolCR
+}
+
+func getExecutorPoolReplicas(pool sfv1.ZuulExecutorPoolSpec) int32 {
+	if pool.Replicas == nil {
+		return 1
+	}
+	return *pool.Replicas
+}
+
+func (r *SFKubeContext) mkExecutorPoolContext(pool sfv1.ZuulExecutorPoolSpec, kubeConfig string) (SFKubeContext, error) {
+	poolEnv, err := MkSFKubeContext(kubeConfig, pool.Namespace, pool.KubeContext, r.DryRun)
+	if err != nil {
+		return poolEnv, err
+	}
+	if poolEnv.Ns == r.Ns && poolEnv.RESTConfig.Host == r.RESTConfig.Host {
+		return poolEnv, fmt.Errorf("executor pool %s must not be deployed in the control plane namespace", pool.Name)
+	}
+	return poolEnv, nil
+}
+
+func (r *SFKubeContext) getExecutorPoolStatus(status sfv1.ExecutorPoolStatus) sfv1.ExecutorPoolStatus {
+	var sts appsv1.StatefulSet
+	if !r.GetOrDie("zuul-executor", &sts) {
+		if status.Message == "" {
+			status.Message = "zuul-executor statefulset not found"
+		}
+		return status
+	}
+	status.ReadyReplicas = sts.Status.ReadyReplicas
+	status.Ready = status.Message == "" && status.ReadyReplicas == status.Replicas
+	if !status.Ready && status.Message == "" {
+		status.Message = fmt.Sprintf("%d/%d executors ready", status.ReadyReplicas, status.Replicas)
+	}
+	return status
+}
+
+// ReconcileExecutorPools deploys the remote executor pools declared in the control plane resource.
+// The pool clients are created from the control plane kubeconfig, using the context of each pool.
+func (r *SFKubeContext) ReconcileExecutorPools(controlCR sfv1.SoftwareFactory, kubeConfig string) []sfv1.ExecutorPoolStatus {
+	statuses := []sfv1.ExecutorPoolStatus{}
+	seen := map[string]bool{}
+	for _, pool := range controlCR.Spec.Zuul.Executor.Pools {
+		status := sfv1.ExecutorPoolStatus{
+			Name:     pool.Name,
+			Zone:     pool.Zone,
+			Replicas: getExecutorPoolReplicas(pool),
+		}
+		if seen[pool.Name] {
+			status.Message = "duplicated pool name"
+			statuses = append(statuses, status)
+			continue
+		}
+		seen[pool.Name] = true
+
+		poolEnv, err := r.mkExecutorPoolContext(pool, kubeConfig)
+		if err != nil {
+			logging.LogE(err, "Unable to connect to executor pool "+pool.Name)
+			status.Message = err.Error()
+			statuses = append(statuses, status)
+			continue
+		}
+		logging.LogI(fmt.Sprintf("Reconciling executor pool %s in namespace %s (context %s)", pool.Name, poolEnv.Ns, pool.KubeContext))
+		poolCR := mkExecutorPoolCR(controlCR, pool)
+		poolEnv.EnsureStandaloneOwner(poolCR.Spec)
+		if err := poolEnv.setupRemoteExecutor(poolCR, r); err != nil {
+			logging.LogE(err, "Unable to setup executor pool "+pool.Name)
+			status.Message = err.Error()
+		} else if err := poolEnv.StandaloneReconcile(poolCR); err != nil {
+			logging.LogE(err, "Unable to reconcile executor pool "+pool.Name)
+			status.Message = err.Error()
+		}
+		statuses = append(statuses, poolEnv.getExecutorPoolStatus(status))
+	}
+	return statuses
+}
+
+// recordExecutorPoolsStatus stores the pools status in the standalone owner ConfigMap.
+func (r *SFKubeContext) recordExecutorPoolsStatus(statuses []sfv1.ExecutorPoolStatus) error {
+	if r.DryRun {
+		return nil
+	}
+	var cm apiv1.ConfigMap
+	if !r.GetOrDie(controllerCMName, &cm) {
+		return fmt.Errorf("%s not found", controllerCMName)
+	}
+	marshaledStatus, _ := yaml.Marshal(sfv1.SoftwareFactoryStatus{ExecutorPools: statuses})
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data["status"] = string(marshaledStatus)
+	return r.Client.Update(r.Ctx, &cm)
+}
+
+// remoteExecutorsCMName is the control plane ConfigMap listing the remote executors deployed with `deploy --remote`.
+const remoteExecutorsCMName = "sf-remote-executors"
+
+// RemoteExecutorTarget defines how to reach the namespace of a remote executor deployment.
+// The kubeconfig is a file of the machine running the CLI: it is not registered, but resolved at run time.
+type RemoteExecutorTarget struct {
+	Name        string `json:"name"`
+	KubeConfig  string `json:"-"`
+	KubeContext string `json:"kubeContext,omitempty"`
+	Namespace   string `json:"namespace"`
+}
+
+func MkRemoteExecutorTarget(kubeConfig string, kubeContext string, namespace string) RemoteExecutorTarget {
+	// The name is used as a ConfigMap key, it must be stable for a given target
+	name := "remote-" + namespace
+	if kubeContext != "" {
+		name += "-" + utils.Checksum([]byte(kubeContext))[:8]
+	}
+	return RemoteExecutorTarget{
+		Name:        name,
+		KubeConfig:  kubeConfig,
+		KubeContext: kubeContext,
+		Namespace:   namespace,
+	}
+}
+
+func (r *SFKubeContext) registerRemoteExecutor(target RemoteExecutorTarget) error {
+	if r.DryRun {
+		return nil
+	}
+	data, err := yaml.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var cm apiv1.ConfigMap
+	if !r.GetOrDie(remoteExecutorsCMName, &cm) {
+		cm = apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: remoteExecutorsCMName, Namespace: r.Ns},
+			Data:       map[string]string{target.Name: string(data)},
+		}
+		return r.Client.Create(r.Ctx, &cm)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	// The entries registered by the older versions include the kubeconfig path in their name and content
+	changed := cm.Data[target.Name] != string(data)
+	for name, entry := range cm.Data {
+		var registered RemoteExecutorTarget
+		if name != target.Name && yaml.Unmarshal([]byte(entry), &registered) == nil &&
+			registered.Namespace == target.Namespace && registered.KubeContext == target.KubeContext {
+			delete(cm.Data, name)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	cm.Data[target.Name] = string(data)
+	return r.Client.Update(r.Ctx, &cm)
+}
+
+// GetRemoteExecutorTargets returns the executor pools of the resource and the remote executors registered by `deploy --remote`.
+func (r *SFKubeContext) GetRemoteExecutorTargets(cr sfv1.SoftwareFactory, kubeConfig string) []RemoteExecutorTarget {
+	targets := []RemoteExecutorTarget{}
+	for _, pool := range cr.Spec.Zuul.Executor.Pools {
+		targets = append(targets, RemoteExecutorTarget{
+			Name:        "pool-" + pool.Name,
+			KubeConfig:  kubeConfig,
+			KubeContext: pool.KubeContext,
+			Namespace:   pool.Namespace,
+		})
+	}
+	var cm apiv1.ConfigMap
+	if r.GetOrDie(remoteExecutorsCMName, &cm) {
+		names := []string{}
+		for name := range cm.Data {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			var target RemoteExecutorTarget
+			if err := yaml.Unmarshal([]byte(cm.Data[name]), &target); err != nil {
+				logging.LogE(err, "Unable to decode remote executor target "+name)
+				continue
+			}
+			target.KubeConfig = kubeConfig
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// restartExecutors deletes the zuul-executor pods so that they are recreated with the current secrets.
+func (r *SFKubeContext) restartExecutors() error {
+	var podList apiv1.PodList
+	if err := r.Client.List(r.Ctx, &podList, client.InNamespace(r.Ns), client.MatchingLabels{"run": "zuul-executor"}); err != nil {
+		return err
+	}
+	for _, pod := range podList.Items {
+		r.DeleteR(&pod)
+	}
+	return nil
+}
+
+// SyncRemoteExecutors pushes the control plane secrets to the remote executor targets and restarts their executors.
+// It returns an error listing the targets that could not be updated.
+func (r *SFKubeContext) SyncRemoteExecutors(cr sfv1.SoftwareFactory, kubeConfig string) error {
+	failed := []string{}
+	for _, target := range r.GetRemoteExecutorTargets(cr, kubeConfig) {
+		err := func() error {
+			targetEnv, err := MkSFKubeContext(target.KubeConfig, target.Namespace, target.KubeContext, r.DryRun)
+			if err != nil {
+				return err
+			}
+			if targetEnv.Ns == r.Ns && targetEnv.RESTConfig.Host == r.RESTConfig.Host {
+				return fmt.Errorf("remote executor %s targets the control plane namespace", target.Name)
+			}
+			if !targetEnv.GetStandaloneOwner() {
+				return fmt.Errorf("no executor deployed in namespace %s", targetEnv.Ns)
+			}
+			if err := targetEnv.copySecrets(cr, r); err != nil {
+				return err
+			}
+			return targetEnv.restartExecutors()
+		}()
+		if err != nil {
+			logging.LogE(err, "Unable to update remote executor "+target.Name)
+			failed = append(failed, target.Name)
+		} else {
+			logging.LogI("Remote executor " + target.Name + " updated and restarted")
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to update remote executors: %v", failed)
+	}
+	return nil
+}